@@ -0,0 +1,101 @@
+package llmschema
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tordrt/llmschema/internal/textdiff"
+	"github.com/tordrt/llmschema/schema"
+)
+
+// ErrOutputOutdated is returned by CheckOutput when the existing output
+// differs from what FormatSchema would write.
+var ErrOutputOutdated = errors.New("schema documentation is out of date")
+
+// CheckOutput renders s in memory and compares it with the existing output
+// instead of writing it: the file at outputFile for single-file output, or
+// the files in OutputOptions.OutputDir for multi-file output, where generated
+// files that FormatSchema would delete as stale count as changes.
+//
+// When the output differs, CheckOutput writes a unified diff of the changes
+// to w, defaulting to os.Stdout, and returns ErrOutputOutdated. Nothing is
+// written to the output itself. Multi-file output can only be checked for the
+// markdown format. The snapshot time of JSON output is not compared.
+func CheckOutput(s *schema.Schema, outputFile string, opts *OutputOptions, w io.Writer) error {
+	if opts == nil {
+		opts = &OutputOptions{}
+	}
+	if w == nil {
+		w = os.Stdout
+	}
+
+	var diff string
+	if opts.OutputDir != "" {
+		if opts.Format != "" && !strings.EqualFold(opts.Format, FormatMarkdown) {
+			return fmt.Errorf("checking an output directory is only supported for %s output", FormatMarkdown)
+		}
+		var err error
+		diff, err = newMultiFileFormatter(opts).Diff(s)
+		if err != nil {
+			return fmt.Errorf("failed to check output directory: %w", err)
+		}
+	} else {
+		if outputFile == "" {
+			return errors.New("an output file or directory is required to check output")
+		}
+
+		existing, err := os.ReadFile(outputFile)
+		oldName := outputFile
+		if os.IsNotExist(err) {
+			oldName = ""
+		} else if err != nil {
+			return fmt.Errorf("failed to read output file: %w", err)
+		}
+
+		// Every extraction has a new snapshot time, which is not a change
+		if strings.EqualFold(opts.Format, FormatJSON) {
+			if previous, err := schema.ReadJSON(bytes.NewReader(existing)); err == nil {
+				unchanged := *s
+				unchanged.SnapshotTime = previous.SnapshotTime
+				s = &unchanged
+			}
+		}
+
+		var rendered bytes.Buffer
+		normalized := *opts
+		normalized.Writer = &rendered
+		if err := FormatSchema(s, &normalized); err != nil {
+			return err
+		}
+		diff = textdiff.Unified(oldName, outputFile, existing, rendered.Bytes())
+	}
+
+	if diff == "" {
+		return nil
+	}
+	if _, err := io.WriteString(w, diff); err != nil {
+		return err
+	}
+	return ErrOutputOutdated
+}
+
+// ExtractAndCheck extracts a database schema like ExtractAndFormat and
+// compares the result with the existing output like CheckOutput.
+func ExtractAndCheck(ctx context.Context, databaseURL string, opts *Options, outputFile string, outOpts *OutputOptions, w io.Writer) error {
+	s, err := ExtractSchema(ctx, databaseURL, opts)
+	if err != nil {
+		return err
+	}
+
+	// Apply exclusions
+	if opts != nil && len(opts.ExcludeTables) > 0 {
+		filterExcludedTables(s, opts.ExcludeTables)
+	}
+
+	return CheckOutput(s, outputFile, outOpts, w)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
 	"runtime/debug"
@@ -12,22 +13,39 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tordrt/llmschema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 const databaseURLEnv = "DATABASE_URL"
 
 var version string
 
+// extractionFlags selects the database and the tables to extract
+type extractionFlags struct {
+	dbURL         string
+	tables        string
+	excludeTables string
+	schemaName    string
+	allSchemas    bool
+	jobs          int
+}
+
 type cliOptions struct {
-	dbURL              string
-	outputFile         string
-	outputDir          string
-	tables             string
-	excludeTables      string
-	schemaName         string
-	omitDatabaseInfo   bool
-	omitTableIndex     bool
-	preserveStaleFiles bool
+	extractionFlags
+	fromSnapshot        string
+	fromDDL             string
+	fromMigrations      string
+	dialect             string
+	check               bool
+	outputFile          string
+	outputDir           string
+	format              string
+	omitDatabaseInfo    bool
+	omitTableIndex      bool
+	omitComments        bool
+	omitViewDefinitions bool
+	preserveStaleFiles  bool
+	overviewDiagram     bool
 }
 
 type extractAndFormatFunc func(context.Context, string, *llmschema.Options, *llmschema.OutputOptions) error
@@ -70,34 +88,185 @@ func newRootCmd(extractAndFormat extractAndFormatFunc) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.dbURL, "db-url", "", "Database connection string (defaults to DATABASE_URL)")
+	opts.extractionFlags.register(cmd)
+	cmd.Flags().StringVar(&opts.fromSnapshot, "from-snapshot", "", "Format a schema snapshot file instead of connecting to a database")
+	cmd.Flags().StringVar(&opts.fromDDL, "from-ddl", "", "Build the schema from a SQL DDL file instead of connecting to a database")
+	cmd.Flags().StringVar(&opts.fromMigrations, "from-migrations", "", "Build the schema by replaying the up migrations of a directory instead of connecting to a database")
+	cmd.Flags().StringVar(&opts.dialect, "dialect", llmschema.DialectPostgres, fmt.Sprintf("SQL dialect of --from-ddl or --from-migrations (%s, %s, %s)", llmschema.DialectPostgres, llmschema.DialectMySQL, llmschema.DialectSQLite))
 	cmd.Flags().StringVarP(&opts.outputFile, "output", "o", "", "Output file for the single-file schema (default: stdout)")
 	cmd.Flags().StringVarP(&opts.outputDir, "output-dir", "d", "", "Output directory for optional multi-file output")
-	cmd.Flags().StringVarP(&opts.tables, "tables", "t", "", "Specific tables (comma-separated, optional)")
-	cmd.Flags().StringVarP(&opts.excludeTables, "exclude-tables", "e", "", "Tables to exclude (comma-separated, optional)")
-	cmd.Flags().StringVarP(&opts.schemaName, "schema", "s", "", "Database schema name (optional: defaults to 'public' for PostgreSQL, auto-detected from connection string for MySQL)")
+	cmd.Flags().StringVarP(&opts.format, "format", "f", llmschema.FormatMarkdown, fmt.Sprintf("Output format (%s)", strings.Join(llmschema.FormatterNames(), ", ")))
 	cmd.Flags().BoolVar(&opts.omitDatabaseInfo, "no-database-info", false, "Exclude database type, version, name, and schema from the output")
 	cmd.Flags().BoolVar(&opts.omitTableIndex, "no-table-index", false, "Exclude the table index from single-file output")
+	cmd.Flags().BoolVar(&opts.omitComments, "no-comments", false, "Exclude table and column comments from the output")
+	cmd.Flags().BoolVar(&opts.omitViewDefinitions, "no-view-definitions", false, "Exclude the defining SQL of views from the output")
 	cmd.Flags().BoolVar(&opts.preserveStaleFiles, "preserve-stale-files", false, "Do not delete table files generated by previous runs")
+	cmd.Flags().BoolVar(&opts.overviewDiagram, "overview-diagram", false, "Embed a Mermaid ER diagram in the multi-file overview")
+	cmd.Flags().BoolVar(&opts.check, "check", false, "Print a diff and exit non-zero if the output file or directory is out of date, without writing it")
 	cmd.MarkFlagsMutuallyExclusive("output", "output-dir")
+	cmd.MarkFlagsMutuallyExclusive("from-snapshot", "db-url")
+	cmd.MarkFlagsMutuallyExclusive("from-snapshot", "schema")
+	cmd.MarkFlagsMutuallyExclusive("from-snapshot", "all-schemas")
+	cmd.MarkFlagsMutuallyExclusive("from-ddl", "db-url")
+	cmd.MarkFlagsMutuallyExclusive("from-ddl", "from-snapshot")
+	cmd.MarkFlagsMutuallyExclusive("from-migrations", "db-url")
+	cmd.MarkFlagsMutuallyExclusive("from-migrations", "from-snapshot")
+	cmd.MarkFlagsMutuallyExclusive("from-migrations", "from-ddl")
+
+	cmd.AddCommand(newSnapshotCmd(extractAndFormat))
+	cmd.AddCommand(newDiffCmd())
 
 	return cmd
 }
 
-func (opts *cliOptions) run(cmd *cobra.Command, extractAndFormat extractAndFormatFunc) (err error) {
-	databaseURL := opts.dbURL
+func newSnapshotCmd(extractAndFormat extractAndFormatFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save schema snapshots for offline documentation",
+		Args:  cobra.NoArgs,
+	}
+
+	flags := &extractionFlags{}
+	saveCmd := &cobra.Command{
+		Use:   "save <file>",
+		Short: "Extract the schema and save it as a JSON snapshot",
+		Long:  `Extract the schema and save it as a JSON snapshot. Commit the snapshot and run llmschema --from-snapshot to generate documentation where the database is not reachable.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return flags.saveSnapshot(cmd, args[0], extractAndFormat)
+		},
+	}
+	flags.register(saveCmd)
+	cmd.AddCommand(saveCmd)
+
+	return cmd
+}
+
+func newDiffCmd() *cobra.Command {
+	flags := &extractionFlags{}
+	var outputFile, format string
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Report schema changes between two databases or snapshots",
+		Long:  `Report the tables, columns, keys, indexes, and references that changed between two schemas. Each schema is a database URL or a snapshot file saved with llmschema snapshot save.`,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			d, err := llmschema.DiffSchemas(cmd.Context(), args[0], args[1], flags.options())
+			if err != nil {
+				return err
+			}
+
+			outOpts := &llmschema.OutputOptions{Writer: cmd.OutOrStdout(), Format: format}
+			if outputFile != "" {
+				writer := &deferredFileWriter{path: outputFile}
+				defer func() {
+					err = errors.Join(err, writer.Close())
+				}()
+				outOpts.Writer = writer
+			}
+			return llmschema.FormatDiff(d, outOpts)
+		},
+	}
+
+	flags.registerSelection(cmd)
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for the diff (default: stdout)")
+	cmd.Flags().StringVarP(&format, "format", "f", llmschema.FormatMarkdown, fmt.Sprintf("Output format (%s, %s)", llmschema.FormatJSON, llmschema.FormatMarkdown))
+
+	return cmd
+}
+
+// register adds the extraction flags to cmd
+func (flags *extractionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flags.dbURL, "db-url", "", "Database connection string (defaults to DATABASE_URL)")
+	flags.registerSelection(cmd)
+}
+
+// registerSelection adds the flags that select schemas and tables, and how
+// many catalog queries run at once, to cmd
+func (flags *extractionFlags) registerSelection(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&flags.tables, "tables", "t", "", "Specific tables (comma-separated, optional)")
+	cmd.Flags().StringVarP(&flags.excludeTables, "exclude-tables", "e", "", "Tables to exclude (comma-separated, optional)")
+	cmd.Flags().StringVarP(&flags.schemaName, "schema", "s", "", "Database schema name, or comma-separated names for PostgreSQL (optional: defaults to 'public' for PostgreSQL, auto-detected from connection string for MySQL)")
+	cmd.Flags().BoolVar(&flags.allSchemas, "all-schemas", false, "Extract all non-system PostgreSQL schemas")
+	cmd.Flags().IntVarP(&flags.jobs, "jobs", "j", llmschema.DefaultConcurrency, "Number of catalog queries to run at once against PostgreSQL (up to 9; MySQL and SQLite run one at a time)")
+	cmd.MarkFlagsMutuallyExclusive("schema", "all-schemas")
+}
+
+func (flags *extractionFlags) databaseURL() (string, error) {
+	databaseURL := flags.dbURL
 	if databaseURL == "" {
 		databaseURL = os.Getenv(databaseURLEnv)
 	}
 	if databaseURL == "" {
-		return fmt.Errorf("--db-url is required or %s must be set", databaseURLEnv)
+		return "", fmt.Errorf("--db-url is required or %s must be set", databaseURLEnv)
+	}
+	return databaseURL, nil
+}
+
+func (flags *extractionFlags) options() *llmschema.Options {
+	opts := &llmschema.Options{
+		Tables:        parseTableList(flags.tables),
+		ExcludeTables: parseTableList(flags.excludeTables),
+		AllSchemas:    flags.allSchemas,
+		Concurrency:   flags.jobs,
+	}
+	if schemaNames := parseTableList(flags.schemaName); len(schemaNames) == 1 {
+		opts.SchemaName = schemaNames[0]
+	} else {
+		opts.SchemaNames = schemaNames
+	}
+	return opts
+}
+
+// saveSnapshot extracts the schema as JSON into path, leaving an existing
+// snapshot untouched when extraction fails
+func (flags *extractionFlags) saveSnapshot(cmd *cobra.Command, path string, extractAndFormat extractAndFormatFunc) (err error) {
+	databaseURL, err := flags.databaseURL()
+	if err != nil {
+		return err
+	}
+
+	writer := &deferredFileWriter{path: path}
+	defer func() {
+		err = errors.Join(err, writer.Close())
+	}()
+	return extractAndFormat(cmd.Context(), databaseURL, flags.options(), &llmschema.OutputOptions{
+		Writer: writer,
+		Format: llmschema.FormatJSON,
+	})
+}
+
+func (opts *cliOptions) run(cmd *cobra.Command, extractAndFormat extractAndFormatFunc) (err error) {
+	var databaseURL string
+	switch {
+	// DDL files and migrations are read by the sql:// and migrations://
+	// extractors, which also serve --check
+	case opts.fromDDL != "":
+		databaseURL = "sql://" + opts.fromDDL + "?dialect=" + url.QueryEscape(opts.dialect)
+	case opts.fromMigrations != "":
+		databaseURL = "migrations://" + opts.fromMigrations + "?dialect=" + url.QueryEscape(opts.dialect)
+	case cmd.Flags().Changed("dialect"):
+		return errors.New("--dialect requires --from-ddl or --from-migrations")
+	case opts.fromSnapshot == "":
+		databaseURL, err = opts.databaseURL()
+		if err != nil {
+			return err
+		}
 	}
 
 	outOpts := &llmschema.OutputOptions{
-		OutputDir:          opts.outputDir,
-		OmitDatabaseInfo:   opts.omitDatabaseInfo,
-		OmitTableIndex:     opts.omitTableIndex,
-		PreserveStaleFiles: opts.preserveStaleFiles,
+		OutputDir:           opts.outputDir,
+		Format:              opts.format,
+		OmitDatabaseInfo:    opts.omitDatabaseInfo,
+		OmitTableIndex:      opts.omitTableIndex,
+		OmitComments:        opts.omitComments,
+		OmitViewDefinitions: opts.omitViewDefinitions,
+		PreserveStaleFiles:  opts.preserveStaleFiles,
+		OverviewDiagram:     opts.overviewDiagram,
+	}
+
+	if opts.check {
+		return opts.checkOutput(cmd, databaseURL, outOpts)
 	}
 
 	if opts.outputFile != "" {
@@ -110,13 +279,33 @@ func (opts *cliOptions) run(cmd *cobra.Command, extractAndFormat extractAndForma
 		outOpts.Writer = cmd.OutOrStdout()
 	}
 
-	extractionOpts := &llmschema.Options{
-		Tables:        parseTableList(opts.tables),
-		ExcludeTables: parseTableList(opts.excludeTables),
-		SchemaName:    opts.schemaName,
+	if opts.fromSnapshot != "" {
+		return llmschema.FormatSnapshot(opts.fromSnapshot, opts.options(), outOpts)
+	}
+	return extractAndFormat(cmd.Context(), databaseURL, opts.options(), outOpts)
+}
+
+// checkOutput compares the existing output with what the command would write
+func (opts *cliOptions) checkOutput(cmd *cobra.Command, databaseURL string, outOpts *llmschema.OutputOptions) error {
+	if opts.outputFile == "" && opts.outputDir == "" {
+		return errors.New("--check requires --output or --output-dir")
 	}
 
-	return extractAndFormat(cmd.Context(), databaseURL, extractionOpts, outOpts)
+	var err error
+	if opts.fromSnapshot != "" {
+		var s *schema.Schema
+		s, err = llmschema.LoadSchema(cmd.Context(), opts.fromSnapshot, opts.options())
+		if err == nil {
+			err = llmschema.CheckOutput(s, opts.outputFile, outOpts, cmd.OutOrStdout())
+		}
+	} else {
+		err = llmschema.ExtractAndCheck(cmd.Context(), databaseURL, opts.options(), opts.outputFile, outOpts, cmd.OutOrStdout())
+	}
+	if errors.Is(err, llmschema.ErrOutputOutdated) {
+		// The diff already explains the failure
+		cmd.SilenceUsage = true
+	}
+	return err
 }
 
 func displayVersion() string {
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/tordrt/llmschema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 const testDatabaseURL = "sqlite://database.db"
@@ -108,6 +109,31 @@ func TestRootCommandValidation(t *testing.T) {
 			args:        []string{"--db-url", "invalid://database", "--output", "schema.md", "--output-dir", "schema"},
 			wantErrText: "if any flags in the group [output output-dir] are set none of the others can be",
 		},
+		{
+			name:        "snapshots do not connect to a database",
+			args:        []string{"--db-url", "invalid://database", "--from-snapshot", "schema.json"},
+			wantErrText: "if any flags in the group [from-snapshot db-url] are set none of the others can be",
+		},
+		{
+			name:        "DDL files do not connect to a database",
+			args:        []string{"--db-url", "invalid://database", "--from-ddl", "schema.sql"},
+			wantErrText: "if any flags in the group [from-ddl db-url] are set none of the others can be",
+		},
+		{
+			name:        "dialect requires a DDL file",
+			args:        []string{"--db-url", "invalid://database", "--dialect", "mysql"},
+			wantErrText: "--dialect requires --from-ddl or --from-migrations",
+		},
+		{
+			name:        "check requires an output",
+			args:        []string{"--db-url", "invalid://database", "--check"},
+			wantErrText: "--check requires --output or --output-dir",
+		},
+		{
+			name:        "snapshot save requires a file",
+			args:        []string{"snapshot", "save", "--db-url", "invalid://database"},
+			wantErrText: "accepts 1 arg(s), received 0",
+		},
 	}
 
 	for _, tt := range tests {
@@ -213,6 +239,9 @@ func TestRootCommandPassesContextAndOptionsToLibrary(t *testing.T) {
 		if outOpts.OutputDir != "docs/schema" {
 			t.Errorf("output directory = %q, want docs/schema", outOpts.OutputDir)
 		}
+		if outOpts.Format != "team-wiki" {
+			t.Errorf("format = %q, want team-wiki", outOpts.Format)
+		}
 		if outOpts.Writer != nil {
 			t.Errorf("writer = %v, want nil for multi-file output", outOpts.Writer)
 		}
@@ -225,6 +254,15 @@ func TestRootCommandPassesContextAndOptionsToLibrary(t *testing.T) {
 		if !outOpts.OmitTableIndex {
 			t.Error("OmitTableIndex = false, want true")
 		}
+		if !outOpts.OmitComments {
+			t.Error("OmitComments = false, want true")
+		}
+		if !outOpts.OmitViewDefinitions {
+			t.Error("OmitViewDefinitions = false, want true")
+		}
+		if !outOpts.OverviewDiagram {
+			t.Error("OverviewDiagram = false, want true")
+		}
 		return nil
 	})
 	cmd.SetArgs([]string{
@@ -233,9 +271,13 @@ func TestRootCommandPassesContextAndOptionsToLibrary(t *testing.T) {
 		"--exclude-tables", "migrations",
 		"--schema", "main",
 		"--output-dir", "docs/schema",
+		"--format", "team-wiki",
 		"--no-database-info",
 		"--no-table-index",
+		"--no-comments",
+		"--no-view-definitions",
 		"--preserve-stale-files",
+		"--overview-diagram",
 	})
 
 	if err := cmd.ExecuteContext(ctx); err != nil {
@@ -246,6 +288,41 @@ func TestRootCommandPassesContextAndOptionsToLibrary(t *testing.T) {
 	}
 }
 
+func TestRootCommandPassesMultipleSchemas(t *testing.T) {
+	var gotOpts *llmschema.Options
+	cmd := newRootCmd(func(_ context.Context, _ string, opts *llmschema.Options, _ *llmschema.OutputOptions) error {
+		gotOpts = opts
+		return nil
+	})
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"--db-url", testDatabaseURL, "--schema", "public, billing,auth"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if gotOpts.SchemaName != "" {
+		t.Errorf("schema name = %q, want empty", gotOpts.SchemaName)
+	}
+	assertStringsEqual(t, "schema names", gotOpts.SchemaNames, []string{"public", "billing", "auth"})
+}
+
+func TestRootCommandPassesAllSchemas(t *testing.T) {
+	var gotOpts *llmschema.Options
+	cmd := newRootCmd(func(_ context.Context, _ string, opts *llmschema.Options, _ *llmschema.OutputOptions) error {
+		gotOpts = opts
+		return nil
+	})
+	cmd.SetOut(io.Discard)
+	cmd.SetArgs([]string{"--db-url", testDatabaseURL, "--all-schemas"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if !gotOpts.AllSchemas {
+		t.Error("AllSchemas = false, want true")
+	}
+}
+
 func TestRootCommandWritesToStdoutByDefault(t *testing.T) {
 	var gotWriter io.Writer
 	cmd := newRootCmd(func(_ context.Context, _ string, _ *llmschema.Options, outOpts *llmschema.OutputOptions) error {
@@ -320,6 +397,166 @@ func TestRootCommandCreatesOutputWhenFormattingStarts(t *testing.T) {
 	}
 }
 
+func TestSnapshotSaveWritesJSONSnapshot(t *testing.T) {
+	t.Setenv(databaseURLEnv, testDatabaseURL)
+	snapshotPath := filepath.Join(t.TempDir(), "schema.json")
+	cmd := newRootCmd(func(_ context.Context, databaseURL string, opts *llmschema.Options, outOpts *llmschema.OutputOptions) error {
+		if databaseURL != testDatabaseURL {
+			t.Errorf("database URL = %q, want %q", databaseURL, testDatabaseURL)
+		}
+		assertStringsEqual(t, "excluded tables", opts.ExcludeTables, []string{"migrations"})
+		if outOpts.Format != llmschema.FormatJSON || outOpts.OutputDir != "" {
+			t.Errorf("output options = %+v, want JSON to a writer", outOpts)
+		}
+		return llmschema.FormatSchema(&schema.Schema{Tables: []schema.Table{{Name: "users"}}}, outOpts)
+	})
+	cmd.SetArgs([]string{"snapshot", "save", snapshotPath, "-e", "migrations"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	s, err := llmschema.LoadSnapshot(snapshotPath)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() failed: %v", err)
+	}
+	if len(s.Tables) != 1 || s.Tables[0].Name != "users" {
+		t.Errorf("snapshot tables = %+v, want users", s.Tables)
+	}
+}
+
+func TestRootCommandFormatsSnapshotWithoutDatabase(t *testing.T) {
+	t.Setenv(databaseURLEnv, "")
+	snapshotPath := filepath.Join(t.TempDir(), "schema.json")
+	s := &schema.Schema{DatabaseType: "SQLite", Tables: []schema.Table{{Name: "users"}, {Name: "migrations"}}}
+	if err := llmschema.SaveSnapshot(snapshotPath, s); err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	cmd := newRootCmd(func(context.Context, string, *llmschema.Options, *llmschema.OutputOptions) error {
+		t.Fatal("ExtractAndFormat called for a snapshot")
+		return nil
+	})
+	cmd.SetArgs([]string{"--from-snapshot", snapshotPath, "--output-dir", outputDir, "-e", "migrations"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "users.md")); err != nil {
+		t.Errorf("users.md was not generated: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "migrations.md")); !os.IsNotExist(err) {
+		t.Errorf("migrations.md exists despite being excluded: %v", err)
+	}
+}
+
+func TestRootCommandReadsDDLFileAsSQLURL(t *testing.T) {
+	t.Setenv(databaseURLEnv, "postgres://ignored")
+	var gotURL string
+	cmd := newRootCmd(func(_ context.Context, databaseURL string, _ *llmschema.Options, _ *llmschema.OutputOptions) error {
+		gotURL = databaseURL
+		return nil
+	})
+	cmd.SetArgs([]string{"--from-ddl", "db/schema.sql", "--dialect", "mysql"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if gotURL != "sql://db/schema.sql?dialect=mysql" {
+		t.Errorf("database URL = %q, want sql://db/schema.sql?dialect=mysql", gotURL)
+	}
+}
+
+func TestRootCommandReplaysMigrationsAsURL(t *testing.T) {
+	var gotURL string
+	cmd := newRootCmd(func(_ context.Context, databaseURL string, _ *llmschema.Options, _ *llmschema.OutputOptions) error {
+		gotURL = databaseURL
+		return nil
+	})
+	cmd.SetArgs([]string{"--from-migrations", "./migrations", "--dialect", "sqlite"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if gotURL != "migrations://./migrations?dialect=sqlite" {
+		t.Errorf("database URL = %q, want migrations://./migrations?dialect=sqlite", gotURL)
+	}
+}
+
+func TestDiffCommandComparesSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+	newPath := filepath.Join(dir, "new.json")
+	users := schema.Table{Name: "users", Columns: []schema.Column{{Name: "id", Type: "integer"}}}
+	if err := llmschema.SaveSnapshot(oldPath, &schema.Schema{Tables: []schema.Table{users}}); err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+	users.Columns = append(users.Columns, schema.Column{Name: "email", Type: "text", Nullable: true})
+	if err := llmschema.SaveSnapshot(newPath, &schema.Schema{Tables: []schema.Table{users}}); err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+
+	var output strings.Builder
+	cmd := newRootCmd(func(context.Context, string, *llmschema.Options, *llmschema.OutputOptions) error {
+		t.Fatal("ExtractAndFormat called for a diff")
+		return nil
+	})
+	cmd.SetOut(&output)
+	cmd.SetArgs([]string{"diff", oldPath, newPath})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if !strings.Contains(output.String(), "### `users`\n\n- Added column `email`: `text`\n") {
+		t.Errorf("diff output = %q, want email added to users", output.String())
+	}
+}
+
+func TestRootCommandCheckFailsOnStaleOutput(t *testing.T) {
+	dir := t.TempDir()
+	snapshotPath := filepath.Join(dir, "schema.json")
+	outputPath := filepath.Join(dir, "schema.md")
+	s := &schema.Schema{Tables: []schema.Table{{Name: "users"}}}
+	if err := llmschema.SaveSnapshot(snapshotPath, s); err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+
+	runCheck := func() (string, error) {
+		var output strings.Builder
+		cmd := newRootCmd(func(context.Context, string, *llmschema.Options, *llmschema.OutputOptions) error {
+			t.Fatal("ExtractAndFormat called for a check")
+			return nil
+		})
+		cmd.SetOut(&output)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"--from-snapshot", snapshotPath, "--output", outputPath, "--check"})
+		err := cmd.Execute()
+		return output.String(), err
+	}
+
+	diff, err := runCheck()
+	if !errors.Is(err, llmschema.ErrOutputOutdated) {
+		t.Fatalf("Execute() error = %v, want %v", err, llmschema.ErrOutputOutdated)
+	}
+	if !strings.Contains(diff, "+## users\n") || strings.Contains(diff, "Usage:") {
+		t.Errorf("check output = %q, want only the diff creating users", diff)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Fatalf("--check wrote the output; stat error = %v", err)
+	}
+
+	writer := &deferredFileWriter{path: outputPath}
+	if err := llmschema.FormatSnapshot(snapshotPath, nil, &llmschema.OutputOptions{Writer: writer}); err != nil {
+		t.Fatalf("FormatSnapshot() failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("failed to close output: %v", err)
+	}
+	if diff, err := runCheck(); err != nil || diff != "" {
+		t.Fatalf("check of current output = %q, %v; want no changes", diff, err)
+	}
+}
+
 func assertStringsEqual(t *testing.T, name string, got, want []string) {
 	t.Helper()
 	if len(got) != len(want) {
@@ -331,3 +568,34 @@ func assertStringsEqual(t *testing.T, name string, got, want []string) {
 		}
 	}
 }
+
+func TestRootCommandPassesJobsAsConcurrency(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "default", args: []string{"--db-url", testDatabaseURL}, want: llmschema.DefaultConcurrency},
+		{name: "long flag", args: []string{"--db-url", testDatabaseURL, "--jobs", "8"}, want: 8},
+		{name: "short flag", args: []string{"--db-url", testDatabaseURL, "-j", "1"}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOpts *llmschema.Options
+			cmd := newRootCmd(func(_ context.Context, _ string, opts *llmschema.Options, _ *llmschema.OutputOptions) error {
+				gotOpts = opts
+				return nil
+			})
+			cmd.SetOut(io.Discard)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			if gotOpts.Concurrency != tt.want {
+				t.Errorf("Concurrency = %d, want %d", gotOpts.Concurrency, tt.want)
+			}
+		})
+	}
+}
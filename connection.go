@@ -0,0 +1,115 @@
+package llmschema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/tordrt/llmschema/internal/db"
+	"github.com/tordrt/llmschema/schema"
+)
+
+// Dialects accepted by ExtractSchemaFromDB and ExtractSchemaFromDDL
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+)
+
+// PgxQuerier runs queries for ExtractSchemaFromPgx. *pgx.Conn, *pgxpool.Pool,
+// and pgx.Tx implement it.
+type PgxQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ExtractSchemaFromDB extracts database schema metadata through an existing
+// database/sql connection pool, such as one configured with custom
+// authentication, TLS, or tracing.
+//
+// The dialect is DialectPostgres, DialectMySQL, or DialectSQLite. PostgreSQL
+// pools must use the pgx driver (github.com/jackc/pgx/v5/stdlib); for other
+// PostgreSQL drivers, pass a pgx connection to ExtractSchemaFromPgx instead.
+//
+// Options behave as in ExtractSchema. When MySQL Options.SchemaName is empty,
+// the database selected on the connection is extracted.
+//
+// The caller keeps ownership of sqlDB: it is never closed.
+func ExtractSchemaFromDB(ctx context.Context, sqlDB *sql.DB, dialect string, opts *Options) (*schema.Schema, error) {
+	if sqlDB == nil {
+		return nil, errors.New("database connection is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	switch strings.ToLower(dialect) {
+	case DialectPostgres, "postgresql":
+		return extractPostgresSchemaFromDB(ctx, sqlDB, opts)
+	case DialectMySQL:
+		schemaName, err := singleSchemaName(opts, "MySQL")
+		if err != nil {
+			return nil, err
+		}
+		client := db.NewMySQLClientFromDB(sqlDB)
+		if schemaName == "" {
+			schemaName, err = client.CurrentDatabase(ctx)
+			if err != nil {
+				return nil, mySQLSchemaNameError(err)
+			}
+		}
+		return extractMySQLClientSchema(ctx, client, schemaName, opts.Tables)
+	case DialectSQLite:
+		if _, err := singleSchemaName(opts, "SQLite"); err != nil {
+			return nil, err
+		}
+		return extractSQLiteClientSchema(ctx, db.NewSQLiteClientFromDB(ctx, sqlDB), opts.Tables)
+	default:
+		return nil, fmt.Errorf("unsupported dialect %q (must be %s, %s, or %s)", dialect, DialectPostgres, DialectMySQL, DialectSQLite)
+	}
+}
+
+// ExtractSchemaFromPgx extracts PostgreSQL schema metadata through an existing
+// pgx connection, pool, or transaction. Options behave as in ExtractSchema.
+// Catalog queries run concurrently only through a *pgxpool.Pool. A pgx.Tx is
+// read as is, so its isolation level decides whether the schema is consistent.
+//
+// The caller keeps ownership of conn: it is never closed.
+func ExtractSchemaFromPgx(ctx context.Context, conn PgxQuerier, opts *Options) (*schema.Schema, error) {
+	if conn == nil {
+		return nil, errors.New("database connection is required")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	return extractPostgresClientSchema(ctx, db.NewPostgresClientFromQuerier(conn, opts.Concurrency), opts)
+}
+
+// extractPostgresSchemaFromDB borrows one pgx connection from the pool for the
+// duration of the extraction and returns it to the pool afterwards
+func extractPostgresSchemaFromDB(ctx context.Context, sqlDB *sql.DB, opts *Options) (*schema.Schema, error) {
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var s *schema.Schema
+	err = conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("PostgreSQL extraction requires the pgx database/sql driver, got %T (use ExtractSchemaFromPgx with a pgx connection instead)", driverConn)
+		}
+		var err error
+		s, err = ExtractSchemaFromPgx(ctx, pgxConn.Conn(), opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,134 @@
+package llmschema
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/tordrt/llmschema/internal/db"
+	"github.com/tordrt/llmschema/schema"
+)
+
+// ExtractSchemaFromDDL builds a schema from a DDL script, such as a schema
+// dump or a migration, without connecting to a database. The statements are
+// applied in order, so later ALTER and DROP statements change the tables that
+// earlier CREATE statements defined. Statements other than schema DDL, such
+// as INSERT or CREATE FUNCTION, are ignored.
+//
+// The dialect is DialectPostgres, DialectMySQL, or DialectSQLite, and
+// defaults to DialectPostgres.
+// Types and unnamed constraints are named the way that database would name
+// them, but expressions and defaults keep the text of the script, and view
+// columns are not known without a database.
+//
+// Options select tables and schemas as for ExtractSchema. Unqualified names
+// belong to the public schema for PostgreSQL unless the script sets the
+// search_path, and to the database selected with USE for MySQL.
+//
+// Example:
+//
+//	ddl, err := os.ReadFile("schema.sql")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	s, err := llmschema.ExtractSchemaFromDDL(string(ddl), llmschema.DialectMySQL, nil)
+func ExtractSchemaFromDDL(sqlText, dialect string, opts *Options) (*schema.Schema, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if dialect == "" {
+		dialect = DialectPostgres
+	}
+
+	model, err := db.NewDDLModel(dialect)
+	if err != nil {
+		return nil, err
+	}
+	if err := model.Apply(sqlText); err != nil {
+		return nil, fmt.Errorf("failed to apply DDL: %w", err)
+	}
+	return extractDDLModelSchema(model, opts)
+}
+
+// extractDDLModelSchema extracts the schemas selected by opts from a DDL model
+func extractDDLModelSchema(model *db.DDLModel, opts *Options) (*schema.Schema, error) {
+	switch model.Dialect() {
+	case DialectMySQL, DialectSQLite:
+		databaseType := "MySQL"
+		if model.Dialect() == DialectSQLite {
+			databaseType = "SQLite"
+		}
+		schemaName, err := singleSchemaName(opts, databaseType)
+		if err != nil {
+			return nil, err
+		}
+		return model.ExtractSchema(schemaName, opts.Tables)
+	}
+
+	schemaNames, err := requestedSchemaNames(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.AllSchemas {
+		schemaNames = model.Schemas()
+	}
+	if opts.AllSchemas || len(schemaNames) > 1 {
+		return model.ExtractSchemas(schemaNames, opts.Tables)
+	}
+	schemaName := ""
+	if len(schemaNames) == 1 {
+		schemaName = schemaNames[0]
+	}
+	return model.ExtractSchema(schemaName, opts.Tables)
+}
+
+// ddlExtractor reads sql://path/to/schema.sql?dialect=mysql URLs, so DDL
+// files can be used wherever a database URL is accepted
+type ddlExtractor struct{}
+
+func (ddlExtractor) ExtractSchema(_ context.Context, databaseURL string, opts *Options) (*schema.Schema, error) {
+	path, dialect, err := parseFileURL(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	sqlText, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DDL file: %w", err)
+	}
+	s, err := ExtractSchemaFromDDL(string(sqlText), dialect, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// parseFileURL returns the path and dialect of a sql:// or migrations:// URL.
+// The dialect is read from the query after the last "?", so paths are not escaped.
+func parseFileURL(databaseURL string) (string, string, error) {
+	scheme, path, _ := strings.Cut(databaseURL, "://")
+	dialect := DialectPostgres
+	if base, rawQuery, ok := cutLast(path, "?"); ok {
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid %s:// URL query: %w", scheme, err)
+		}
+		path = base
+		if value := query.Get("dialect"); value != "" {
+			dialect = value
+		}
+	}
+	if path == "" {
+		return "", "", fmt.Errorf("%s:// URL is missing the path", scheme)
+	}
+	return path, dialect, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
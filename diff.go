@@ -0,0 +1,71 @@
+package llmschema
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tordrt/llmschema/internal/formatter"
+	"github.com/tordrt/llmschema/schema"
+)
+
+// LoadSchema returns the schema of source, which is either a database URL or
+// the path of a snapshot file saved with SaveSnapshot. Options apply as in
+// ExtractAndFormat for URLs and as in FormatSnapshot for snapshots.
+func LoadSchema(ctx context.Context, source string, opts *Options) (*schema.Schema, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if !strings.Contains(source, "://") {
+		return loadSnapshotTables(source, opts)
+	}
+
+	s, err := ExtractSchema(ctx, source, opts)
+	if err != nil {
+		return nil, err
+	}
+	filterExcludedTables(s, opts.ExcludeTables)
+	return s, nil
+}
+
+// DiffSchemas compares the schemas of two sources, each a database URL or a
+// snapshot file as accepted by LoadSchema, and returns the changes from
+// oldSource to newSource. Use schema.Compare to compare schemas already in
+// memory.
+func DiffSchemas(ctx context.Context, oldSource, newSource string, opts *Options) (*schema.Diff, error) {
+	oldSchema, err := LoadSchema(ctx, oldSource, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load old schema: %w", err)
+	}
+	newSchema, err := LoadSchema(ctx, newSource, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load new schema: %w", err)
+	}
+	return schema.Compare(oldSchema, newSchema), nil
+}
+
+// FormatDiff writes d to OutputOptions.Writer as markdown, the default, or as
+// a versioned JSON document when Format is FormatJSON. Diffs are always a
+// single document, so OutputDir is not supported.
+func FormatDiff(d *schema.Diff, opts *OutputOptions) error {
+	if opts == nil {
+		opts = &OutputOptions{}
+	}
+	if opts.OutputDir != "" {
+		return fmt.Errorf("schema diffs are a single document and cannot be written to an output directory")
+	}
+	w := opts.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	switch strings.ToLower(opts.Format) {
+	case "", FormatMarkdown:
+		return formatter.NewDiffMarkdownFormatter(w).Format(d)
+	case FormatJSON:
+		return schema.WriteDiffJSON(w, d)
+	default:
+		return fmt.Errorf("unsupported diff format %q (must be %s or %s)", opts.Format, FormatMarkdown, FormatJSON)
+	}
+}
@@ -0,0 +1,134 @@
+package llmschema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// Extractor extracts a schema from the database identified by a connection URL.
+//
+// Extractors are registered by URL scheme with RegisterExtractor. ExtractSchema
+// and ExtractAndFormat dispatch to the extractor registered for the scheme of
+// their databaseURL, passing the complete URL and non-nil Options.
+type Extractor interface {
+	ExtractSchema(ctx context.Context, databaseURL string, opts *Options) (*schema.Schema, error)
+}
+
+// ExtractorFunc adapts an ordinary function to the Extractor interface.
+type ExtractorFunc func(ctx context.Context, databaseURL string, opts *Options) (*schema.Schema, error)
+
+// ExtractSchema calls f(ctx, databaseURL, opts).
+func (f ExtractorFunc) ExtractSchema(ctx context.Context, databaseURL string, opts *Options) (*schema.Schema, error) {
+	return f(ctx, databaseURL, opts)
+}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = map[string]Extractor{
+		"postgres":   postgresExtractor{},
+		"postgresql": postgresExtractor{},
+		"mysql":      mysqlExtractor{},
+		"sqlite":     sqliteExtractor{},
+		"sql":        ddlExtractor{},
+		"migrations": migrationsExtractor{},
+	}
+)
+
+// RegisterExtractor makes an extractor available for URLs with the given
+// scheme, such as "postgres" for postgres://... URLs. Schemes are matched
+// case-insensitively.
+//
+// Registering a scheme that is already registered replaces its extractor.
+// To wrap a built-in extractor, for example to add instrumentation, look it
+// up with LookupExtractor first and register the wrapper under the same scheme.
+//
+// RegisterExtractor panics if scheme is empty or contains "://", or if
+// extractor is nil.
+func RegisterExtractor(scheme string, extractor Extractor) {
+	if scheme == "" || strings.Contains(scheme, "://") {
+		panic(fmt.Sprintf("llmschema: invalid extractor scheme %q", scheme))
+	}
+	if extractor == nil {
+		panic("llmschema: RegisterExtractor extractor is nil")
+	}
+
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors[strings.ToLower(scheme)] = extractor
+}
+
+// LookupExtractor returns the extractor registered for scheme.
+func LookupExtractor(scheme string) (Extractor, bool) {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	extractor, ok := extractors[strings.ToLower(scheme)]
+	return extractor, ok
+}
+
+// ExtractorSchemes returns the registered URL schemes in sorted order.
+func ExtractorSchemes() []string {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	schemes := make([]string, 0, len(extractors))
+	for scheme := range extractors {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// parseDatabaseURL returns the extractor registered for the URL's scheme
+func parseDatabaseURL(url string) (Extractor, error) {
+	if url == "" {
+		return nil, fmt.Errorf("database URL is required")
+	}
+
+	scheme, _, ok := strings.Cut(url, "://")
+	if !ok || scheme == "" {
+		return nil, fmt.Errorf("invalid database URL (must start with a scheme such as %s)", formatSchemes(ExtractorSchemes()))
+	}
+
+	extractor, ok := LookupExtractor(scheme)
+	if !ok {
+		return nil, fmt.Errorf("unsupported database URL scheme %q (must be one of %s)", scheme, formatSchemes(ExtractorSchemes()))
+	}
+	return extractor, nil
+}
+
+func formatSchemes(schemes []string) string {
+	formatted := make([]string, len(schemes))
+	for i, scheme := range schemes {
+		formatted[i] = scheme + "://"
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// trimURLScheme returns the part of url after its scheme:// prefix
+func trimURLScheme(url string) string {
+	_, rest, _ := strings.Cut(url, "://")
+	return rest
+}
+
+type postgresExtractor struct{}
+
+func (postgresExtractor) ExtractSchema(ctx context.Context, databaseURL string, opts *Options) (*schema.Schema, error) {
+	return extractPostgresSchema(ctx, databaseURL, opts)
+}
+
+type mysqlExtractor struct{}
+
+func (mysqlExtractor) ExtractSchema(ctx context.Context, databaseURL string, opts *Options) (*schema.Schema, error) {
+	// The Go MySQL driver expects a DSN without the mysql:// prefix
+	return extractMySQLSchema(ctx, trimURLScheme(databaseURL), opts)
+}
+
+type sqliteExtractor struct{}
+
+func (sqliteExtractor) ExtractSchema(ctx context.Context, databaseURL string, opts *Options) (*schema.Schema, error) {
+	return extractSQLiteSchema(ctx, trimURLScheme(databaseURL), opts)
+}
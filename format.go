@@ -0,0 +1,165 @@
+package llmschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tordrt/llmschema/internal/formatter"
+	"github.com/tordrt/llmschema/schema"
+)
+
+// Built-in format names. FormatMarkdown is the default for OutputOptions.Format.
+const (
+	FormatMarkdown = "markdown"
+	// FormatJSON writes the complete schema as a versioned JSON document (see
+	// schema.JSONVersion), which schema.ReadJSON loads back. The Omit options
+	// do not apply to it.
+	FormatJSON = "json"
+	// FormatMermaid writes the tables and their relations as a Mermaid
+	// erDiagram. Views are not included.
+	FormatMermaid = "mermaid"
+	// FormatDBML writes tables, enums, indexes, and references as DBML for
+	// dbdiagram.io and other DBML tools. Views are not included.
+	FormatDBML = "dbml"
+)
+
+// Formatter renders a schema to the output it was created for.
+type Formatter interface {
+	Format(s *schema.Schema) error
+}
+
+// FormatterFunc adapts an ordinary function to the Formatter interface.
+type FormatterFunc func(s *schema.Schema) error
+
+// Format calls f(s).
+func (f FormatterFunc) Format(s *schema.Schema) error {
+	return f(s)
+}
+
+// FormatterFactory creates a Formatter for one FormatSchema call.
+//
+// The options are never nil. When OutputDir is empty, Writer is set, defaulting
+// to os.Stdout; when OutputDir is set, the formatter should write files there.
+// A format that supports only one of the two may return an error for the other.
+type FormatterFactory func(opts *OutputOptions) (Formatter, error)
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]FormatterFactory{
+		FormatMarkdown: newMarkdownFormatter,
+		FormatJSON:     newJSONFormatter,
+		FormatMermaid:  newMermaidFormatter,
+		FormatDBML:     newDBMLFormatter,
+	}
+)
+
+// RegisterFormatter makes a format available under name for
+// OutputOptions.Format and the --format CLI flag. Names are matched
+// case-insensitively.
+//
+// Registering a name that is already registered replaces its factory.
+//
+// RegisterFormatter panics if name is empty or factory is nil.
+func RegisterFormatter(name string, factory FormatterFactory) {
+	if name == "" {
+		panic("llmschema: RegisterFormatter name is empty")
+	}
+	if factory == nil {
+		panic("llmschema: RegisterFormatter factory is nil")
+	}
+
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	formatters[strings.ToLower(name)] = factory
+}
+
+// LookupFormatter returns the factory registered for name.
+func LookupFormatter(name string) (FormatterFactory, bool) {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	factory, ok := formatters[strings.ToLower(name)]
+	return factory, ok
+}
+
+// FormatterNames returns the registered format names in sorted order.
+func FormatterNames() []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newFormatter creates the formatter selected by opts.Format
+func newFormatter(opts *OutputOptions) (Formatter, error) {
+	name := opts.Format
+	if name == "" {
+		name = FormatMarkdown
+	}
+	factory, ok := LookupFormatter(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (must be one of %s)", name, strings.Join(FormatterNames(), ", "))
+	}
+	return factory(opts)
+}
+
+// newMarkdownFormatter writes a single markdown document to Writer, or an
+// overview and one file per table to OutputDir
+func newMarkdownFormatter(opts *OutputOptions) (Formatter, error) {
+	if opts.OutputDir != "" {
+		return newMultiFileFormatter(opts), nil
+	}
+
+	f := formatter.NewMarkdownFormatter(opts.Writer)
+	f.OmitDatabaseInfo = opts.OmitDatabaseInfo
+	f.OmitTableIndex = opts.OmitTableIndex
+	f.OmitComments = opts.OmitComments
+	f.OmitViewDefinitions = opts.OmitViewDefinitions
+	return f, nil
+}
+
+func newMultiFileFormatter(opts *OutputOptions) *formatter.MultiFileFormatter {
+	f := formatter.NewMultiFileFormatter(opts.OutputDir, "markdown")
+	f.OmitDatabaseInfo = opts.OmitDatabaseInfo
+	f.OmitComments = opts.OmitComments
+	f.OmitViewDefinitions = opts.OmitViewDefinitions
+	f.PreserveStaleFiles = opts.PreserveStaleFiles
+	f.OverviewDiagram = opts.OverviewDiagram
+	return f
+}
+
+// newJSONFormatter writes the schema as one JSON document to Writer
+func newJSONFormatter(opts *OutputOptions) (Formatter, error) {
+	if opts.OutputDir != "" {
+		return nil, fmt.Errorf("%s output is a single document and cannot be written to an output directory", FormatJSON)
+	}
+	return FormatterFunc(func(s *schema.Schema) error {
+		return schema.WriteJSON(opts.Writer, s)
+	}), nil
+}
+
+// newMermaidFormatter writes the schema as one Mermaid erDiagram to Writer
+func newMermaidFormatter(opts *OutputOptions) (Formatter, error) {
+	if opts.OutputDir != "" {
+		return nil, fmt.Errorf("%s output is a single document and cannot be written to an output directory", FormatMermaid)
+	}
+	f := formatter.NewMermaidFormatter(opts.Writer)
+	f.OmitComments = opts.OmitComments
+	return f, nil
+}
+
+// newDBMLFormatter writes the schema as one DBML document to Writer
+func newDBMLFormatter(opts *OutputOptions) (Formatter, error) {
+	if opts.OutputDir != "" {
+		return nil, fmt.Errorf("%s output is a single document and cannot be written to an output directory", FormatDBML)
+	}
+	f := formatter.NewDBMLFormatter(opts.Writer)
+	f.OmitDatabaseInfo = opts.OmitDatabaseInfo
+	f.OmitComments = opts.OmitComments
+	return f, nil
+}
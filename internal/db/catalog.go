@@ -0,0 +1,55 @@
+package db
+
+import (
+	"github.com/tordrt/llmschema/schema"
+)
+
+// catalog holds the metadata of the extracted tables and views of one schema,
+// keyed by relation name. It is loaded with one set-based query per kind of
+// metadata, so the number of queries does not grow with the number of tables.
+type catalog struct {
+	comments     map[string]string
+	columns      map[string][]schema.Column
+	primaryKeys  map[string][]string
+	indexes      map[string][]schema.Index
+	checks       map[string][]schema.CheckConstraint
+	relations    map[string][]schema.Relation // not yet finalized
+	dependencies map[string][]string
+}
+
+// table assembles the named table from the catalog
+func (c *catalog) table(name string) schema.Table {
+	table := schema.Table{
+		Name:       name,
+		Comment:    c.comments[name],
+		Columns:    c.columns[name],
+		PrimaryKey: c.primaryKeys[name],
+		Indexes:    c.indexes[name],
+	}
+	applyUniqueKeys(&table)
+	applyCheckConstraints(&table, c.checks[name])
+
+	// Relations are finalized after keys and indexes so cardinality can be inferred.
+	for _, relation := range c.relations[name] {
+		finalizeRelation(&relation, table.PrimaryKey, table.Indexes)
+		table.Relations = append(table.Relations, relation)
+	}
+	return table
+}
+
+// view assembles the view described by metadata from the catalog
+func (c *catalog) view(metadata viewMetadata) schema.View {
+	view := newView(metadata, c.columns[metadata.name], c.dependencies[metadata.name])
+	view.Comment = c.comments[metadata.name]
+	return view
+}
+
+// relationNames returns the names of the tables and views to extract
+func relationNames(tableNames []string, views []viewMetadata) []string {
+	names := make([]string, 0, len(tableNames)+len(views))
+	names = append(names, tableNames...)
+	for _, view := range views {
+		names = append(names, view.name)
+	}
+	return names
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+func TestCatalogAssemblesTablesAndViews(t *testing.T) {
+	c := &catalog{
+		comments: map[string]string{"profiles": "One per user", "active_profiles": "Profiles in use"},
+		columns: map[string][]schema.Column{
+			"profiles":        {{Name: "id", Type: "integer"}, {Name: "user_id", Type: "integer"}, {Name: "age", Type: "integer"}},
+			"active_profiles": {{Name: "id", Type: "integer", Nullable: true}},
+		},
+		primaryKeys: map[string][]string{"profiles": {"id"}},
+		indexes: map[string][]schema.Index{
+			"profiles": {{Name: "profiles_user_id_key", Columns: []string{"user_id"}, IsUnique: true}},
+		},
+		checks: map[string][]schema.CheckConstraint{
+			"profiles": {{Name: "profiles_age_check", Expression: "age >= 0", Columns: []string{"age"}}},
+		},
+		relations: map[string][]schema.Relation{
+			"profiles": {{Name: "profiles_user_id_fkey", SourceColumns: []string{"user_id"}, TargetTable: "users", TargetColumns: []string{"id"}}},
+		},
+		dependencies: map[string][]string{"active_profiles": {"profiles"}},
+	}
+
+	table := c.table("profiles")
+	if table.Comment != "One per user" || !slices.Equal(table.PrimaryKey, []string{"id"}) {
+		t.Errorf("table = %+v, want comment and primary key", table)
+	}
+	if !table.Columns[1].IsUnique {
+		t.Error("user_id is not unique, want unique from its index")
+	}
+	if table.Columns[2].CheckConstraint == nil || *table.Columns[2].CheckConstraint != "age >= 0" {
+		t.Errorf("age check = %v, want age >= 0", table.Columns[2].CheckConstraint)
+	}
+	if len(table.Relations) != 1 || table.Relations[0].Cardinality != "1:1" || table.Relations[0].SourceColumn != "user_id" {
+		t.Errorf("relations = %+v, want a finalized 1:1 relation on user_id", table.Relations)
+	}
+
+	view := c.view(viewMetadata{name: "active_profiles", definition: " SELECT id FROM profiles "})
+	if view.Comment != "Profiles in use" || view.Definition != "SELECT id FROM profiles" ||
+		len(view.Columns) != 1 || !slices.Equal(view.Dependencies, []string{"profiles"}) {
+		t.Errorf("view = %+v, want columns, dependencies, and comment from the catalog", view)
+	}
+
+	if missing := c.table("missing"); missing.Columns != nil || missing.Relations != nil {
+		t.Errorf("missing table = %+v, want an empty table", missing)
+	}
+}
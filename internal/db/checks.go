@@ -0,0 +1,64 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// applyCheckConstraints attaches checks on a single column to that column and
+// keeps the others, including additional checks on an annotated column, as
+// table-level checks
+func applyCheckConstraints(table *schema.Table, checks []schema.CheckConstraint) {
+	for _, check := range checks {
+		if len(check.Columns) == 1 {
+			if column := findColumn(table.Columns, check.Columns[0]); column != nil && column.CheckConstraint == nil {
+				expression := check.Expression
+				column.CheckConstraint = &expression
+				continue
+			}
+		}
+		table.Checks = append(table.Checks, check)
+	}
+}
+
+func findColumn(columns []schema.Column, name string) *schema.Column {
+	for i := range columns {
+		if columns[i].Name == name {
+			return &columns[i]
+		}
+	}
+	return nil
+}
+
+// unwrapCheckExpression removes a leading CHECK keyword and the parentheses
+// that enclose the whole condition
+func unwrapCheckExpression(definition string) string {
+	expression := strings.TrimSpace(definition)
+	if tokens := tokenizeSQL(expression); len(tokens) > 0 && tokens[0].isKeyword("CHECK") {
+		expression = expression[tokens[0].end:]
+	}
+	return unwrapSQLExpression(expression)
+}
+
+// checkExpressionColumns returns the columns, in table order, that a CHECK
+// expression refers to. Identifiers are matched case-insensitively, and
+// function names and string literals are ignored.
+func checkExpressionColumns(expression string, columns []schema.Column) []string {
+	tokens := tokenizeSQL(expression)
+	referenced := make(map[string]bool)
+	for i, token := range tokens {
+		if !token.isIdentifier() || (i+1 < len(tokens) && tokens[i+1].isPunctuation("(")) {
+			continue
+		}
+		referenced[strings.ToLower(token.identifier())] = true
+	}
+
+	var names []string
+	for _, column := range columns {
+		if referenced[strings.ToLower(column.Name)] {
+			names = append(names, column.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency is the number of catalog queries run at once when a
+// client is created with a concurrency of zero
+const DefaultConcurrency = 4
+
+// resolveConcurrency returns concurrency, or DefaultConcurrency when it is
+// not positive
+func resolveConcurrency(concurrency int) int {
+	if concurrency <= 0 {
+		return DefaultConcurrency
+	}
+	return concurrency
+}
+
+// runConcurrently calls each task with at most concurrency tasks running at
+// once. The first error cancels the context passed to the other tasks and is
+// returned.
+func runConcurrently(ctx context.Context, concurrency int, tasks ...func(ctx context.Context) error) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(concurrency, 1))
+	for _, task := range tasks {
+		if ctx.Err() != nil {
+			// An earlier task failed; Wait returns its error
+			break
+		}
+		g.Go(func() error { return task(ctx) })
+	}
+	return g.Wait()
+}
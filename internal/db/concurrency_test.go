@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunConcurrentlyLimitsRunningTasks(t *testing.T) {
+	var running, peak, finished atomic.Int32
+	task := func(context.Context) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		finished.Add(1)
+		return nil
+	}
+
+	if err := runConcurrently(context.Background(), 3, task, task, task, task, task, task, task, task); err != nil {
+		t.Fatalf("runConcurrently() failed: %v", err)
+	}
+	if finished.Load() != 8 {
+		t.Errorf("%d tasks finished, want 8", finished.Load())
+	}
+	if peak.Load() > 3 {
+		t.Errorf("%d tasks ran at once, want at most 3", peak.Load())
+	}
+}
+
+func TestRunConcurrentlyCancelsOnFirstError(t *testing.T) {
+	errBroken := errors.New("broken")
+	slow := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			t.Error("task was not cancelled")
+			return nil
+		}
+	}
+
+	err := runConcurrently(context.Background(), 2, func(context.Context) error { return errBroken }, slow, slow, slow)
+	if !errors.Is(err, errBroken) {
+		t.Fatalf("error = %v, want %v", err, errBroken)
+	}
+}
+
+func TestNewPostgresClientFromQuerierLimitsConnections(t *testing.T) {
+	if got := NewPostgresClientFromQuerier(nil, 8).concurrency; got != 1 {
+		t.Errorf("concurrency through a single connection = %d, want 1", got)
+	}
+	if got := resolveConcurrency(0); got != DefaultConcurrency {
+		t.Errorf("resolveConcurrency(0) = %d, want %d", got, DefaultConcurrency)
+	}
+}
@@ -0,0 +1,500 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// Dialects accepted by NewDDLModel
+const (
+	DDLDialectPostgres = "postgres"
+	DDLDialectMySQL    = "mysql"
+	DDLDialectSQLite   = "sqlite"
+)
+
+// DDLModel is a schema built from DDL statements instead of a database
+// catalog. Statements are applied in order, so ALTER and DROP statements
+// change what earlier CREATE statements defined, as they would in a database.
+//
+// CREATE TABLE, CREATE INDEX, CREATE TYPE ... AS ENUM, CREATE VIEW, ALTER
+// TABLE, ALTER TYPE ... ADD VALUE, DROP, COMMENT ON, USE, and SET search_path
+// statements are understood; all other statements, such as INSERT or CREATE
+// FUNCTION, are ignored.
+type DDLModel struct {
+	dialect       string
+	currentSchema string
+	tables        []*ddlTable
+	views         []*ddlView
+	enums         []*ddlEnum
+}
+
+// ddlTable is a table with the constraint names needed to alter it later
+type ddlTable struct {
+	schemaName     string
+	table          schema.Table
+	primaryKeyName string
+	createSQL      string
+}
+
+type ddlView struct {
+	schemaName string
+	view       schema.View
+}
+
+type ddlEnum struct {
+	schemaName string
+	name       string
+	values     []string
+}
+
+// NewDDLModel creates an empty model for DDL in the given dialect
+func NewDDLModel(dialect string) (*DDLModel, error) {
+	m := &DDLModel{dialect: strings.ToLower(dialect)}
+	switch m.dialect {
+	case DDLDialectPostgres, "postgresql":
+		m.dialect = DDLDialectPostgres
+		m.currentSchema = "public"
+	case DDLDialectMySQL:
+	case DDLDialectSQLite:
+		m.currentSchema = "main"
+	default:
+		return nil, fmt.Errorf("unsupported DDL dialect %q (must be %s, %s, or %s)", dialect, DDLDialectPostgres, DDLDialectMySQL, DDLDialectSQLite)
+	}
+	return m, nil
+}
+
+// Apply applies the statements of a DDL script to the model. Errors name the
+// line of the statement that could not be applied.
+func (m *DDLModel) Apply(sqlText string) error {
+	for _, statement := range splitDDLStatements(sqlText, m.dialect) {
+		c := &ddlCursor{text: statement.text, tokens: m.tokenize(statement.text)}
+		if err := m.applyStatement(c); err != nil {
+			return fmt.Errorf("line %d: %w", statement.line, err)
+		}
+	}
+	return nil
+}
+
+// tokenize splits SQL text of the model's dialect into tokens
+func (m *DDLModel) tokenize(sqlText string) []sqlToken {
+	return tokenizeDDL(sqlText, m.dialect)
+}
+
+// tokenizeDDL splits SQL text of the given dialect into tokens
+func tokenizeDDL(sqlText, dialect string) []sqlToken {
+	if dialect == DDLDialectMySQL {
+		return tokenizeMySQL(sqlText)
+	}
+	return tokenizeSQL(sqlText)
+}
+
+// Dialect returns the dialect of the model
+func (m *DDLModel) Dialect() string {
+	return m.dialect
+}
+
+// DefaultSchema returns the schema that unqualified names refer to: public
+// or the schema set with SET search_path for PostgreSQL, the database selected
+// with USE for MySQL, and main for SQLite
+func (m *DDLModel) DefaultSchema() string {
+	return m.currentSchema
+}
+
+// Schemas returns the names of the schemas that contain tables or views
+func (m *DDLModel) Schemas() []string {
+	seen := make(map[string]bool)
+	var schemaNames []string
+	add := func(schemaName string) {
+		if !seen[schemaName] {
+			seen[schemaName] = true
+			schemaNames = append(schemaNames, schemaName)
+		}
+	}
+	for _, table := range m.tables {
+		add(table.schemaName)
+	}
+	for _, view := range m.views {
+		add(view.schemaName)
+	}
+	sort.Strings(schemaNames)
+	return schemaNames
+}
+
+// ExtractSchema returns the tables and views of one schema, or of the
+// default schema when schemaName is empty. Requested tables and views are
+// returned in the requested order; otherwise all are returned by name.
+func (m *DDLModel) ExtractSchema(schemaName string, tables []string) (*schema.Schema, error) {
+	if schemaName == "" {
+		schemaName = m.currentSchema
+	}
+	extracted, err := m.extractSchema(schemaName, tables, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) > 0 {
+		for _, name := range tables {
+			if !slices.ContainsFunc(extracted.Tables, func(table schema.Table) bool { return table.Name == name }) &&
+				!slices.ContainsFunc(extracted.Views, func(view schema.View) bool { return view.Name == name }) {
+				return nil, fmt.Errorf("table %q is not defined in the DDL", name)
+			}
+		}
+	}
+
+	switch m.dialect {
+	case DDLDialectPostgres:
+		extracted.SchemaName = schemaName
+	case DDLDialectMySQL:
+		extracted.DatabaseName = schemaName
+		extracted.SchemaName = schemaName
+	}
+	return extracted, nil
+}
+
+// ExtractSchemas returns the tables and views of several schemas, qualified
+// by their schema like ExtractPostgresSchemas. Requested tables may be
+// qualified as schema.table; unqualified names match in every schema.
+func (m *DDLModel) ExtractSchemas(schemaNames []string, tables []string) (*schema.Schema, error) {
+	merged := &schema.Schema{DatabaseType: m.databaseType(), Schemas: schemaNames}
+	found := make(map[string]bool)
+	for _, schemaName := range schemaNames {
+		requested, unqualified := splitQualifiedTables(schemaName, schemaNames, tables)
+		requested = append(requested, unqualified...)
+		if len(tables) > 0 && len(requested) == 0 {
+			continue
+		}
+
+		extracted, err := m.extractSchema(schemaName, requested, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range extracted.Tables {
+			found[table.Name] = true
+			found[schema.QualifiedName(schemaName, table.Name)] = true
+		}
+		for _, view := range extracted.Views {
+			found[view.Name] = true
+			found[schema.QualifiedName(schemaName, view.Name)] = true
+		}
+		qualifySchema(extracted, schemaName)
+		merged.Tables = append(merged.Tables, extracted.Tables...)
+		merged.Views = append(merged.Views, extracted.Views...)
+	}
+
+	for _, name := range tables {
+		if !found[name] {
+			return nil, fmt.Errorf("table %q is not defined in the DDL", name)
+		}
+	}
+	return merged, nil
+}
+
+func (m *DDLModel) databaseType() string {
+	switch m.dialect {
+	case DDLDialectPostgres:
+		return "PostgreSQL"
+	case DDLDialectMySQL:
+		return "MySQL"
+	default:
+		return "SQLite"
+	}
+}
+
+// extractSchema assembles the tables and views of schemaName. Requested names
+// that do not exist in the schema are skipped. References keep their target
+// schema when qualified is set or when they leave the schema.
+func (m *DDLModel) extractSchema(schemaName string, requested []string, qualified bool) (*schema.Schema, error) {
+	var tables []*ddlTable
+	var views []*ddlView
+	if len(requested) == 0 {
+		for _, table := range m.tables {
+			if strings.EqualFold(table.schemaName, schemaName) {
+				tables = append(tables, table)
+			}
+		}
+		for _, view := range m.views {
+			if strings.EqualFold(view.schemaName, schemaName) {
+				views = append(views, view)
+			}
+		}
+		sort.SliceStable(tables, func(i, j int) bool { return tables[i].table.Name < tables[j].table.Name })
+		sort.SliceStable(views, func(i, j int) bool { return views[i].view.Name < views[j].view.Name })
+	} else {
+		for _, name := range requested {
+			if table := m.findTable(schemaName, name); table != nil {
+				tables = append(tables, table)
+			} else if view := m.findView(schemaName, name); view != nil {
+				views = append(views, view)
+			}
+		}
+	}
+
+	s := &schema.Schema{DatabaseType: m.databaseType()}
+	for _, table := range tables {
+		s.Tables = append(s.Tables, m.assembleTable(table, schemaName, qualified))
+	}
+	for _, view := range views {
+		s.Views = append(s.Views, m.assembleView(view))
+	}
+	return s, nil
+}
+
+// assembleTable returns a copy of a table with the derived information that
+// extractors read from the catalog: unique keys, check columns, enum values,
+// and the targets and cardinality of relations
+func (m *DDLModel) assembleTable(t *ddlTable, schemaName string, qualified bool) schema.Table {
+	table := t.table
+	table.Columns = slices.Clone(t.table.Columns)
+	table.Indexes = slices.Clone(t.table.Indexes)
+	table.Relations = nil
+	table.Checks = nil
+	table.UniqueKeys = nil
+
+	for i := range table.Columns {
+		if enum := m.findEnumType(t.schemaName, table.Columns[i].Type); enum != nil {
+			table.Columns[i].Type = enum.name
+			table.Columns[i].EnumValues = slices.Clone(enum.values)
+		}
+	}
+	applyUniqueKeys(&table)
+
+	checks := make([]schema.CheckConstraint, len(t.table.Checks))
+	for i, check := range t.table.Checks {
+		checks[i] = check
+		if check.Columns == nil {
+			checks[i].Columns = checkExpressionColumns(check.Expression, table.Columns)
+		}
+	}
+	applyCheckConstraints(&table, checks)
+
+	if m.dialect == DDLDialectSQLite {
+		markSQLiteRowidAlias(&table, t.createSQL)
+		// PRAGMA index_list lists the newest index first
+		slices.Reverse(table.Indexes)
+	}
+
+	for _, relation := range t.table.Relations {
+		relation.SourceColumns = slices.Clone(relation.SourceColumns)
+		relation.TargetColumns = slices.Clone(relation.TargetColumns)
+		if len(relation.TargetColumns) == 0 {
+			if target := m.findTable(relation.TargetSchema, relation.TargetTable); target != nil {
+				relation.TargetColumns = slices.Clone(target.table.PrimaryKey)
+			}
+		}
+		if m.dialect == DDLDialectSQLite || (!qualified && strings.EqualFold(relation.TargetSchema, schemaName)) {
+			relation.TargetSchema = ""
+		}
+		finalizeRelation(&relation, table.PrimaryKey, table.Indexes)
+		table.Relations = append(table.Relations, relation)
+	}
+	if m.dialect == DDLDialectSQLite {
+		// PRAGMA foreign_key_list lists the last declared foreign key first
+		slices.Reverse(table.Relations)
+	}
+	return table
+}
+
+// assembleView returns a copy of a view with its dependencies resolved to
+// the tables and views of its schema
+func (m *DDLModel) assembleView(v *ddlView) schema.View {
+	view := v.view
+	view.Columns = slices.Clone(v.view.Columns)
+
+	seen := map[string]bool{view.Name: true}
+	view.Dependencies = nil
+	for _, reference := range sqliteQueryRelations(view.Definition) {
+		name := ""
+		if table := m.findTable(v.schemaName, m.foldName(reference)); table != nil {
+			name = table.table.Name
+		} else if dependency := m.findView(v.schemaName, m.foldName(reference)); dependency != nil {
+			name = dependency.view.Name
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			view.Dependencies = append(view.Dependencies, name)
+		}
+	}
+	sort.Strings(view.Dependencies)
+	return view
+}
+
+func (m *DDLModel) findTable(schemaName, name string) *ddlTable {
+	for _, table := range m.tables {
+		if strings.EqualFold(table.schemaName, schemaName) && strings.EqualFold(table.table.Name, name) {
+			return table
+		}
+	}
+	return nil
+}
+
+func (m *DDLModel) findView(schemaName, name string) *ddlView {
+	for _, view := range m.views {
+		if strings.EqualFold(view.schemaName, schemaName) && strings.EqualFold(view.view.Name, name) {
+			return view
+		}
+	}
+	return nil
+}
+
+func (m *DDLModel) findEnum(schemaName, name string) *ddlEnum {
+	for _, enum := range m.enums {
+		if strings.EqualFold(enum.schemaName, schemaName) && strings.EqualFold(enum.name, name) {
+			return enum
+		}
+	}
+	return nil
+}
+
+// findEnumType returns the enum a PostgreSQL column type names, which may
+// be qualified by its schema
+func (m *DDLModel) findEnumType(schemaName, columnType string) *ddlEnum {
+	if m.dialect != DDLDialectPostgres {
+		return nil
+	}
+	typeName := strings.TrimSuffix(columnType, "[]")
+	if typeName != columnType {
+		return nil
+	}
+	if qualifier, name, ok := strings.Cut(typeName, "."); ok {
+		return m.findEnum(qualifier, name)
+	}
+	if enum := m.findEnum(schemaName, typeName); enum != nil {
+		return enum
+	}
+	return m.findEnum("public", typeName)
+}
+
+// foldName lowercases unquoted names the way PostgreSQL does
+func (m *DDLModel) foldName(name string) string {
+	if m.dialect == DDLDialectPostgres {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// ddlStatement is one statement of a DDL script
+type ddlStatement struct {
+	text string
+	line int // line of the statement's first token
+}
+
+// splitDDLStatements splits a script at semicolons outside quotes, comments,
+// PostgreSQL dollar-quoted bodies, and trigger bodies. MySQL scripts may
+// change the delimiter with DELIMITER lines, as the mysql client allows.
+func splitDDLStatements(sqlText, dialect string) []ddlStatement {
+	var statements []ddlStatement
+	delimiter := ";"
+	start := 0
+	emit := func(end int) {
+		text := sqlText[start:end]
+		tokens := tokenizeDDL(text, dialect)
+		if len(tokens) == 0 {
+			return
+		}
+		statements = append(statements, ddlStatement{
+			text: strings.TrimSpace(text),
+			line: strings.Count(sqlText[:start+tokens[0].start], "\n") + 1,
+		})
+	}
+
+	for i := 0; i < len(sqlText); {
+		c := sqlText[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = scanQuoted(sqlText, i, dialect == DDLDialectMySQL && c != '`')
+		case strings.HasPrefix(sqlText[i:], "--") || (c == '#' && dialect == DDLDialectMySQL):
+			if end := strings.IndexByte(sqlText[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(sqlText)
+			}
+		case strings.HasPrefix(sqlText[i:], "/*"):
+			if end := strings.Index(sqlText[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(sqlText)
+			}
+		case c == '$' && dialect == DDLDialectPostgres:
+			i = scanDollarQuoted(sqlText, i)
+		case dialect == DDLDialectMySQL && isDelimiterCommand(sqlText, i):
+			emit(i)
+			end := strings.IndexByte(sqlText[i:], '\n')
+			if end < 0 {
+				end = len(sqlText) - i
+			}
+			if fields := strings.Fields(sqlText[i : i+end]); len(fields) > 1 {
+				delimiter = fields[1]
+			}
+			i += end
+			start = i
+		case strings.HasPrefix(sqlText[i:], delimiter):
+			if !hasOpenTriggerBody(tokenizeDDL(sqlText[start:i], dialect)) {
+				emit(i)
+				start = i + len(delimiter)
+			}
+			i += len(delimiter)
+		default:
+			i++
+		}
+	}
+	emit(len(sqlText))
+	return statements
+}
+
+// scanDollarQuoted returns the offset just past a $tag$...$tag$ string
+// starting at start, or just past the $ when none starts there
+func scanDollarQuoted(sqlText string, start int) int {
+	end := start + 1
+	for end < len(sqlText) && sqlText[end] != '$' {
+		if c := sqlText[end]; !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (end > start+1 && isSQLDigit(c))) {
+			return start + 1
+		}
+		end++
+	}
+	if end >= len(sqlText) {
+		return start + 1
+	}
+	tag := sqlText[start : end+1]
+	if closing := strings.Index(sqlText[end+1:], tag); closing >= 0 {
+		return end + 1 + closing + len(tag)
+	}
+	return len(sqlText)
+}
+
+// isDelimiterCommand reports whether a mysql client DELIMITER command starts
+// at offset i, which must begin a line
+func isDelimiterCommand(sqlText string, i int) bool {
+	if i > 0 && sqlText[i-1] != '\n' {
+		return false
+	}
+	fields := strings.Fields(strings.SplitN(sqlText[i:], "\n", 2)[0])
+	return len(fields) > 0 && strings.EqualFold(fields[0], "DELIMITER")
+}
+
+// hasOpenTriggerBody reports whether tokens are a CREATE TRIGGER statement
+// whose BEGIN ... END body has not been closed yet
+func hasOpenTriggerBody(tokens []sqlToken) bool {
+	if len(tokens) == 0 || !tokens[0].isKeyword("CREATE") ||
+		!slices.ContainsFunc(tokens, func(token sqlToken) bool { return token.isKeyword("TRIGGER") }) {
+		return false
+	}
+
+	depth := 0
+	for i, token := range tokens {
+		switch {
+		case token.isKeyword("BEGIN"), token.isKeyword("CASE"):
+			depth++
+		case token.isKeyword("END"):
+			// END IF, END LOOP, and similar close blocks that were not counted
+			if i+1 < len(tokens) && (tokens[i+1].isKeyword("IF") || tokens[i+1].isKeyword("LOOP") ||
+				tokens[i+1].isKeyword("WHILE") || tokens[i+1].isKeyword("REPEAT")) {
+				continue
+			}
+			depth--
+		}
+	}
+	return depth > 0
+}
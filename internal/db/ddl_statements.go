@@ -0,0 +1,1586 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// ddlCursor walks the tokens of one statement
+type ddlCursor struct {
+	text   string
+	tokens []sqlToken
+	pos    int
+}
+
+func (c *ddlCursor) done() bool {
+	return c.pos >= len(c.tokens)
+}
+
+func (c *ddlCursor) peek() sqlToken {
+	if c.done() {
+		return sqlToken{}
+	}
+	return c.tokens[c.pos]
+}
+
+// peekKeyword reports whether the next tokens are the given keywords
+func (c *ddlCursor) peekKeyword(keywords ...string) bool {
+	if c.pos+len(keywords) > len(c.tokens) {
+		return false
+	}
+	for i, keyword := range keywords {
+		if !c.tokens[c.pos+i].isKeyword(keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptKeyword consumes the given keywords if they are next
+func (c *ddlCursor) acceptKeyword(keywords ...string) bool {
+	if !c.peekKeyword(keywords...) {
+		return false
+	}
+	c.pos += len(keywords)
+	return true
+}
+
+// acceptAnyKeyword consumes and returns the next token if it is one of the keywords
+func (c *ddlCursor) acceptAnyKeyword(keywords ...string) (string, bool) {
+	for _, keyword := range keywords {
+		if c.acceptKeyword(keyword) {
+			return keyword, true
+		}
+	}
+	return "", false
+}
+
+func (c *ddlCursor) acceptPunctuation(punctuation string) bool {
+	if !c.peek().isPunctuation(punctuation) {
+		return false
+	}
+	c.pos++
+	return true
+}
+
+// group consumes a parenthesized group and returns the tokens inside it
+func (c *ddlCursor) group() ([]sqlToken, error) {
+	if !c.peek().isPunctuation("(") {
+		return nil, c.unexpected("(")
+	}
+	end := skipParenthesized(c.tokens, c.pos)
+	inner := c.tokens[c.pos+1 : max(end-1, c.pos+1)]
+	c.pos = end
+	return inner, nil
+}
+
+// skip consumes one token, or a whole parenthesized group
+func (c *ddlCursor) skip() {
+	if c.peek().isPunctuation("(") {
+		c.pos = skipParenthesized(c.tokens, c.pos)
+		return
+	}
+	c.pos++
+}
+
+// sourceText returns the statement text spanned by tokens
+func (c *ddlCursor) sourceText(tokens []sqlToken) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(c.text[tokens[0].start:tokens[len(tokens)-1].end])
+}
+
+func (c *ddlCursor) unexpected(expected string) error {
+	if c.done() {
+		return fmt.Errorf("expected %s at end of statement", expected)
+	}
+	return fmt.Errorf("expected %s, found %q", expected, c.peek().text)
+}
+
+// name consumes an identifier, folded as the dialect folds unquoted names
+func (m *DDLModel) name(c *ddlCursor) (string, error) {
+	token := c.peek()
+	if !token.isIdentifier() {
+		return "", c.unexpected("a name")
+	}
+	c.pos++
+	return m.tokenName(token), nil
+}
+
+func (m *DDLModel) tokenName(token sqlToken) string {
+	if token.kind == sqlWord {
+		return m.foldName(token.text)
+	}
+	return token.identifier()
+}
+
+// qualifiedName consumes a name that may be qualified by its schema, which
+// defaults to the current schema
+func (m *DDLModel) qualifiedName(c *ddlCursor) (string, string, error) {
+	name, err := m.name(c)
+	if err != nil {
+		return "", "", err
+	}
+	if !c.acceptPunctuation(".") {
+		return m.currentSchema, name, nil
+	}
+	qualifiedName, err := m.name(c)
+	if err != nil {
+		return "", "", err
+	}
+	return name, qualifiedName, nil
+}
+
+// nameList returns the names in a comma-separated list of tokens, such as a
+// column list without its parentheses
+func (m *DDLModel) nameList(tokens []sqlToken) ([]string, error) {
+	var names []string
+	for _, item := range splitSQLList(tokens) {
+		if len(item) == 0 || !item[0].isIdentifier() {
+			return nil, fmt.Errorf("invalid name list %q", joinTokenText(tokens))
+		}
+		names = append(names, m.tokenName(item[0]))
+	}
+	return names, nil
+}
+
+// stringValue returns the contents of a string literal
+func (m *DDLModel) stringValue(token sqlToken) (string, error) {
+	if token.kind != sqlString || len(token.text) < 2 {
+		return "", fmt.Errorf("expected a string, found %q", token.text)
+	}
+	value := strings.ReplaceAll(token.text[1:len(token.text)-1], "''", "'")
+	if m.dialect == DDLDialectMySQL {
+		value = strings.NewReplacer(`\'`, `'`, `\"`, `"`, `\n`, "\n", `\t`, "\t", `\\`, `\`).Replace(value)
+	}
+	return value, nil
+}
+
+func joinTokenText(tokens []sqlToken) string {
+	texts := make([]string, len(tokens))
+	for i, token := range tokens {
+		texts[i] = token.text
+	}
+	return strings.Join(texts, " ")
+}
+
+// applyStatement applies one statement; unsupported statements are ignored
+func (m *DDLModel) applyStatement(c *ddlCursor) error {
+	switch {
+	case c.acceptKeyword("CREATE"):
+		return m.applyCreate(c)
+	case c.acceptKeyword("ALTER", "TABLE"):
+		return m.alterTable(c)
+	case c.acceptKeyword("ALTER", "TYPE"):
+		return m.alterType(c)
+	case c.acceptKeyword("DROP"):
+		return m.applyDrop(c)
+	case c.acceptKeyword("COMMENT", "ON"):
+		return m.commentOn(c)
+	case m.dialect == DDLDialectMySQL && c.acceptKeyword("USE"):
+		name, err := m.name(c)
+		if err != nil {
+			return err
+		}
+		m.currentSchema = name
+	case m.dialect == DDLDialectPostgres && c.acceptKeyword("SET"):
+		c.acceptAnyKeyword("SESSION", "LOCAL")
+		if c.acceptKeyword("SEARCH_PATH") && (c.acceptKeyword("TO") || c.acceptPunctuation("=")) {
+			if name, err := m.name(c); err == nil && !strings.HasPrefix(name, "$") {
+				m.currentSchema = name
+			}
+		}
+	}
+	return nil
+}
+
+func (m *DDLModel) applyCreate(c *ddlCursor) error {
+	c.acceptKeyword("OR", "REPLACE")
+	if _, ok := c.acceptAnyKeyword("TEMP", "TEMPORARY"); ok {
+		return nil // Temporary objects are not part of the schema
+	}
+	c.acceptAnyKeyword("UNLOGGED", "GLOBAL", "LOCAL")
+
+	switch {
+	case c.acceptKeyword("TABLE"):
+		return m.createTable(c)
+	case c.acceptKeyword("UNIQUE", "INDEX"):
+		return m.createIndex(c, true)
+	case c.acceptKeyword("INDEX"):
+		return m.createIndex(c, false)
+	case m.dialect == DDLDialectMySQL && (c.acceptKeyword("FULLTEXT", "INDEX") || c.acceptKeyword("SPATIAL", "INDEX")):
+		return m.createIndex(c, false)
+	case m.dialect == DDLDialectPostgres && c.acceptKeyword("TYPE"):
+		return m.createType(c)
+	}
+
+	// CREATE [MATERIALIZED | RECURSIVE] VIEW, with MySQL view options such
+	// as ALGORITHM = MERGE and DEFINER = user before VIEW
+	for i := c.pos; i < len(c.tokens) && !c.tokens[i].isKeyword("AS") && !c.tokens[i].isPunctuation("("); i++ {
+		if c.tokens[i].isKeyword("VIEW") {
+			materialized := slices.ContainsFunc(c.tokens[c.pos:i], func(token sqlToken) bool { return token.isKeyword("MATERIALIZED") })
+			c.pos = i + 1
+			return m.createView(c, materialized)
+		}
+	}
+	return nil
+}
+
+func (m *DDLModel) createTable(c *ddlCursor) error {
+	ifNotExists := c.acceptKeyword("IF", "NOT", "EXISTS")
+	schemaName, name, err := m.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+	if !c.peek().isPunctuation("(") {
+		// CREATE TABLE ... AS SELECT and PARTITION OF do not declare their columns
+		return nil
+	}
+	if m.findTable(schemaName, name) != nil || m.findView(schemaName, name) != nil {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("relation %s already exists", schema.QualifiedName(schemaName, name))
+	}
+
+	t := &ddlTable{schemaName: schemaName, table: schema.Table{Name: name}, createSQL: c.text}
+	definitions, err := c.group()
+	if err != nil {
+		return err
+	}
+	// Columns come first so that table constraints can refer to any of them
+	var constraints [][]sqlToken
+	for _, definition := range splitSQLList(definitions) {
+		switch {
+		case len(definition) == 0:
+			return fmt.Errorf("empty definition in table %s", name)
+		case isDDLTableConstraint(definition[0]):
+			constraints = append(constraints, definition)
+		case definition[0].isKeyword("LIKE"):
+			// LIKE copies another table's columns, which are not tracked here
+		default:
+			if err := m.addColumn(t, &ddlCursor{text: c.text, tokens: definition}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, definition := range constraints {
+		if err := m.addTableConstraint(t, &ddlCursor{text: c.text, tokens: definition}); err != nil {
+			return err
+		}
+	}
+
+	// MySQL table options follow the definitions
+	for !c.done() {
+		if c.acceptKeyword("COMMENT") {
+			c.acceptPunctuation("=")
+			if comment, err := m.stringValue(c.peek()); err == nil {
+				t.table.Comment = comment
+			}
+		}
+		c.skip()
+	}
+
+	m.tables = append(m.tables, t)
+	m.addForeignKeyIndexes(t)
+	return nil
+}
+
+// isDDLTableConstraint reports whether a table definition item starting
+// with token is a table constraint or index rather than a column definition
+func isDDLTableConstraint(token sqlToken) bool {
+	for _, keyword := range []string{"CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "EXCLUDE"} {
+		if token.isKeyword(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// ddlColumnConstraintKeywords end the type of a column definition
+var ddlColumnConstraintKeywords = []string{
+	"CONSTRAINT", "NOT", "NULL", "PRIMARY", "UNIQUE", "CHECK", "DEFAULT", "REFERENCES",
+	"GENERATED", "AS", "COLLATE", "AUTO_INCREMENT", "AUTOINCREMENT", "COMMENT", "ON",
+	"CHARSET", "VISIBLE", "INVISIBLE", "STORAGE", "COLUMN_FORMAT", "SRID", "KEY",
+}
+
+func isDDLColumnConstraint(tokens []sqlToken, i int) bool {
+	if tokens[i].isKeyword("CHARACTER") {
+		return i+1 < len(tokens) && tokens[i+1].isKeyword("SET")
+	}
+	return slices.ContainsFunc(ddlColumnConstraintKeywords, tokens[i].isKeyword)
+}
+
+// addColumn adds a column definition and its column constraints to a table
+func (m *DDLModel) addColumn(t *ddlTable, c *ddlCursor) error {
+	column, err := m.parseColumn(t, c)
+	if err != nil {
+		return err
+	}
+	if findColumn(t.table.Columns, column.Name) != nil {
+		return fmt.Errorf("column %s of table %s already exists", column.Name, t.table.Name)
+	}
+	t.table.Columns = append(t.table.Columns, column)
+	return nil
+}
+
+// parseColumn parses a column definition. Constraints other than NOT NULL,
+// DEFAULT, and the column's own attributes are added to the table.
+func (m *DDLModel) parseColumn(t *ddlTable, c *ddlCursor) (schema.Column, error) {
+	name, err := m.name(c)
+	if err != nil {
+		return schema.Column{}, err
+	}
+	column := schema.Column{Name: name, Nullable: true}
+
+	typeStart := c.pos
+	for !c.done() && !isDDLColumnConstraint(c.tokens, c.pos) {
+		c.skip()
+	}
+	m.setColumnType(&column, c.tokens[typeStart:c.pos], c)
+
+	constraintName := ""
+	for !c.done() {
+		switch {
+		case c.acceptKeyword("CONSTRAINT"):
+			if constraintName, err = m.name(c); err != nil {
+				return schema.Column{}, err
+			}
+			continue
+		case c.acceptKeyword("NOT", "NULL"):
+			column.Nullable = false
+		case c.acceptKeyword("NULL"):
+			column.Nullable = true
+		case c.acceptKeyword("PRIMARY", "KEY"):
+			c.acceptAnyKeyword("ASC", "DESC")
+			if len(t.table.PrimaryKey) > 0 {
+				return schema.Column{}, fmt.Errorf("multiple primary keys for table %s are not allowed", t.table.Name)
+			}
+			t.table.PrimaryKey = []string{column.Name}
+			t.primaryKeyName = constraintName
+			if m.dialect != DDLDialectSQLite {
+				column.Nullable = false
+			}
+		case c.acceptKeyword("UNIQUE"):
+			c.acceptKeyword("KEY")
+			m.addUniqueIndex(t, constraintName, []string{column.Name})
+		case c.acceptKeyword("CHECK"):
+			group, err := c.group()
+			if err != nil {
+				return schema.Column{}, err
+			}
+			m.addCheck(t, constraintName, c.sourceText(group), []string{column.Name})
+		case c.acceptKeyword("DEFAULT"):
+			start := c.pos
+			c.skip()
+			for !c.done() && !isDDLColumnConstraint(c.tokens, c.pos) {
+				c.skip()
+			}
+			value := m.defaultValue(c, c.tokens[start:c.pos])
+			column.DefaultValue = &value
+			if m.dialect == DDLDialectPostgres && strings.HasPrefix(strings.ToLower(value), "nextval(") {
+				// Sequence defaults make serial-like columns, as in the catalog
+				column.Identity = schema.IdentityByDefault
+				column.DefaultValue = nil
+			}
+		case c.acceptKeyword("REFERENCES"):
+			relation, err := m.parseReferences(c, constraintName, t.table.Name, []string{column.Name})
+			if err != nil {
+				return schema.Column{}, err
+			}
+			t.table.Relations = append(t.table.Relations, relation)
+		case c.acceptKeyword("GENERATED"):
+			if err := m.parseGenerated(c, &column); err != nil {
+				return schema.Column{}, err
+			}
+		case c.acceptKeyword("AS"):
+			if err := m.parseGeneratedExpression(c, &column); err != nil {
+				return schema.Column{}, err
+			}
+		case c.acceptKeyword("AUTO_INCREMENT"), c.acceptKeyword("AUTOINCREMENT"):
+			// SQLite AUTOINCREMENT only applies to rowid aliases, which are
+			// detected from the whole table definition
+			if m.dialect == DDLDialectMySQL {
+				column.Identity = schema.IdentityByDefault
+			}
+		case c.acceptKeyword("COMMENT"):
+			comment, err := m.stringValue(c.peek())
+			if err != nil {
+				return schema.Column{}, err
+			}
+			column.Comment = comment
+			c.pos++
+		case c.acceptKeyword("ON", "UPDATE"):
+			// MySQL ON UPDATE CURRENT_TIMESTAMP
+			c.skip()
+			c.acceptPunctuation("(")
+			c.acceptPunctuation(")")
+		case c.acceptKeyword("COLLATE"), c.acceptKeyword("CHARSET"), c.acceptKeyword("CHARACTER", "SET"),
+			c.acceptKeyword("STORAGE"), c.acceptKeyword("COLUMN_FORMAT"), c.acceptKeyword("SRID"):
+			c.skip()
+		default:
+			c.skip()
+		}
+		constraintName = ""
+	}
+	return column, nil
+}
+
+// setColumnType sets the type of a column from its declaration, normalized
+// the way the dialect's catalog reports it
+func (m *DDLModel) setColumnType(column *schema.Column, tokens []sqlToken, c *ddlCursor) {
+	if m.dialect == DDLDialectSQLite {
+		column.Type = c.sourceText(tokens)
+		return
+	}
+
+	var words, trailing []string
+	args := ""
+	array := ""
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token.isPunctuation("("):
+			end := skipParenthesized(tokens, i)
+			args = "(" + strings.Join(ddlTypeArguments(tokens[i+1:max(end-1, i+1)]), ",") + ")"
+			i = end - 1
+		case token.isPunctuation("["):
+			array += "[]"
+			for i < len(tokens) && !tokens[i].isPunctuation("]") {
+				i++
+			}
+		case token.isPunctuation("."):
+			words[len(words)-1] += "."
+		case args != "" && m.dialect == DDLDialectMySQL:
+			trailing = append(trailing, strings.ToLower(token.text))
+		case len(words) > 0 && strings.HasSuffix(words[len(words)-1], "."):
+			words[len(words)-1] += m.tokenName(token)
+		case token.kind == sqlQuotedIdentifier:
+			words = append(words, token.identifier())
+		default:
+			words = append(words, strings.ToLower(token.text))
+		}
+	}
+
+	base := strings.Join(words, " ")
+	if m.dialect == DDLDialectPostgres {
+		base = m.postgresType(column, base)
+		// The catalog reports lengths of character types only
+		if (base != varcharType && base != "char") || array != "" {
+			args = ""
+		}
+	} else {
+		base = mysqlType(column, base, tokens)
+		if base == "tinyint" && args == "" && len(words) == 1 && (words[0] == "bool" || words[0] == "boolean") {
+			args = "(1)"
+		}
+	}
+	column.Type = base + args + array
+	if len(trailing) > 0 {
+		column.Type += " " + strings.Join(trailing, " ")
+	}
+}
+
+// ddlTypeArguments returns the arguments of a type such as numeric(10, 2)
+func ddlTypeArguments(tokens []sqlToken) []string {
+	var arguments []string
+	for _, item := range splitSQLList(tokens) {
+		arguments = append(arguments, joinTokenText(item))
+	}
+	return arguments
+}
+
+// postgresType maps a PostgreSQL type to the name the catalog reports,
+// marking serial columns as identities
+func (m *DDLModel) postgresType(column *schema.Column, base string) string {
+	switch base {
+	case "serial", "serial4", "bigserial", "serial8", "smallserial", "serial2":
+		column.Identity = schema.IdentityByDefault
+		column.Nullable = false
+	}
+	switch base {
+	case "int", "int4", "integer", "serial", "serial4":
+		return "integer"
+	case "int8", "bigint", "bigserial", "serial8":
+		return "bigint"
+	case "int2", "smallint", "smallserial", "serial2":
+		return "smallint"
+	case "float4", "real":
+		return "real"
+	case "float", "float8", "double precision":
+		return "double precision"
+	case "bool", "boolean":
+		return "boolean"
+	case "decimal", "numeric":
+		return "numeric"
+	case "character varying", varcharType:
+		return varcharType
+	case "character", "char", "bpchar":
+		return "char"
+	case "timestamp with time zone", "timestamptz":
+		return "timestamptz"
+	case "timestamp without time zone", "timestamp":
+		return "timestamp"
+	case "time with time zone", "timetz":
+		return "timetz"
+	case "time without time zone", "time":
+		return "time"
+	default:
+		return base
+	}
+}
+
+// mysqlType maps a MySQL type to the name the catalog reports and records
+// the values of ENUM columns
+func mysqlType(column *schema.Column, base string, tokens []sqlToken) string {
+	switch base {
+	case "integer":
+		return "int"
+	case "bool", "boolean":
+		return "tinyint"
+	case "dec", "numeric", "fixed":
+		return "decimal"
+	case "double precision", "real":
+		return "double"
+	case "character varying":
+		return varcharType
+	case "character":
+		return "char"
+	case "enum":
+		for _, token := range tokens {
+			if token.kind == sqlString {
+				column.EnumValues = append(column.EnumValues, strings.ReplaceAll(token.text[1:len(token.text)-1], "''", "'"))
+			}
+		}
+	}
+	return base
+}
+
+// defaultValue returns a DEFAULT expression as the dialect's catalog reports
+// it. MySQL reports string defaults without their quotes.
+func (m *DDLModel) defaultValue(c *ddlCursor, tokens []sqlToken) string {
+	if m.dialect == DDLDialectMySQL && len(tokens) == 1 && tokens[0].kind == sqlString {
+		value, _ := m.stringValue(tokens[0])
+		return value
+	}
+	return c.sourceText(tokens)
+}
+
+// expression returns the text of an expression in parentheses, unwrapped
+// like the dialect's catalog unless it is SQLite, which keeps the declared text
+func (m *DDLModel) expression(c *ddlCursor, tokens []sqlToken) string {
+	if m.dialect == DDLDialectSQLite {
+		return c.sourceText(tokens)
+	}
+	return unwrapSQLExpression(c.sourceText(tokens))
+}
+
+// parseGenerated parses the rest of GENERATED ALWAYS AS (expression) and
+// GENERATED { ALWAYS | BY DEFAULT } AS IDENTITY
+func (m *DDLModel) parseGenerated(c *ddlCursor, column *schema.Column) error {
+	identity := schema.IdentityAlways
+	if c.acceptKeyword("BY", "DEFAULT") {
+		identity = schema.IdentityByDefault
+	} else if !c.acceptKeyword("ALWAYS") {
+		return c.unexpected("ALWAYS or BY DEFAULT")
+	}
+	if !c.acceptKeyword("AS") {
+		return c.unexpected("AS")
+	}
+	if c.acceptKeyword("IDENTITY") {
+		column.Identity = identity
+		column.Nullable = false
+		if c.peek().isPunctuation("(") {
+			c.skip() // sequence options
+		}
+		return nil
+	}
+	return m.parseGeneratedExpression(c, column)
+}
+
+// parseGeneratedExpression parses the rest of AS (expression) [STORED | VIRTUAL]
+func (m *DDLModel) parseGeneratedExpression(c *ddlCursor, column *schema.Column) error {
+	group, err := c.group()
+	if err != nil {
+		return err
+	}
+	// PostgreSQL only supported stored generated columns before version 18
+	stored := m.dialect == DDLDialectPostgres
+	if _, ok := c.acceptAnyKeyword("STORED", "PERSISTENT"); ok {
+		stored = true
+	} else if c.acceptKeyword("VIRTUAL") {
+		stored = false
+	}
+	column.Generated = &schema.GeneratedColumn{Expression: m.expression(c, group), Stored: stored}
+	column.DefaultValue = nil
+	return nil
+}
+
+// parseReferences parses the rest of REFERENCES table [(columns)] and its
+// referential actions. Omitted target columns refer to the target's primary key.
+func (m *DDLModel) parseReferences(c *ddlCursor, name, tableName string, sourceColumns []string) (schema.Relation, error) {
+	targetSchema, targetTable, err := m.qualifiedName(c)
+	if err != nil {
+		return schema.Relation{}, err
+	}
+	relation := schema.Relation{
+		Name:          name,
+		TargetSchema:  targetSchema,
+		TargetTable:   targetTable,
+		SourceColumns: sourceColumns,
+		OnUpdate:      "NO ACTION",
+		OnDelete:      "NO ACTION",
+	}
+	if c.peek().isPunctuation("(") {
+		group, _ := c.group()
+		if relation.TargetColumns, err = m.nameList(group); err != nil {
+			return schema.Relation{}, err
+		}
+	}
+
+	for !c.done() {
+		switch {
+		case c.acceptKeyword("ON", "DELETE"):
+			relation.OnDelete = referentialAction(c)
+		case c.acceptKeyword("ON", "UPDATE"):
+			relation.OnUpdate = referentialAction(c)
+		case c.acceptKeyword("MATCH"), c.acceptKeyword("INITIALLY"):
+			c.skip()
+		case c.acceptKeyword("DEFERRABLE"), c.acceptKeyword("NOT", "DEFERRABLE"):
+		default:
+			return m.withDefaultRelationName(relation, tableName), nil
+		}
+	}
+	return m.withDefaultRelationName(relation, tableName), nil
+}
+
+// withDefaultRelationName names an unnamed PostgreSQL foreign key the way
+// PostgreSQL does. MySQL names are assigned when the table is stored.
+func (m *DDLModel) withDefaultRelationName(relation schema.Relation, tableName string) schema.Relation {
+	if relation.Name == "" && m.dialect == DDLDialectPostgres {
+		relation.Name = tableName + "_" + strings.Join(relation.SourceColumns, "_") + "_fkey"
+	}
+	return relation
+}
+
+// referentialAction consumes a referential action such as SET NULL
+func referentialAction(c *ddlCursor) string {
+	for _, action := range [][]string{{"CASCADE"}, {"RESTRICT"}, {"SET", "NULL"}, {"SET", "DEFAULT"}, {"NO", "ACTION"}} {
+		if c.acceptKeyword(action...) {
+			return strings.Join(action, " ")
+		}
+	}
+	c.skip()
+	return ""
+}
+
+// addTableConstraint adds a table constraint or MySQL index definition
+func (m *DDLModel) addTableConstraint(t *ddlTable, c *ddlCursor) error {
+	name := ""
+	if c.acceptKeyword("CONSTRAINT") {
+		// MySQL allows CONSTRAINT without a name
+		if !c.peekKeyword("PRIMARY") && !c.peekKeyword("UNIQUE") && !c.peekKeyword("CHECK") && !c.peekKeyword("FOREIGN") {
+			var err error
+			if name, err = m.name(c); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch {
+	case c.acceptKeyword("PRIMARY", "KEY"):
+		columns, _, err := m.indexColumns(c)
+		if err != nil {
+			return err
+		}
+		if len(t.table.PrimaryKey) > 0 {
+			return fmt.Errorf("multiple primary keys for table %s are not allowed", t.table.Name)
+		}
+		t.table.PrimaryKey = columns
+		t.primaryKeyName = name
+		if m.dialect != DDLDialectSQLite {
+			for _, column := range columns {
+				if col := findColumn(t.table.Columns, column); col != nil {
+					col.Nullable = false
+				}
+			}
+		}
+	case c.acceptKeyword("UNIQUE"):
+		c.acceptAnyKeyword("KEY", "INDEX")
+		if indexName, ok := m.optionalIndexName(c); ok {
+			name = indexName
+		}
+		columns, hasExpressions, err := m.indexColumns(c)
+		if err != nil {
+			return err
+		}
+		if hasExpressions {
+			t.table.Indexes = append(t.table.Indexes, schema.Index{Name: name, Columns: columns, IsUnique: true, HasExpressions: true})
+		} else {
+			m.addUniqueIndex(t, name, columns)
+		}
+	case c.acceptKeyword("CHECK"):
+		group, err := c.group()
+		if err != nil {
+			return err
+		}
+		m.addCheck(t, name, c.sourceText(group), nil)
+	case c.acceptKeyword("FOREIGN", "KEY"):
+		if indexName, ok := m.optionalIndexName(c); ok && name == "" {
+			name = indexName
+		}
+		group, err := c.group()
+		if err != nil {
+			return err
+		}
+		sourceColumns, err := m.nameList(group)
+		if err != nil {
+			return err
+		}
+		if !c.acceptKeyword("REFERENCES") {
+			return c.unexpected("REFERENCES")
+		}
+		relation, err := m.parseReferences(c, name, t.table.Name, sourceColumns)
+		if err != nil {
+			return err
+		}
+		t.table.Relations = append(t.table.Relations, relation)
+	case m.dialect == DDLDialectMySQL && (c.acceptKeyword("KEY") || c.acceptKeyword("INDEX") ||
+		c.acceptKeyword("FULLTEXT") || c.acceptKeyword("SPATIAL")):
+		c.acceptAnyKeyword("KEY", "INDEX")
+		indexName, _ := m.optionalIndexName(c)
+		columns, hasExpressions, err := m.indexColumns(c)
+		if err != nil {
+			return err
+		}
+		if indexName == "" && len(columns) > 0 {
+			indexName = m.uniqueIndexName(t, columns[0])
+		}
+		t.table.Indexes = append(t.table.Indexes, schema.Index{Name: indexName, Columns: columns, HasExpressions: hasExpressions})
+	}
+	// EXCLUDE constraints are not part of the schema model
+	return nil
+}
+
+// optionalIndexName consumes the optional name in MySQL UNIQUE [KEY] [name] (...)
+func (m *DDLModel) optionalIndexName(c *ddlCursor) (string, bool) {
+	if !c.peek().isIdentifier() || c.peekKeyword("USING") || c.peekKeyword("NULLS") {
+		return "", false
+	}
+	name, err := m.name(c)
+	return name, err == nil
+}
+
+// indexColumns consumes the parenthesized key parts of a key or index, with
+// any index method before it and options after it. Key parts that are
+// expressions are not returned, but reported with hasExpressions.
+func (m *DDLModel) indexColumns(c *ddlCursor) ([]string, bool, error) {
+	for !c.done() && !c.peek().isPunctuation("(") {
+		c.skip() // USING BTREE, NULLS NOT DISTINCT
+	}
+	group, err := c.group()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var columns []string
+	hasExpressions := false
+	for _, part := range splitSQLList(group) {
+		switch {
+		case len(part) == 0:
+			return nil, false, fmt.Errorf("empty key part in %q", joinTokenText(group))
+		case !part[0].isIdentifier():
+			hasExpressions = true
+		case len(part) > 1 && part[1].isPunctuation("(") && m.dialect != DDLDialectMySQL:
+			// A function call; MySQL uses name(length) for prefix key parts
+			hasExpressions = true
+		default:
+			columns = append(columns, m.tokenName(part[0]))
+		}
+	}
+	// Trailing options such as USING, INCLUDE, or COMMENT do not change the key
+	for !c.done() {
+		c.skip()
+	}
+	return columns, hasExpressions, nil
+}
+
+// addUniqueIndex adds the index that backs a UNIQUE constraint
+func (m *DDLModel) addUniqueIndex(t *ddlTable, name string, columns []string) {
+	if name == "" {
+		switch m.dialect {
+		case DDLDialectPostgres:
+			name = t.table.Name + "_" + strings.Join(columns, "_") + "_key"
+		case DDLDialectMySQL:
+			name = m.uniqueIndexName(t, columns[0])
+		case DDLDialectSQLite:
+			count := 1
+			for _, index := range t.table.Indexes {
+				if strings.HasPrefix(index.Name, "sqlite_autoindex_") {
+					count++
+				}
+			}
+			name = fmt.Sprintf("sqlite_autoindex_%s_%d", t.table.Name, count)
+		}
+	}
+	t.table.Indexes = append(t.table.Indexes, schema.Index{Name: name, Columns: columns, IsUnique: true})
+}
+
+// uniqueIndexName returns base, or base_2, base_3, ... when the table already
+// has an index named base, as MySQL names unnamed indexes
+func (m *DDLModel) uniqueIndexName(t *ddlTable, base string) string {
+	name := base
+	for suffix := 2; slices.ContainsFunc(t.table.Indexes, func(index schema.Index) bool { return strings.EqualFold(index.Name, name) }); suffix++ {
+		name = fmt.Sprintf("%s_%d", base, suffix)
+	}
+	return name
+}
+
+// addCheck adds a CHECK constraint. Column constraints list their column;
+// the columns of table constraints are found when the table is assembled.
+func (m *DDLModel) addCheck(t *ddlTable, name, expression string, columns []string) {
+	if name == "" {
+		switch m.dialect {
+		case DDLDialectPostgres:
+			name = t.table.Name + "_check"
+			if len(columns) == 1 {
+				name = t.table.Name + "_" + columns[0] + "_check"
+			}
+		case DDLDialectMySQL:
+			name = fmt.Sprintf("%s_chk_%d", t.table.Name, len(t.table.Checks)+1)
+		}
+	}
+	t.table.Checks = append(t.table.Checks, schema.CheckConstraint{
+		Name:       name,
+		Expression: m.expression(&ddlCursor{text: expression, tokens: m.tokenize(expression)}, m.tokenize(expression)),
+		Columns:    columns,
+	})
+}
+
+// addForeignKeyIndexes names unnamed MySQL foreign keys and adds the index
+// that MySQL creates for foreign key columns without one
+func (m *DDLModel) addForeignKeyIndexes(t *ddlTable) {
+	if m.dialect != DDLDialectMySQL {
+		return
+	}
+	for i := range t.table.Relations {
+		relation := &t.table.Relations[i]
+		explicitName := relation.Name != ""
+		if !explicitName {
+			relation.Name = m.nextForeignKeyName(t)
+		}
+
+		indexed := hasPrefix(t.table.PrimaryKey, relation.SourceColumns) ||
+			slices.ContainsFunc(t.table.Indexes, func(index schema.Index) bool {
+				return hasPrefix(index.Columns, relation.SourceColumns)
+			})
+		if indexed {
+			continue
+		}
+		indexName := relation.SourceColumns[0]
+		if explicitName {
+			indexName = relation.Name
+		}
+		t.table.Indexes = append(t.table.Indexes, schema.Index{Name: m.uniqueIndexName(t, indexName), Columns: slices.Clone(relation.SourceColumns)})
+	}
+}
+
+func (m *DDLModel) nextForeignKeyName(t *ddlTable) string {
+	for number := 1; ; number++ {
+		name := fmt.Sprintf("%s_ibfk_%d", t.table.Name, number)
+		if !slices.ContainsFunc(t.table.Relations, func(relation schema.Relation) bool { return relation.Name == name }) {
+			return name
+		}
+	}
+}
+
+// hasPrefix reports whether columns starts with prefix
+func hasPrefix(columns, prefix []string) bool {
+	return len(prefix) > 0 && len(columns) >= len(prefix) && slices.Equal(columns[:len(prefix)], prefix)
+}
+
+// createIndex parses the rest of CREATE [UNIQUE] INDEX
+func (m *DDLModel) createIndex(c *ddlCursor, unique bool) error {
+	c.acceptKeyword("CONCURRENTLY")
+	ifNotExists := c.acceptKeyword("IF", "NOT", "EXISTS")
+
+	name := ""
+	if !c.peekKeyword("ON") {
+		var err error
+		if _, name, err = m.qualifiedName(c); err != nil {
+			return err
+		}
+	}
+	if c.acceptKeyword("USING") {
+		c.skip()
+	}
+	if !c.acceptKeyword("ON") {
+		return c.unexpected("ON")
+	}
+	c.acceptKeyword("ONLY")
+	schemaName, tableName, err := m.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+	t := m.findTable(schemaName, tableName)
+	if t == nil {
+		return fmt.Errorf("table %s does not exist", schema.QualifiedName(schemaName, tableName))
+	}
+	if name != "" && slices.ContainsFunc(t.table.Indexes, func(index schema.Index) bool { return strings.EqualFold(index.Name, name) }) {
+		if ifNotExists {
+			return nil
+		}
+		return fmt.Errorf("index %s already exists", name)
+	}
+
+	for !c.done() && !c.peek().isPunctuation("(") {
+		c.skip() // USING method
+	}
+	if c.done() {
+		return c.unexpected("(")
+	}
+	end := skipParenthesized(c.tokens, c.pos)
+	columns, hasExpressions, err := m.indexColumns(&ddlCursor{text: c.text, tokens: c.tokens[c.pos:end]})
+	if err != nil {
+		return err
+	}
+	c.pos = end
+	partial := slices.ContainsFunc(c.tokens[c.pos:], func(token sqlToken) bool { return token.isKeyword("WHERE") })
+
+	if name == "" {
+		name = tableName + "_" + strings.Join(columns, "_") + "_idx"
+		if len(columns) == 0 {
+			name = tableName + "_expr_idx"
+		}
+	}
+	t.table.Indexes = append(t.table.Indexes, schema.Index{
+		Name:           name,
+		Columns:        columns,
+		IsUnique:       unique,
+		IsPartial:      partial,
+		HasExpressions: hasExpressions,
+	})
+	return nil
+}
+
+// createType parses the rest of CREATE TYPE name AS ENUM (...). Other
+// kinds of types are ignored.
+func (m *DDLModel) createType(c *ddlCursor) error {
+	schemaName, name, err := m.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+	if !c.acceptKeyword("AS", "ENUM") {
+		return nil
+	}
+	group, err := c.group()
+	if err != nil {
+		return err
+	}
+	if m.findEnum(schemaName, name) != nil {
+		return fmt.Errorf("type %s already exists", schema.QualifiedName(schemaName, name))
+	}
+
+	enum := &ddlEnum{schemaName: schemaName, name: name}
+	for _, item := range splitSQLList(group) {
+		if len(item) == 0 {
+			continue
+		}
+		value, err := m.stringValue(item[0])
+		if err != nil {
+			return err
+		}
+		enum.values = append(enum.values, value)
+	}
+	m.enums = append(m.enums, enum)
+	return nil
+}
+
+// alterType parses the rest of ALTER TYPE name ADD VALUE and RENAME VALUE
+func (m *DDLModel) alterType(c *ddlCursor) error {
+	schemaName, name, err := m.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+	enum := m.findEnum(schemaName, name)
+	if enum == nil {
+		return nil // not an enum defined in the DDL
+	}
+
+	switch {
+	case c.acceptKeyword("ADD", "VALUE"):
+		ifNotExists := c.acceptKeyword("IF", "NOT", "EXISTS")
+		value, err := m.stringValue(c.peek())
+		if err != nil {
+			return err
+		}
+		c.pos++
+		if slices.Contains(enum.values, value) {
+			if ifNotExists {
+				return nil
+			}
+			return fmt.Errorf("enum label %q already exists", value)
+		}
+		position := len(enum.values)
+		if placement, ok := c.acceptAnyKeyword("BEFORE", "AFTER"); ok {
+			neighbor, err := m.stringValue(c.peek())
+			if err != nil {
+				return err
+			}
+			if index := slices.Index(enum.values, neighbor); index >= 0 {
+				position = index
+				if placement == "AFTER" {
+					position++
+				}
+			}
+		}
+		enum.values = slices.Insert(enum.values, position, value)
+	case c.acceptKeyword("RENAME", "VALUE"):
+		oldValue, err := m.stringValue(c.peek())
+		if err != nil {
+			return err
+		}
+		c.pos++
+		if !c.acceptKeyword("TO") {
+			return c.unexpected("TO")
+		}
+		newValue, err := m.stringValue(c.peek())
+		if err != nil {
+			return err
+		}
+		if index := slices.Index(enum.values, oldValue); index >= 0 {
+			enum.values[index] = newValue
+		}
+	case c.acceptKeyword("RENAME", "TO"):
+		newName, err := m.name(c)
+		if err != nil {
+			return err
+		}
+		for _, table := range m.tables {
+			for i := range table.table.Columns {
+				if strings.EqualFold(table.table.Columns[i].Type, enum.name) {
+					table.table.Columns[i].Type = newName
+				}
+			}
+		}
+		enum.name = newName
+	}
+	return nil
+}
+
+// createView parses the rest of CREATE VIEW, from the view's name
+func (m *DDLModel) createView(c *ddlCursor, materialized bool) error {
+	ifNotExists := c.acceptKeyword("IF", "NOT", "EXISTS")
+	schemaName, name, err := m.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+
+	var columns []schema.Column
+	if c.peek().isPunctuation("(") {
+		group, _ := c.group()
+		names, err := m.nameList(group)
+		if err != nil {
+			return err
+		}
+		for _, columnName := range names {
+			columns = append(columns, schema.Column{Name: columnName, Nullable: true})
+		}
+	}
+	for !c.done() && !c.peekKeyword("AS") {
+		c.skip() // WITH (options)
+	}
+	if !c.acceptKeyword("AS") || c.done() {
+		return c.unexpected("AS")
+	}
+	definition := strings.TrimSpace(c.text[c.peek().start:])
+	if materialized {
+		for _, suffix := range []string{"WITH NO DATA", "WITH DATA"} {
+			if len(definition) >= len(suffix) && strings.EqualFold(definition[len(definition)-len(suffix):], suffix) {
+				definition = strings.TrimSpace(definition[:len(definition)-len(suffix)])
+			}
+		}
+	}
+
+	if existing := m.findView(schemaName, name); existing != nil {
+		if ifNotExists {
+			return nil
+		}
+		m.views = slices.DeleteFunc(m.views, func(view *ddlView) bool { return view == existing })
+	}
+	if m.findTable(schemaName, name) != nil {
+		return fmt.Errorf("relation %s already exists", schema.QualifiedName(schemaName, name))
+	}
+	m.views = append(m.views, &ddlView{
+		schemaName: schemaName,
+		view:       newView(viewMetadata{name: name, materialized: materialized, definition: definition}, columns, nil),
+	})
+	return nil
+}
+
+// applyDrop parses the rest of DROP TABLE, VIEW, INDEX, TYPE, and SCHEMA
+func (m *DDLModel) applyDrop(c *ddlCursor) error {
+	switch {
+	case c.acceptKeyword("TABLE"):
+		return m.dropNames(c, func(schemaName, name string) bool {
+			table := m.findTable(schemaName, name)
+			m.tables = slices.DeleteFunc(m.tables, func(t *ddlTable) bool { return t == table })
+			return table != nil
+		})
+	case c.acceptKeyword("VIEW"), c.acceptKeyword("MATERIALIZED", "VIEW"):
+		return m.dropNames(c, func(schemaName, name string) bool {
+			view := m.findView(schemaName, name)
+			m.views = slices.DeleteFunc(m.views, func(v *ddlView) bool { return v == view })
+			return view != nil
+		})
+	case c.acceptKeyword("TYPE"):
+		return m.dropNames(c, func(schemaName, name string) bool {
+			enum := m.findEnum(schemaName, name)
+			m.enums = slices.DeleteFunc(m.enums, func(e *ddlEnum) bool { return e == enum })
+			return enum != nil
+		})
+	case c.acceptKeyword("INDEX"):
+		c.acceptKeyword("CONCURRENTLY")
+		return m.dropNames(c, func(schemaName, name string) bool {
+			for _, table := range m.tables {
+				if m.dialect == DDLDialectPostgres && !strings.EqualFold(table.schemaName, schemaName) {
+					continue
+				}
+				before := len(table.table.Indexes)
+				table.table.Indexes = slices.DeleteFunc(table.table.Indexes, func(index schema.Index) bool {
+					return strings.EqualFold(index.Name, name)
+				})
+				if len(table.table.Indexes) < before {
+					return true
+				}
+			}
+			return false
+		})
+	case c.acceptKeyword("SCHEMA"), c.acceptKeyword("DATABASE"):
+		c.acceptKeyword("IF", "EXISTS")
+		for !c.done() {
+			name, err := m.name(c)
+			if err != nil {
+				return err
+			}
+			m.tables = slices.DeleteFunc(m.tables, func(t *ddlTable) bool { return strings.EqualFold(t.schemaName, name) })
+			m.views = slices.DeleteFunc(m.views, func(v *ddlView) bool { return strings.EqualFold(v.schemaName, name) })
+			m.enums = slices.DeleteFunc(m.enums, func(e *ddlEnum) bool { return strings.EqualFold(e.schemaName, name) })
+			if !c.acceptPunctuation(",") {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// dropNames drops each name in a DROP statement's name list with drop,
+// which reports whether the object existed
+func (m *DDLModel) dropNames(c *ddlCursor, drop func(schemaName, name string) bool) error {
+	ifExists := c.acceptKeyword("IF", "EXISTS")
+	for {
+		schemaName, name, err := m.qualifiedName(c)
+		if err != nil {
+			return err
+		}
+		if !drop(schemaName, name) && !ifExists {
+			return fmt.Errorf("%s does not exist", schema.QualifiedName(schemaName, name))
+		}
+		if !c.acceptPunctuation(",") {
+			return nil
+		}
+	}
+}
+
+// commentOn parses the rest of COMMENT ON TABLE, VIEW, and COLUMN
+func (m *DDLModel) commentOn(c *ddlCursor) error {
+	var setComment func(comment string) error
+	switch {
+	case c.acceptKeyword("TABLE"):
+		schemaName, name, err := m.qualifiedName(c)
+		if err != nil {
+			return err
+		}
+		setComment = func(comment string) error {
+			if table := m.findTable(schemaName, name); table != nil {
+				table.table.Comment = comment
+				return nil
+			}
+			return fmt.Errorf("table %s does not exist", schema.QualifiedName(schemaName, name))
+		}
+	case c.acceptKeyword("VIEW"), c.acceptKeyword("MATERIALIZED", "VIEW"):
+		schemaName, name, err := m.qualifiedName(c)
+		if err != nil {
+			return err
+		}
+		setComment = func(comment string) error {
+			if view := m.findView(schemaName, name); view != nil {
+				view.view.Comment = comment
+				return nil
+			}
+			return fmt.Errorf("view %s does not exist", schema.QualifiedName(schemaName, name))
+		}
+	case c.acceptKeyword("COLUMN"):
+		var parts []string
+		for {
+			part, err := m.name(c)
+			if err != nil {
+				return err
+			}
+			parts = append(parts, part)
+			if !c.acceptPunctuation(".") {
+				break
+			}
+		}
+		if len(parts) < 2 {
+			return errors.New("COMMENT ON COLUMN requires a table and a column name")
+		}
+		schemaName := m.currentSchema
+		if len(parts) > 2 {
+			schemaName = parts[len(parts)-3]
+		}
+		tableName, columnName := parts[len(parts)-2], parts[len(parts)-1]
+		setComment = func(comment string) error {
+			var columns []schema.Column
+			if table := m.findTable(schemaName, tableName); table != nil {
+				columns = table.table.Columns
+			} else if view := m.findView(schemaName, tableName); view != nil {
+				columns = view.view.Columns
+			}
+			if column := findColumn(columns, columnName); column != nil {
+				column.Comment = comment
+				return nil
+			}
+			return fmt.Errorf("column %s.%s does not exist", schema.QualifiedName(schemaName, tableName), columnName)
+		}
+	default:
+		return nil
+	}
+
+	if !c.acceptKeyword("IS") {
+		return c.unexpected("IS")
+	}
+	if c.acceptKeyword("NULL") {
+		return setComment("")
+	}
+	comment, err := m.stringValue(c.peek())
+	if err != nil {
+		return err
+	}
+	return setComment(comment)
+}
+
+// alterTable parses the rest of ALTER TABLE and applies each of its
+// comma-separated actions
+func (m *DDLModel) alterTable(c *ddlCursor) error {
+	ifExists := c.acceptKeyword("IF", "EXISTS")
+	c.acceptKeyword("ONLY")
+	schemaName, name, err := m.qualifiedName(c)
+	if err != nil {
+		return err
+	}
+	t := m.findTable(schemaName, name)
+	if t == nil {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("table %s does not exist", schema.QualifiedName(schemaName, name))
+	}
+
+	for _, action := range splitSQLList(c.tokens[c.pos:]) {
+		if err := m.alterTableAction(t, &ddlCursor{text: c.text, tokens: action}); err != nil {
+			return err
+		}
+	}
+	m.addForeignKeyIndexes(t)
+	return nil
+}
+
+func (m *DDLModel) alterTableAction(t *ddlTable, c *ddlCursor) error {
+	switch {
+	case c.acceptKeyword("ADD"):
+		if !c.acceptKeyword("COLUMN") && !c.done() && isDDLTableConstraint(c.peek()) {
+			return m.addTableConstraint(t, c)
+		}
+		if c.acceptKeyword("IF", "NOT", "EXISTS") && c.peek().isIdentifier() &&
+			findColumn(t.table.Columns, m.tokenName(c.peek())) != nil {
+			return nil
+		}
+		return m.addColumn(t, c)
+	case c.acceptKeyword("DROP"):
+		return m.dropFromTable(t, c)
+	case c.acceptKeyword("ALTER"):
+		c.acceptKeyword("COLUMN")
+		return m.alterColumn(t, c)
+	case c.acceptKeyword("MODIFY"):
+		c.acceptKeyword("COLUMN")
+		if !c.peek().isIdentifier() {
+			return c.unexpected("a column name")
+		}
+		return m.replaceColumn(t, m.tokenName(c.peek()), c)
+	case c.acceptKeyword("CHANGE"):
+		c.acceptKeyword("COLUMN")
+		oldName, err := m.name(c)
+		if err != nil {
+			return err
+		}
+		return m.replaceColumn(t, oldName, c)
+	case c.acceptKeyword("RENAME"):
+		return m.renameInTable(t, c)
+	case c.acceptKeyword("COMMENT"):
+		c.acceptPunctuation("=")
+		comment, err := m.stringValue(c.peek())
+		if err != nil {
+			return err
+		}
+		t.table.Comment = comment
+	}
+	// Other actions, such as OWNER TO or ENGINE =, do not change the schema
+	return nil
+}
+
+// dropFromTable parses the rest of an ALTER TABLE ... DROP action
+func (m *DDLModel) dropFromTable(t *ddlTable, c *ddlCursor) error {
+	switch {
+	case c.acceptKeyword("PRIMARY", "KEY"):
+		t.table.PrimaryKey = nil
+		t.primaryKeyName = ""
+		return nil
+	case c.acceptKeyword("CONSTRAINT"), c.acceptKeyword("INDEX"), c.acceptKeyword("KEY"),
+		c.acceptKeyword("FOREIGN", "KEY"), c.acceptKeyword("CHECK"):
+		ifExists := c.acceptKeyword("IF", "EXISTS")
+		name, err := m.name(c)
+		if err != nil {
+			return err
+		}
+		if !m.dropConstraint(t, name) && !ifExists {
+			return fmt.Errorf("constraint %s of table %s does not exist", name, t.table.Name)
+		}
+		return nil
+	}
+
+	c.acceptKeyword("COLUMN")
+	ifExists := c.acceptKeyword("IF", "EXISTS")
+	name, err := m.name(c)
+	if err != nil {
+		return err
+	}
+	if findColumn(t.table.Columns, name) == nil {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("column %s of table %s does not exist", name, t.table.Name)
+	}
+	m.dropColumn(t, name)
+	return nil
+}
+
+// dropConstraint drops the primary key, unique key, index, check, or foreign
+// key with the given name and reports whether there was one
+func (m *DDLModel) dropConstraint(t *ddlTable, name string) bool {
+	primaryKeyName := t.primaryKeyName
+	if primaryKeyName == "" && m.dialect == DDLDialectPostgres {
+		primaryKeyName = t.table.Name + "_pkey"
+	}
+	if len(t.table.PrimaryKey) > 0 && strings.EqualFold(primaryKeyName, name) {
+		t.table.PrimaryKey = nil
+		t.primaryKeyName = ""
+		return true
+	}
+
+	matches := func(constraintName string) bool { return strings.EqualFold(constraintName, name) }
+	before := len(t.table.Indexes) + len(t.table.Checks) + len(t.table.Relations)
+	t.table.Indexes = slices.DeleteFunc(t.table.Indexes, func(index schema.Index) bool { return matches(index.Name) })
+	t.table.Checks = slices.DeleteFunc(t.table.Checks, func(check schema.CheckConstraint) bool { return matches(check.Name) })
+	t.table.Relations = slices.DeleteFunc(t.table.Relations, func(relation schema.Relation) bool { return matches(relation.Name) })
+	return len(t.table.Indexes)+len(t.table.Checks)+len(t.table.Relations) < before
+}
+
+// dropColumn drops a column with the keys, indexes, checks, and foreign keys
+// that use it, as PostgreSQL does
+func (m *DDLModel) dropColumn(t *ddlTable, name string) {
+	uses := func(columns []string) bool {
+		return slices.ContainsFunc(columns, func(column string) bool { return strings.EqualFold(column, name) })
+	}
+	t.table.Columns = slices.DeleteFunc(t.table.Columns, func(column schema.Column) bool { return strings.EqualFold(column.Name, name) })
+	if uses(t.table.PrimaryKey) {
+		t.table.PrimaryKey = nil
+		t.primaryKeyName = ""
+	}
+	t.table.Indexes = slices.DeleteFunc(t.table.Indexes, func(index schema.Index) bool { return uses(index.Columns) })
+	t.table.Checks = slices.DeleteFunc(t.table.Checks, func(check schema.CheckConstraint) bool {
+		if check.Columns == nil {
+			return uses(checkExpressionColumns(check.Expression, []schema.Column{{Name: name}}))
+		}
+		return uses(check.Columns)
+	})
+	t.table.Relations = slices.DeleteFunc(t.table.Relations, func(relation schema.Relation) bool { return uses(relation.SourceColumns) })
+}
+
+// alterColumn parses the rest of ALTER TABLE ... ALTER [COLUMN] name
+func (m *DDLModel) alterColumn(t *ddlTable, c *ddlCursor) error {
+	name, err := m.name(c)
+	if err != nil {
+		return err
+	}
+	column := findColumn(t.table.Columns, name)
+	if column == nil {
+		return fmt.Errorf("column %s of table %s does not exist", name, t.table.Name)
+	}
+
+	switch {
+	case c.acceptKeyword("SET", "NOT", "NULL"):
+		column.Nullable = false
+	case c.acceptKeyword("DROP", "NOT", "NULL"):
+		column.Nullable = true
+	case c.acceptKeyword("SET", "DEFAULT"):
+		value := m.defaultValue(c, c.tokens[c.pos:])
+		column.DefaultValue = &value
+	case c.acceptKeyword("DROP", "DEFAULT"):
+		column.DefaultValue = nil
+	case c.acceptKeyword("DROP", "IDENTITY"):
+		column.Identity = ""
+	case c.acceptKeyword("ADD", "GENERATED"):
+		return m.parseGenerated(c, column)
+	case c.acceptKeyword("SET", "DATA", "TYPE"), c.acceptKeyword("TYPE"):
+		end := c.pos
+		for end < len(c.tokens) && !c.tokens[end].isKeyword("USING") && !c.tokens[end].isKeyword("COLLATE") {
+			end++
+		}
+		column.EnumValues = nil
+		m.setColumnType(column, c.tokens[c.pos:end], c)
+	}
+	return nil
+}
+
+// replaceColumn replaces a column with a new definition in place, for MySQL
+// MODIFY and CHANGE
+func (m *DDLModel) replaceColumn(t *ddlTable, oldName string, c *ddlCursor) error {
+	index := slices.IndexFunc(t.table.Columns, func(column schema.Column) bool { return strings.EqualFold(column.Name, oldName) })
+	if index < 0 {
+		return fmt.Errorf("column %s of table %s does not exist", oldName, t.table.Name)
+	}
+	column, err := m.parseColumn(t, c)
+	if err != nil {
+		return err
+	}
+	t.table.Columns[index] = column
+	if column.Name != oldName {
+		m.renameColumnReferences(t, oldName, column.Name)
+	}
+	return nil
+}
+
+// renameInTable parses the rest of ALTER TABLE ... RENAME
+func (m *DDLModel) renameInTable(t *ddlTable, c *ddlCursor) error {
+	if c.acceptKeyword("TO") || c.acceptKeyword("AS") {
+		newName, err := m.name(c)
+		if err != nil {
+			return err
+		}
+		if m.findTable(t.schemaName, newName) != nil {
+			return fmt.Errorf("relation %s already exists", schema.QualifiedName(t.schemaName, newName))
+		}
+		for _, other := range m.tables {
+			for i := range other.table.Relations {
+				relation := &other.table.Relations[i]
+				if strings.EqualFold(relation.TargetSchema, t.schemaName) && strings.EqualFold(relation.TargetTable, t.table.Name) {
+					relation.TargetTable = newName
+				}
+			}
+		}
+		t.table.Name = newName
+		return nil
+	}
+
+	if c.acceptKeyword("CONSTRAINT") || c.acceptKeyword("INDEX") || c.acceptKeyword("KEY") {
+		oldName, err := m.name(c)
+		if err != nil {
+			return err
+		}
+		if !c.acceptKeyword("TO") {
+			return c.unexpected("TO")
+		}
+		newName, err := m.name(c)
+		if err != nil {
+			return err
+		}
+		m.renameConstraint(t, oldName, newName)
+		return nil
+	}
+
+	c.acceptKeyword("COLUMN")
+	oldName, err := m.name(c)
+	if err != nil {
+		return err
+	}
+	if !c.acceptKeyword("TO") {
+		return c.unexpected("TO")
+	}
+	newName, err := m.name(c)
+	if err != nil {
+		return err
+	}
+	column := findColumn(t.table.Columns, oldName)
+	if column == nil {
+		return fmt.Errorf("column %s of table %s does not exist", oldName, t.table.Name)
+	}
+	column.Name = newName
+	m.renameColumnReferences(t, oldName, newName)
+	return nil
+}
+
+func (m *DDLModel) renameConstraint(t *ddlTable, oldName, newName string) {
+	if strings.EqualFold(t.primaryKeyName, oldName) || (t.primaryKeyName == "" && strings.EqualFold(t.table.Name+"_pkey", oldName)) {
+		t.primaryKeyName = newName
+	}
+	for i := range t.table.Indexes {
+		if strings.EqualFold(t.table.Indexes[i].Name, oldName) {
+			t.table.Indexes[i].Name = newName
+		}
+	}
+	for i := range t.table.Checks {
+		if strings.EqualFold(t.table.Checks[i].Name, oldName) {
+			t.table.Checks[i].Name = newName
+		}
+	}
+	for i := range t.table.Relations {
+		if strings.EqualFold(t.table.Relations[i].Name, oldName) {
+			t.table.Relations[i].Name = newName
+		}
+	}
+}
+
+// renameColumnReferences renames a column in the keys, indexes, and checks
+// of its table, and in the foreign keys of every table that references it
+func (m *DDLModel) renameColumnReferences(t *ddlTable, oldName, newName string) {
+	rename := func(columns []string) {
+		for i, column := range columns {
+			if strings.EqualFold(column, oldName) {
+				columns[i] = newName
+			}
+		}
+	}
+	rename(t.table.PrimaryKey)
+	for _, index := range t.table.Indexes {
+		rename(index.Columns)
+	}
+	for _, check := range t.table.Checks {
+		rename(check.Columns)
+	}
+	for _, relation := range t.table.Relations {
+		rename(relation.SourceColumns)
+	}
+	for _, other := range m.tables {
+		for _, relation := range other.table.Relations {
+			if strings.EqualFold(relation.TargetSchema, t.schemaName) && strings.EqualFold(relation.TargetTable, t.table.Name) {
+				rename(relation.TargetColumns)
+			}
+		}
+	}
+}
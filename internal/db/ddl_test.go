@@ -0,0 +1,355 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+func applyDDL(t *testing.T, dialect, sqlText string) *DDLModel {
+	t.Helper()
+	m, err := NewDDLModel(dialect)
+	if err != nil {
+		t.Fatalf("NewDDLModel() failed: %v", err)
+	}
+	if err := m.Apply(sqlText); err != nil {
+		t.Fatalf("Apply() failed: %v", err)
+	}
+	return m
+}
+
+func extractDDLTable(t *testing.T, m *DDLModel, name string) schema.Table {
+	t.Helper()
+	s, err := m.ExtractSchema("", []string{name})
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	if len(s.Tables) != 1 {
+		t.Fatalf("ExtractSchema() returned %d tables, want 1", len(s.Tables))
+	}
+	return s.Tables[0]
+}
+
+func TestDDLModelMatchesSQLiteExtractor(t *testing.T) {
+	script, err := os.ReadFile("../../test_sqlite_schema.sql")
+	if err != nil {
+		t.Fatalf("reading test schema failed: %v", err)
+	}
+
+	ctx := context.Background()
+	client, err := NewSQLiteClient(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+	if _, err := client.GetDB().ExecContext(ctx, string(script)); err != nil {
+		t.Fatalf("creating test schema failed: %v", err)
+	}
+	want, err := NewSQLiteExtractor(client).ExtractSchema(ctx, nil)
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+
+	got, err := applyDDL(t, DDLDialectSQLite, string(script)).ExtractSchema("", nil)
+	if err != nil {
+		t.Fatalf("DDL ExtractSchema() failed: %v", err)
+	}
+	if len(got.Tables) != len(want.Tables) {
+		t.Fatalf("DDL model has %d tables, want %d", len(got.Tables), len(want.Tables))
+	}
+	for i := range want.Tables {
+		// Compare the JSON documents, which do not distinguish nil and empty slices
+		gotJSON, _ := json.Marshal(got.Tables[i])
+		wantJSON, _ := json.Marshal(want.Tables[i])
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("table %s from DDL = %s, want %s", want.Tables[i].Name, gotJSON, wantJSON)
+		}
+	}
+	for i := range want.Views {
+		// View columns come from the catalog, so only the definition is compared
+		if got.Views[i].Name != want.Views[i].Name || got.Views[i].Definition != want.Views[i].Definition ||
+			!slices.Equal(got.Views[i].Dependencies, want.Views[i].Dependencies) {
+			t.Errorf("view from DDL = %+v, want %+v", got.Views[i], want.Views[i])
+		}
+	}
+}
+
+func TestDDLModelPostgresSchema(t *testing.T) {
+	script, err := os.ReadFile("../../test_postgres_schema.sql")
+	if err != nil {
+		t.Fatalf("reading test schema failed: %v", err)
+	}
+	m := applyDDL(t, DDLDialectPostgres, string(script))
+
+	if got := m.Schemas(); !slices.Equal(got, []string{"identity", "public"}) {
+		t.Errorf("Schemas() = %v, want [identity public]", got)
+	}
+
+	users := extractDDLTable(t, m, "users")
+	id := findColumn(users.Columns, "id")
+	if id.Type != "integer" || id.Identity != schema.IdentityByDefault || id.Nullable || id.DefaultValue != nil {
+		t.Errorf("serial column = %+v, want a non-null integer identity without default", *id)
+	}
+	if username := findColumn(users.Columns, "username"); username.Type != "varchar(50)" || !username.IsUnique {
+		t.Errorf("username = %+v, want unique varchar(50)", *username)
+	}
+	status := findColumn(users.Columns, "status")
+	if status.Type != "user_status" || !slices.Equal(status.EnumValues, []string{"active", "inactive", "banned"}) {
+		t.Errorf("status = %+v, want user_status enum values", *status)
+	}
+	if status.Comment != "Account lifecycle state" || users.Comment != "Registered application accounts" {
+		t.Errorf("comments = %q, %q", users.Comment, status.Comment)
+	}
+
+	products := extractDDLTable(t, m, "products")
+	if price := findColumn(products.Columns, "price"); price.Type != "numeric" || price.CheckConstraint == nil || *price.CheckConstraint != "price >= 0" {
+		t.Errorf("price = %+v, want numeric with its check", *price)
+	}
+	if len(products.Checks) != 1 || products.Checks[0].Name != "products_sale_below_price" ||
+		!slices.Equal(products.Checks[0].Columns, []string{"price", "sale_price"}) {
+		t.Errorf("Checks = %+v, want the table-level check", products.Checks)
+	}
+	if findColumn(products.Columns, "id").Identity != schema.IdentityAlways {
+		t.Error("products.id is not an identity column")
+	}
+
+	orderItems := extractDDLTable(t, m, "order_items")
+	if generated := findColumn(orderItems.Columns, "line_total").Generated; generated == nil ||
+		generated.Expression != "quantity * unit_price" || !generated.Stored {
+		t.Errorf("line_total generated = %+v, want stored quantity * unit_price", generated)
+	}
+	if !slices.Equal(orderItems.UniqueKeys[0], []string{"order_id", "product_id"}) {
+		t.Errorf("UniqueKeys = %v", orderItems.UniqueKeys)
+	}
+
+	orders := extractDDLTable(t, m, "orders")
+	relation := orders.Relations[0]
+	if relation.Name != "orders_user_id_fkey" || relation.TargetTable != "users" || relation.TargetSchema != "" ||
+		relation.OnDelete != "CASCADE" || relation.OnUpdate != "NO ACTION" || relation.Cardinality != "N:1" {
+		t.Errorf("orders relation = %+v", relation)
+	}
+
+	external := extractDDLTable(t, m, "external_profiles")
+	if external.Relations[0].TargetSchema != "identity" || external.Relations[0].TargetTable != "users" {
+		t.Errorf("external relation = %+v, want identity.users", external.Relations[0])
+	}
+
+	expressionChildren := extractDDLTable(t, m, "expression_children")
+	if index := expressionChildren.Indexes[0]; index.Name != "expression_children_user_label" || !index.HasExpressions || !index.IsUnique {
+		t.Errorf("expression index = %+v", index)
+	}
+
+	s, err := m.ExtractSchema("", nil)
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	if s.SchemaName != "public" || len(s.Tables) != 10 || len(s.Views) != 2 {
+		t.Fatalf("schema %s has %d tables and %d views, want public with 10 and 2", s.SchemaName, len(s.Tables), len(s.Views))
+	}
+	if view := s.Views[1]; view.Kind != schema.ViewKindMaterialized || !slices.Equal(view.Dependencies, []string{"orders"}) {
+		t.Errorf("order_totals = %+v", view)
+	}
+
+	multi, err := m.ExtractSchemas([]string{"identity", "public"}, []string{"identity.users", "external_profiles"})
+	if err != nil {
+		t.Fatalf("ExtractSchemas() failed: %v", err)
+	}
+	var names []string
+	for _, table := range multi.Tables {
+		names = append(names, schema.QualifiedName(table.Schema, table.Name))
+	}
+	if !slices.Equal(names, []string{"identity.users", "public.external_profiles"}) {
+		t.Errorf("ExtractSchemas() tables = %v", names)
+	}
+}
+
+func TestDDLModelMySQLSchema(t *testing.T) {
+	script, err := os.ReadFile("../../test_mysql_schema.sql")
+	if err != nil {
+		t.Fatalf("reading test schema failed: %v", err)
+	}
+	m := applyDDL(t, DDLDialectMySQL, "USE testdb;\n"+string(script))
+
+	s, err := m.ExtractSchema("", []string{"users", "order_items"})
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	if s.DatabaseName != "testdb" {
+		t.Errorf("DatabaseName = %q, want testdb", s.DatabaseName)
+	}
+
+	users := s.Tables[0]
+	status := findColumn(users.Columns, "status")
+	if status.Type != "enum('active','inactive','banned')" || len(status.EnumValues) != 3 ||
+		status.DefaultValue == nil || *status.DefaultValue != "active" {
+		t.Errorf("status = %+v, want enum with unquoted default", *status)
+	}
+	if id := findColumn(users.Columns, "id"); id.Identity != schema.IdentityByDefault || id.Nullable {
+		t.Errorf("id = %+v, want non-null auto increment", *id)
+	}
+	if users.Comment != "Registered application accounts" {
+		t.Errorf("table comment = %q", users.Comment)
+	}
+
+	orderItems := s.Tables[1]
+	var relationNames, indexNames []string
+	for _, relation := range orderItems.Relations {
+		relationNames = append(relationNames, relation.Name)
+	}
+	for _, index := range orderItems.Indexes {
+		indexNames = append(indexNames, index.Name)
+	}
+	if !slices.Equal(relationNames, []string{"order_items_ibfk_1", "order_items_ibfk_2"}) {
+		t.Errorf("relation names = %v", relationNames)
+	}
+	// The unique key covers order_id, so only product_id gets a foreign key index
+	if !slices.Equal(indexNames, []string{"unique_order_product", "product_id"}) {
+		t.Errorf("index names = %v", indexNames)
+	}
+	if unitPrice := findColumn(orderItems.Columns, "unit_price"); unitPrice.Type != "decimal(10,2)" {
+		t.Errorf("unit_price type = %q, want decimal(10,2)", unitPrice.Type)
+	}
+}
+
+func TestDDLModelAppliesAlterStatements(t *testing.T) {
+	m := applyDDL(t, DDLDialectPostgres, `
+CREATE TYPE mood AS ENUM ('sad', 'happy');
+ALTER TYPE mood ADD VALUE 'ok' BEFORE 'happy';
+CREATE TABLE accounts (id bigint PRIMARY KEY, name text);
+CREATE TABLE posts (
+    id bigint PRIMARY KEY,
+    author_id bigint REFERENCES accounts,
+    title varchar(10),
+    legacy text
+);
+ALTER TABLE posts
+    ADD COLUMN feeling mood NOT NULL DEFAULT 'ok',
+    ALTER COLUMN title TYPE character varying(200),
+    ALTER COLUMN title SET NOT NULL,
+    DROP COLUMN legacy;
+ALTER TABLE posts ADD CONSTRAINT posts_title_unique UNIQUE (title);
+ALTER TABLE accounts RENAME TO users;
+ALTER TABLE users RENAME COLUMN id TO user_id;
+ALTER TABLE posts RENAME COLUMN author_id TO user_id;
+CREATE INDEX ON posts (user_id);
+DROP INDEX posts_title_unique;
+COMMENT ON COLUMN posts.feeling IS 'How the author felt';
+`)
+
+	posts := extractDDLTable(t, m, "posts")
+	var columnNames []string
+	for _, column := range posts.Columns {
+		columnNames = append(columnNames, column.Name)
+	}
+	if !slices.Equal(columnNames, []string{"id", "user_id", "title", "feeling"}) {
+		t.Errorf("columns = %v", columnNames)
+	}
+	if title := findColumn(posts.Columns, "title"); title.Type != "varchar(200)" || title.Nullable || title.IsUnique {
+		t.Errorf("title = %+v, want non-null varchar(200) without unique key", *title)
+	}
+	feeling := findColumn(posts.Columns, "feeling")
+	if !slices.Equal(feeling.EnumValues, []string{"sad", "ok", "happy"}) || feeling.Comment != "How the author felt" {
+		t.Errorf("feeling = %+v", *feeling)
+	}
+
+	relation := posts.Relations[0]
+	if relation.TargetTable != "users" || !slices.Equal(relation.SourceColumns, []string{"user_id"}) ||
+		!slices.Equal(relation.TargetColumns, []string{"user_id"}) {
+		t.Errorf("relation = %+v, want posts.user_id -> users.user_id", relation)
+	}
+	if len(posts.Indexes) != 1 || posts.Indexes[0].Name != "posts_user_id_idx" {
+		t.Errorf("Indexes = %+v, want posts_user_id_idx", posts.Indexes)
+	}
+}
+
+func TestDDLModelReportsStatementLine(t *testing.T) {
+	m, err := NewDDLModel(DDLDialectPostgres)
+	if err != nil {
+		t.Fatalf("NewDDLModel() failed: %v", err)
+	}
+	err = m.Apply("CREATE TABLE a (id int);\n\n-- comment\nALTER TABLE missing\n  ADD COLUMN x int;\n")
+	if err == nil || !strings.Contains(err.Error(), "line 4:") || !strings.Contains(err.Error(), "public.missing") {
+		t.Errorf("Apply() error = %v, want line 4 and the missing table", err)
+	}
+
+	if err := m.Apply("CREATE TABLE a (id int);"); err == nil {
+		t.Error("Apply() accepted a duplicate table")
+	}
+	if err := m.Apply("CREATE TABLE IF NOT EXISTS a (id int);"); err != nil {
+		t.Errorf("Apply() rejected IF NOT EXISTS: %v", err)
+	}
+	if _, err := NewDDLModel("oracle"); err == nil {
+		t.Error("NewDDLModel() accepted an unsupported dialect")
+	}
+}
+
+func TestSplitDDLStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		sql     string
+		want    []string
+	}{
+		{
+			name:    "quotes and comments",
+			dialect: DDLDialectPostgres,
+			sql:     "SELECT ';'; -- a; comment\n/* b; */ SELECT \"x;y\";",
+			want:    []string{"SELECT ';'", "-- a; comment\n/* b; */ SELECT \"x;y\""},
+		},
+		{
+			name:    "dollar quoted function body",
+			dialect: DDLDialectPostgres,
+			sql:     "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;\nCREATE TABLE t (id int);",
+			want:    []string{"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql", "CREATE TABLE t (id int)"},
+		},
+		{
+			name:    "mysql delimiter",
+			dialect: DDLDialectMySQL,
+			sql:     "DELIMITER //\nCREATE PROCEDURE p() BEGIN SELECT 1; END//\nDELIMITER ;\nCREATE TABLE t (id int);",
+			want:    []string{"CREATE PROCEDURE p() BEGIN SELECT 1; END", "CREATE TABLE t (id int)"},
+		},
+		{
+			name:    "sqlite trigger",
+			dialect: DDLDialectSQLite,
+			sql:     "CREATE TRIGGER tr AFTER INSERT ON t BEGIN UPDATE t SET x = 1; END;\nCREATE TABLE u (id int);",
+			want:    []string{"CREATE TRIGGER tr AFTER INSERT ON t BEGIN UPDATE t SET x = 1; END", "CREATE TABLE u (id int)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, statement := range splitDDLStatements(tt.sql, tt.dialect) {
+				got = append(got, statement.text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitDDLStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDDLModelMySQLCommentsAndBackslashEscapes(t *testing.T) {
+	m := applyDDL(t, DDLDialectMySQL, `CREATE TABLE notes (
+		id INT, # the id
+		label VARCHAR(20) DEFAULT 'it\'s, ok', -- trailing comment
+		body TEXT
+	);`)
+
+	notes := extractDDLTable(t, m, "notes")
+	var names []string
+	for _, column := range notes.Columns {
+		names = append(names, column.Name)
+	}
+	if !slices.Equal(names, []string{"id", "label", "body"}) {
+		t.Fatalf("columns = %v, want [id label body]", names)
+	}
+	if label := findColumn(notes.Columns, "label"); label.Type != "varchar(20)" || label.DefaultValue == nil || *label.DefaultValue != "it's, ok" {
+		t.Errorf("label = %+v, want varchar(20) with default it's, ok", *label)
+	}
+}
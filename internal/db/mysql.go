@@ -10,10 +10,13 @@ import (
 
 // MySQLClient manages the connection to MySQL
 type MySQLClient struct {
-	db *sql.DB
+	db       *sql.DB
+	querier  SQLQuerier
+	borrowed bool // owned by the caller and never closed
 }
 
-// NewMySQLClient creates a new MySQL client
+// NewMySQLClient creates a new MySQL client. Catalog queries run one at a
+// time, because the consistent snapshot they read belongs to one connection.
 func NewMySQLClient(ctx context.Context, connString string) (*MySQLClient, error) {
 	db, err := sql.Open("mysql", connString)
 	if err != nil {
@@ -26,11 +29,20 @@ func NewMySQLClient(ctx context.Context, connString string) (*MySQLClient, error
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &MySQLClient{db: db}, nil
+	return &MySQLClient{db: db, querier: db}, nil
 }
 
-// Close closes the database connection
+// NewMySQLClientFromDB creates a client that queries through an existing
+// connection pool. The caller keeps ownership: Close leaves it open.
+func NewMySQLClientFromDB(db *sql.DB) *MySQLClient {
+	return &MySQLClient{db: db, querier: db, borrowed: true}
+}
+
+// Close closes the database connection if the client opened it
 func (c *MySQLClient) Close() error {
+	if c.borrowed {
+		return nil
+	}
 	return c.db.Close()
 }
 
@@ -39,6 +51,24 @@ func (c *MySQLClient) GetDB() *sql.DB {
 	return c.db
 }
 
+// GetConnection returns the connection that queries run on, which is a
+// transaction within ReadSnapshot
+func (c *MySQLClient) GetConnection() SQLQuerier {
+	return c.querier
+}
+
+// CurrentDatabase returns the database selected on the connection
+func (c *MySQLClient) CurrentDatabase(ctx context.Context) (string, error) {
+	var databaseName sql.NullString
+	if err := c.querier.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&databaseName); err != nil {
+		return "", fmt.Errorf("failed to query current database: %w", err)
+	}
+	if !databaseName.Valid || databaseName.String == "" {
+		return "", fmt.Errorf("no database selected on the connection")
+	}
+	return databaseName.String, nil
+}
+
 // ParseDatabaseName extracts the database name from a MySQL connection string
 func ParseDatabaseName(connString string) (string, error) {
 	cfg, err := mysql.ParseDSN(connString)
@@ -3,16 +3,23 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/go-sql-driver/mysql"
+	"github.com/tordrt/llmschema/schema"
 )
 
+// mysqlErrUnknownTable is ER_UNKNOWN_TABLE, returned for information_schema
+// tables the server does not provide
+const mysqlErrUnknownTable = 1109
+
 // MySQLExtractor handles schema extraction from MySQL
 type MySQLExtractor struct {
 	client     *MySQLClient
 	schemaName string
+	mariaDB    bool // MariaDB scopes CHECK constraint names to their table
 }
 
 // NewMySQLExtractor creates a new MySQL schema extractor
@@ -26,23 +33,40 @@ func NewMySQLExtractor(client *MySQLClient, schemaName string) *MySQLExtractor {
 // ExtractSchema extracts the complete schema for specified tables
 // If tables is empty, extracts all tables in the schema
 func (e *MySQLExtractor) ExtractSchema(ctx context.Context, tables []string) (*schema.Schema, error) {
-	var extractedTables []schema.Table
 	var databaseVersion string
 	// Version metadata is optional: compatible servers and proxies may not
 	// support this query even when schema extraction itself works.
-	_ = e.client.GetDB().QueryRowContext(ctx, "SELECT VERSION()").Scan(&databaseVersion)
+	_ = e.client.GetConnection().QueryRowContext(ctx, "SELECT VERSION()").Scan(&databaseVersion)
+	e.mariaDB = strings.Contains(databaseVersion, "MariaDB")
 
 	tableNames, err := e.getTableNames(ctx, tables)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get table names: %w", err)
 	}
+	viewCatalog, err := e.getViews(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get views: %w", err)
+	}
+	tableNames, views := splitRequestedViews(tables, tableNames, viewCatalog)
+
+	// Without a table selection every relation of the schema is extracted,
+	// so the catalog queries need no name filter
+	var names []string
+	if len(tables) > 0 {
+		names = relationNames(tableNames, views)
+	}
+	catalog, err := e.loadCatalog(ctx, names)
+	if err != nil {
+		return nil, err
+	}
 
+	var extractedTables []schema.Table
 	for _, tableName := range tableNames {
-		table, err := e.extractTable(ctx, tableName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to extract table %s: %w", tableName, err)
-		}
-		extractedTables = append(extractedTables, *table)
+		extractedTables = append(extractedTables, catalog.table(tableName))
+	}
+	var extractedViews []schema.View
+	for _, metadata := range views {
+		extractedViews = append(extractedViews, catalog.view(metadata))
 	}
 
 	return &schema.Schema{
@@ -51,6 +75,7 @@ func (e *MySQLExtractor) ExtractSchema(ctx context.Context, tables []string) (*s
 		DatabaseName:    e.schemaName,
 		SchemaName:      e.schemaName,
 		Tables:          extractedTables,
+		Views:           extractedViews,
 	}, nil
 }
 
@@ -67,7 +92,7 @@ func (e *MySQLExtractor) getTableNames(ctx context.Context, requestedTables []st
 		ORDER BY table_name
 	`
 
-	rows, err := e.client.GetDB().QueryContext(ctx, query, e.schemaName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, e.schemaName)
 	if err != nil {
 		return nil, err
 	}
@@ -85,73 +110,150 @@ func (e *MySQLExtractor) getTableNames(ctx context.Context, requestedTables []st
 	return tables, rows.Err()
 }
 
-// extractTable extracts all information for a single table
-func (e *MySQLExtractor) extractTable(ctx context.Context, tableName string) (*schema.Table, error) {
-	table := &schema.Table{Name: tableName}
+// getViews returns the views in the schema
+func (e *MySQLExtractor) getViews(ctx context.Context) ([]viewMetadata, error) {
+	query := `
+		SELECT table_name, view_definition
+		FROM information_schema.views
+		WHERE table_schema = ?
+		ORDER BY table_name
+	`
 
-	// Extract columns
-	columns, err := e.extractColumns(ctx, tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, e.schemaName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract columns: %w", err)
+		return nil, err
 	}
-	table.Columns = columns
+	defer func() { _ = rows.Close() }()
 
-	// Extract primary key
-	pk, err := e.extractPrimaryKey(ctx, tableName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract primary key: %w", err)
+	var views []viewMetadata
+	for rows.Next() {
+		var view viewMetadata
+		var definition sql.NullString
+		if err := rows.Scan(&view.name, &definition); err != nil {
+			return nil, err
+		}
+		view.definition = definition.String
+		views = append(views, view)
 	}
-	table.PrimaryKey = pk
 
-	// Extract indexes
-	indexes, err := e.extractIndexes(ctx, tableName)
-	if err != nil {
+	return views, rows.Err()
+}
+
+// loadCatalog loads the metadata of the named tables and views, or of every
+// table and view in the schema when names is empty. information_schema is
+// slow to query table by table, so each kind of metadata is one query for
+// all of them. The queries run one at a time, on the connection whose
+// consistent snapshot they read.
+func (e *MySQLExtractor) loadCatalog(ctx context.Context, names []string) (*catalog, error) {
+	c := &catalog{}
+
+	var err error
+	if c.comments, err = e.extractTableComments(ctx, names); err != nil {
+		return nil, fmt.Errorf("failed to extract table comments: %w", err)
+	}
+	if c.columns, err = e.extractColumns(ctx, names); err != nil {
+		return nil, fmt.Errorf("failed to extract columns: %w", err)
+	}
+	if c.primaryKeys, err = e.extractPrimaryKeys(ctx, names); err != nil {
+		return nil, fmt.Errorf("failed to extract primary keys: %w", err)
+	}
+	if c.indexes, err = e.extractIndexes(ctx, names); err != nil {
 		return nil, fmt.Errorf("failed to extract indexes: %w", err)
 	}
-	table.Indexes = indexes
-	applyUniqueKeys(table)
+	if c.checks, err = e.extractCheckConstraints(ctx, names); err != nil {
+		return nil, fmt.Errorf("failed to extract check constraints: %w", err)
+	}
+	if c.relations, err = e.extractRelations(ctx, names); err != nil {
+		return nil, fmt.Errorf("failed to extract relations: %w", err)
+	}
+	if c.dependencies, err = e.extractViewDependencies(ctx, names); err != nil {
+		return nil, fmt.Errorf("failed to extract view dependencies: %w", err)
+	}
 
-	// Extract relations after keys and indexes so cardinality can be inferred.
-	relations, err := e.extractRelations(ctx, tableName, pk, indexes)
+	// CHECK clauses only name their columns in the expression
+	for tableName, checks := range c.checks {
+		for i := range checks {
+			checks[i].Columns = checkExpressionColumns(checks[i].Expression, c.columns[tableName])
+		}
+	}
+	return c, nil
+}
+
+// extractViewDependencies returns the tables and views each view reads from.
+// information_schema.view_table_usage was added in MySQL 8.0.13; servers
+// without it document views without dependencies.
+func (e *MySQLExtractor) extractViewDependencies(ctx context.Context, names []string) (map[string][]string, error) {
+	query := `
+		SELECT view_name, table_schema, table_name
+		FROM information_schema.view_table_usage
+		WHERE view_schema = ?%s
+		ORDER BY view_name, table_schema, table_name
+	`
+	query, args := e.filterQuery(query, "view_name", names)
+
+	dependencies := make(map[string][]string)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, args...)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrUnknownTable {
+		return dependencies, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract relations: %w", err)
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var viewName, sourceSchema, sourceName string
+		if err := rows.Scan(&viewName, &sourceSchema, &sourceName); err != nil {
+			return nil, err
+		}
+		if sourceSchema != e.schemaName {
+			sourceName = sourceSchema + "." + sourceName
+		}
+		dependencies[viewName] = append(dependencies[viewName], sourceName)
 	}
-	table.Relations = relations
 
-	return table, nil
+	return dependencies, rows.Err()
 }
 
-// extractColumns extracts column information for a table
-func (e *MySQLExtractor) extractColumns(ctx context.Context, tableName string) ([]schema.Column, error) {
+// extractColumns extracts column information for the tables and views of
+// the schema, which information_schema.columns describes the same way
+func (e *MySQLExtractor) extractColumns(ctx context.Context, names []string) (map[string][]schema.Column, error) {
 	query := `
 		SELECT
+			c.table_name,
 			c.column_name,
 			c.column_type,
 			c.is_nullable,
 			c.column_default,
-			c.data_type
+			c.data_type,
+			c.column_comment,
+			c.extra,
+			c.generation_expression
 		FROM information_schema.columns c
-		WHERE c.table_schema = ? AND c.table_name = ?
-		ORDER BY c.ordinal_position
+		WHERE c.table_schema = ?%s
+		ORDER BY c.table_name, c.ordinal_position
 	`
+	query, args := e.filterQuery(query, "c.table_name", names)
 
-	rows, err := e.client.GetDB().QueryContext(ctx, query, e.schemaName, tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
-	var columns []schema.Column
-	var enumColumns []int
-
+	columns := make(map[string][]schema.Column)
 	for rows.Next() {
+		var tableName string
 		var col schema.Column
 		var columnType string
 		var nullable string
 		var defaultVal sql.NullString
 		var dataType string
+		var extra string
+		var generationExpression sql.NullString
 
-		if err := rows.Scan(&col.Name, &columnType, &nullable, &defaultVal, &dataType); err != nil {
+		if err := rows.Scan(&tableName, &col.Name, &columnType, &nullable, &defaultVal, &dataType, &col.Comment, &extra, &generationExpression); err != nil {
 			return nil, err
 		}
 
@@ -160,29 +262,62 @@ func (e *MySQLExtractor) extractColumns(ctx context.Context, tableName string) (
 		if defaultVal.Valid {
 			col.DefaultValue = &defaultVal.String
 		}
+		// EXTRA is "VIRTUAL GENERATED" or "STORED GENERATED" for generated
+		// columns; "DEFAULT_GENERATED" marks expression defaults instead.
+		extra = strings.ToUpper(extra)
+		if strings.Contains(extra, "AUTO_INCREMENT") {
+			col.Identity = schema.IdentityByDefault
+		}
+		if strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") {
+			col.Generated = &schema.GeneratedColumn{
+				Expression: unwrapSQLExpression(generationExpression.String),
+				Stored:     strings.Contains(extra, "STORED GENERATED"),
+			}
+			col.DefaultValue = nil
+		}
 
-		// Check if this is an ENUM column
+		// Extract enum values for enum columns
 		if dataType == "enum" {
-			enumColumns = append(enumColumns, len(columns))
+			col.EnumValues, err = e.extractEnumValues(col.Type)
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		columns = append(columns, col)
+		columns[tableName] = append(columns[tableName], col)
 	}
 
-	if err := rows.Err(); err != nil {
+	return columns, rows.Err()
+}
+
+// extractTableComments extracts the COMMENT table option of the schema's tables
+func (e *MySQLExtractor) extractTableComments(ctx context.Context, names []string) (map[string]string, error) {
+	query := `
+		SELECT t.table_name, t.table_comment
+		FROM information_schema.tables t
+		WHERE t.table_schema = ? AND t.table_type = 'BASE TABLE'%s
+	`
+	query, args := e.filterQuery(query, "t.table_name", names)
+
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, args...)
+	if err != nil {
 		return nil, err
 	}
+	defer func() { _ = rows.Close() }()
 
-	// Extract enum values for enum columns
-	for _, idx := range enumColumns {
-		enumValues, err := e.extractEnumValues(columns[idx].Type)
-		if err != nil {
+	comments := make(map[string]string)
+	for rows.Next() {
+		var tableName string
+		var comment sql.NullString
+		if err := rows.Scan(&tableName, &comment); err != nil {
 			return nil, err
 		}
-		columns[idx].EnumValues = enumValues
+		if comment.String != "" {
+			comments[tableName] = comment.String
+		}
 	}
 
-	return columns, nil
+	return comments, rows.Err()
 }
 
 // extractEnumValues parses enum values from the column type string
@@ -216,39 +351,90 @@ func (e *MySQLExtractor) extractEnumValues(columnType string) ([]string, error)
 	return values, nil
 }
 
-// extractPrimaryKey extracts primary key columns
-func (e *MySQLExtractor) extractPrimaryKey(ctx context.Context, tableName string) ([]string, error) {
+// extractCheckConstraints extracts CHECK constraints by table, without their
+// columns. They are available from MySQL 8.0.16 and MariaDB 10.2.22; older
+// servers report none.
+func (e *MySQLExtractor) extractCheckConstraints(ctx context.Context, names []string) (map[string][]schema.CheckConstraint, error) {
+	query := `
+		SELECT tc.table_name, cc.constraint_name, cc.check_clause
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.check_constraints cc
+			ON cc.constraint_schema = tc.constraint_schema
+			AND cc.constraint_name = tc.constraint_name
+		WHERE tc.table_schema = ? AND tc.constraint_type = 'CHECK'%s
+		ORDER BY tc.table_name, cc.constraint_name
+	`
+	query, args := e.filterQuery(query, "tc.table_name", names)
+	if e.mariaDB {
+		query = `
+			SELECT table_name, constraint_name, check_clause
+			FROM information_schema.check_constraints
+			WHERE constraint_schema = ?%s
+			ORDER BY table_name, constraint_name
+		`
+		query, args = e.filterQuery(query, "table_name", names)
+	}
+
+	checks := make(map[string][]schema.CheckConstraint)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, args...)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrUnknownTable {
+		return checks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var tableName string
+		var check schema.CheckConstraint
+		var clause string
+		if err := rows.Scan(&tableName, &check.Name, &clause); err != nil {
+			return nil, err
+		}
+		check.Expression = unwrapCheckExpression(clause)
+		checks[tableName] = append(checks[tableName], check)
+	}
+
+	return checks, rows.Err()
+}
+
+// extractPrimaryKeys extracts primary key columns by table
+func (e *MySQLExtractor) extractPrimaryKeys(ctx context.Context, names []string) (map[string][]string, error) {
 	query := `
-		SELECT column_name
+		SELECT table_name, column_name
 		FROM information_schema.key_column_usage
 		WHERE table_schema = ?
-			AND table_name = ?
-			AND constraint_name = 'PRIMARY'
-		ORDER BY ordinal_position
+			AND constraint_name = 'PRIMARY'%s
+		ORDER BY table_name, ordinal_position
 	`
+	query, args := e.filterQuery(query, "table_name", names)
 
-	rows, err := e.client.GetDB().QueryContext(ctx, query, e.schemaName, tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
-	var pk []string
+	primaryKeys := make(map[string][]string)
 	for rows.Next() {
-		var colName string
-		if err := rows.Scan(&colName); err != nil {
+		var tableName, colName string
+		if err := rows.Scan(&tableName, &colName); err != nil {
 			return nil, err
 		}
-		pk = append(pk, colName)
+		primaryKeys[tableName] = append(primaryKeys[tableName], colName)
 	}
 
-	return pk, rows.Err()
+	return primaryKeys, rows.Err()
 }
 
-// extractRelations extracts foreign key relationships
-func (e *MySQLExtractor) extractRelations(ctx context.Context, tableName string, primaryKey []string, indexes []schema.Index) ([]schema.Relation, error) {
+// extractRelations extracts foreign key relationships by table. They are
+// finalized once the table's keys and indexes are known.
+func (e *MySQLExtractor) extractRelations(ctx context.Context, names []string) (map[string][]schema.Relation, error) {
 	query := `
 		SELECT
+			kcu.table_name,
 			kcu.constraint_name,
 			kcu.column_name,
 			kcu.referenced_table_schema,
@@ -262,82 +448,79 @@ func (e *MySQLExtractor) extractRelations(ctx context.Context, tableName string,
 			AND rc.constraint_name = kcu.constraint_name
 			AND rc.table_name = kcu.table_name
 		WHERE kcu.table_schema = ?
-			AND kcu.table_name = ?
-			AND kcu.referenced_table_name IS NOT NULL
-		ORDER BY kcu.constraint_name, kcu.ordinal_position
+			AND kcu.referenced_table_name IS NOT NULL%s
+		ORDER BY kcu.table_name, kcu.constraint_name, kcu.ordinal_position
 	`
+	query, args := e.filterQuery(query, "kcu.table_name", names)
 
-	rows, err := e.client.GetDB().QueryContext(ctx, query, e.schemaName, tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
-	var relations []schema.Relation
-	var current *schema.Relation
+	relations := make(map[string][]schema.Relation)
 	for rows.Next() {
-		var name, sourceColumn, targetSchema, targetTable, targetColumn, onUpdate, onDelete string
-		if err := rows.Scan(&name, &sourceColumn, &targetSchema, &targetTable, &targetColumn, &onUpdate, &onDelete); err != nil {
+		var tableName, name, sourceColumn, targetSchema, targetTable, targetColumn, onUpdate, onDelete string
+		if err := rows.Scan(&tableName, &name, &sourceColumn, &targetSchema, &targetTable, &targetColumn, &onUpdate, &onDelete); err != nil {
 			return nil, err
 		}
 
-		if current == nil || current.Name != name {
-			if current != nil {
-				finalizeRelation(current, primaryKey, indexes)
-				relations = append(relations, *current)
-			}
-			current = &schema.Relation{
+		tableRelations := relations[tableName]
+		if len(tableRelations) == 0 || tableRelations[len(tableRelations)-1].Name != name {
+			relation := schema.Relation{
 				Name:         name,
 				TargetSchema: targetSchema,
 				TargetTable:  targetTable,
 				OnUpdate:     onUpdate,
 				OnDelete:     onDelete,
 			}
-			if current.TargetSchema == e.schemaName {
-				current.TargetSchema = ""
+			if relation.TargetSchema == e.schemaName {
+				relation.TargetSchema = ""
 			}
+			tableRelations = append(tableRelations, relation)
 		}
+		current := &tableRelations[len(tableRelations)-1]
 		current.SourceColumns = append(current.SourceColumns, sourceColumn)
 		current.TargetColumns = append(current.TargetColumns, targetColumn)
-	}
-	if current != nil {
-		finalizeRelation(current, primaryKey, indexes)
-		relations = append(relations, *current)
+		relations[tableName] = tableRelations
 	}
 
 	return relations, rows.Err()
 }
 
-// extractIndexes extracts index information
-func (e *MySQLExtractor) extractIndexes(ctx context.Context, tableName string) ([]schema.Index, error) {
+// extractIndexes extracts index information by table
+func (e *MySQLExtractor) extractIndexes(ctx context.Context, names []string) (map[string][]schema.Index, error) {
 	query := `
 		SELECT
+			s.table_name,
 			s.index_name,
 			s.non_unique = 0 AS is_unique,
 			GROUP_CONCAT(s.column_name ORDER BY s.seq_in_index) AS column_names,
 			SUM(s.column_name IS NULL) > 0 AS has_expressions
 		FROM information_schema.statistics s
 		WHERE s.table_schema = ?
-			AND s.table_name = ?
-			AND s.index_name != 'PRIMARY'
-		GROUP BY s.index_name, s.non_unique
-		ORDER BY s.index_name
+			AND s.index_name != 'PRIMARY'%s
+		GROUP BY s.table_name, s.index_name, s.non_unique
+		ORDER BY s.table_name, s.index_name
 	`
+	query, args := e.filterQuery(query, "s.table_name", names)
 
-	rows, err := e.client.GetDB().QueryContext(ctx, query, e.schemaName, tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
-	var indexes []schema.Index
+	indexes := make(map[string][]schema.Index)
 	for rows.Next() {
+		var tableName string
 		var idx schema.Index
 		var isUnique int
 		var columnNames sql.NullString
 		var hasExpressions int
 
-		if err := rows.Scan(&idx.Name, &isUnique, &columnNames, &hasExpressions); err != nil {
+		if err := rows.Scan(&tableName, &idx.Name, &isUnique, &columnNames, &hasExpressions); err != nil {
 			return nil, err
 		}
 
@@ -347,8 +530,23 @@ func (e *MySQLExtractor) extractIndexes(ctx context.Context, tableName string) (
 			idx.Columns = strings.Split(columnNames.String, ",")
 		}
 
-		indexes = append(indexes, idx)
+		indexes[tableName] = append(indexes[tableName], idx)
 	}
 
 	return indexes, rows.Err()
 }
+
+// filterQuery fills in the %s of query with a condition restricting column
+// to names, and returns the query with its arguments. Without names every
+// relation of the schema is queried.
+func (e *MySQLExtractor) filterQuery(query, column string, names []string) (string, []any) {
+	args := []any{e.schemaName}
+	if len(names) == 0 {
+		return fmt.Sprintf(query, ""), args
+	}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	return fmt.Sprintf(query, fmt.Sprintf(" AND %s IN (%s)", column, placeholders)), args
+}
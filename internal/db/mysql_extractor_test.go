@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// countingConnector opens connections that answer every query with no rows
+// and count the round trips
+type countingConnector struct {
+	queries atomic.Int64
+}
+
+func (c *countingConnector) Connect(context.Context) (driver.Conn, error) {
+	return countingConn{queries: &c.queries}, nil
+}
+
+func (c *countingConnector) Driver() driver.Driver { return nil }
+
+type countingConn struct {
+	queries *atomic.Int64
+}
+
+func (c countingConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.queries.Add(1)
+	return emptyDriverRows{}, nil
+}
+
+func (countingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (countingConn) Close() error { return nil }
+
+func (countingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type emptyDriverRows struct{}
+
+func (emptyDriverRows) Columns() []string         { return nil }
+func (emptyDriverRows) Close() error              { return nil }
+func (emptyDriverRows) Next([]driver.Value) error { return io.EOF }
+
+// extractMySQLRoundTrips returns the number of queries that extracting the
+// given number of tables sends to the server
+func extractMySQLRoundTrips(t testing.TB, tables int) int64 {
+	connector := &countingConnector{}
+	sqlDB := sql.OpenDB(connector)
+	defer func() { _ = sqlDB.Close() }()
+
+	extractor := NewMySQLExtractor(NewMySQLClientFromDB(sqlDB), "shop")
+	s, err := extractor.ExtractSchema(context.Background(), tableNames(tables))
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	if len(s.Tables) != tables {
+		t.Fatalf("extracted %d tables, want %d", len(s.Tables), tables)
+	}
+	return connector.queries.Load()
+}
+
+func TestMySQLRoundTripsDoNotGrowWithTables(t *testing.T) {
+	one := extractMySQLRoundTrips(t, 1)
+	if many := extractMySQLRoundTrips(t, 500); many != one {
+		t.Errorf("extracting 500 tables took %d queries, want %d as for one table", many, one)
+	}
+}
+
+func BenchmarkMySQLExtractRoundTrips(b *testing.B) {
+	for _, tables := range []int{1, 10, 100, 1000} {
+		b.Run(fmt.Sprintf("tables=%d", tables), func(b *testing.B) {
+			var queries int64
+			for b.Loop() {
+				queries = extractMySQLRoundTrips(b, tables)
+			}
+			b.ReportMetric(float64(queries), "queries/op")
+		})
+	}
+}
+
+func TestMySQLFilterQueryRestrictsRequestedNames(t *testing.T) {
+	extractor := NewMySQLExtractor(nil, "shop")
+	query := "SELECT table_name FROM information_schema.tables WHERE table_schema = ?%s ORDER BY table_name"
+
+	filtered, args := extractor.filterQuery(query, "table_name", []string{"users", "orders"})
+	if want := "SELECT table_name FROM information_schema.tables WHERE table_schema = ? AND table_name IN (?, ?) ORDER BY table_name"; filtered != want {
+		t.Errorf("filtered query = %q, want %q", filtered, want)
+	}
+	if fmt.Sprint(args) != "[shop users orders]" {
+		t.Errorf("filtered arguments = %v, want [shop users orders]", args)
+	}
+
+	unfiltered, args := extractor.filterQuery(query, "table_name", nil)
+	if want := "SELECT table_name FROM information_schema.tables WHERE table_schema = ? ORDER BY table_name"; unfiltered != want {
+		t.Errorf("unfiltered query = %q, want %q", unfiltered, want)
+	}
+	if fmt.Sprint(args) != "[shop]" {
+		t.Errorf("unfiltered arguments = %v, want [shop]", args)
+	}
+}
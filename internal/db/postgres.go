@@ -5,35 +5,77 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// PostgresQuerier runs read queries. *pgx.Conn, *pgxpool.Pool, and pgx.Tx
+// implement it.
+type PostgresQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // PostgresClient manages the connection to PostgreSQL
 type PostgresClient struct {
-	conn *pgx.Conn
+	pool    *pgxpool.Pool // nil when the connection is owned by the caller
+	querier PostgresQuerier
+
+	// concurrency bounds how many catalog queries run at once. Each kind of
+	// metadata is one query for all tables, so values above the number of
+	// catalog queries have no effect.
+	concurrency int
 }
 
-// NewPostgresClient creates a new PostgreSQL client
-func NewPostgresClient(ctx context.Context, connString string) (*PostgresClient, error) {
-	conn, err := pgx.Connect(ctx, connString)
+// NewPostgresClient creates a new PostgreSQL client with a connection pool
+// large enough to run concurrency catalog queries at once. A concurrency of zero
+// uses DefaultConcurrency.
+func NewPostgresClient(ctx context.Context, connString string, concurrency int) (*PostgresClient, error) {
+	concurrency = resolveConcurrency(concurrency)
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	config.MaxConns = max(config.MaxConns, int32(min(concurrency, 1<<31-1)))
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
 	// Test the connection
-	if err := conn.Ping(ctx); err != nil {
-		_ = conn.Close(ctx)
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &PostgresClient{conn: conn}, nil
+	return &PostgresClient{pool: pool, querier: pool, concurrency: concurrency}, nil
 }
 
-// Close closes the database connection
+// NewPostgresClientFromQuerier creates a client that queries through an
+// existing connection. The caller keeps ownership: Close leaves it open.
+//
+// Catalog queries run concurrently only when querier is a *pgxpool.Pool;
+// a single connection or transaction runs one query at a time.
+func NewPostgresClientFromQuerier(querier PostgresQuerier, concurrency int) *PostgresClient {
+	if _, ok := querier.(*pgxpool.Pool); ok {
+		concurrency = resolveConcurrency(concurrency)
+	} else {
+		concurrency = 1
+	}
+	return &PostgresClient{querier: querier, concurrency: concurrency}
+}
+
+// Close closes the connection pool if the client opened it
 func (c *PostgresClient) Close(ctx context.Context) error {
-	return c.conn.Close(ctx)
+	if c.pool == nil {
+		return nil
+	}
+	c.pool.Close()
+	return nil
 }
 
-// GetConnection returns the underlying connection
-func (c *PostgresClient) GetConnection() *pgx.Conn {
-	return c.conn
+// GetConnection returns the connection that queries run on, which is a
+// transaction within ReadSnapshot
+func (c *PostgresClient) GetConnection() PostgresQuerier {
+	return c.querier
 }
@@ -3,8 +3,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 const varcharType = "varchar"
@@ -13,6 +14,10 @@ const varcharType = "varchar"
 type Extractor struct {
 	client *PostgresClient
 	schema string
+
+	// qualified keeps the schema on references within the extracted schema,
+	// for merging with other schemas
+	qualified bool
 }
 
 // NewExtractor creates a new schema extractor
@@ -26,7 +31,6 @@ func NewExtractor(client *PostgresClient, schemaName string) *Extractor {
 // ExtractSchema extracts the complete schema for specified tables
 // If tables is empty, extracts all tables in the schema
 func (e *Extractor) ExtractSchema(ctx context.Context, tables []string) (*schema.Schema, error) {
-	var extractedTables []schema.Table
 	var databaseVersion string
 	var databaseName string
 	// Version metadata is optional: compatible servers and proxies may not
@@ -38,13 +42,24 @@ func (e *Extractor) ExtractSchema(ctx context.Context, tables []string) (*schema
 	if err != nil {
 		return nil, fmt.Errorf("failed to get table names: %w", err)
 	}
+	viewCatalog, err := e.getViews(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get views: %w", err)
+	}
+	tableNames, views := splitRequestedViews(tables, tableNames, viewCatalog)
+
+	catalog, err := e.loadCatalog(ctx, tableNames, views)
+	if err != nil {
+		return nil, err
+	}
 
+	var extractedTables []schema.Table
 	for _, tableName := range tableNames {
-		table, err := e.extractTable(ctx, tableName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to extract table %s: %w", tableName, err)
-		}
-		extractedTables = append(extractedTables, *table)
+		extractedTables = append(extractedTables, catalog.table(tableName))
+	}
+	var extractedViews []schema.View
+	for _, metadata := range views {
+		extractedViews = append(extractedViews, catalog.view(metadata))
 	}
 
 	return &schema.Schema{
@@ -53,6 +68,7 @@ func (e *Extractor) ExtractSchema(ctx context.Context, tables []string) (*schema
 		DatabaseName:    databaseName,
 		SchemaName:      e.schema,
 		Tables:          extractedTables,
+		Views:           extractedViews,
 	}, nil
 }
 
@@ -87,40 +103,305 @@ func (e *Extractor) getTableNames(ctx context.Context, requestedTables []string)
 	return tables, rows.Err()
 }
 
-// extractTable extracts all information for a single table
-func (e *Extractor) extractTable(ctx context.Context, tableName string) (*schema.Table, error) {
-	table := &schema.Table{Name: tableName}
+// getViews returns the views and materialized views in the schema
+func (e *Extractor) getViews(ctx context.Context) ([]viewMetadata, error) {
+	query := `
+		SELECT viewname, false, definition
+		FROM pg_views
+		WHERE schemaname = $1
+		UNION ALL
+		SELECT matviewname, true, definition
+		FROM pg_matviews
+		WHERE schemaname = $1
+		ORDER BY 1
+	`
 
-	// Extract columns
-	columns, err := e.extractColumns(ctx, tableName)
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract columns: %w", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []viewMetadata
+	for rows.Next() {
+		var view viewMetadata
+		var definition *string
+		if err := rows.Scan(&view.name, &view.materialized, &definition); err != nil {
+			return nil, err
+		}
+		if definition != nil {
+			view.definition = *definition
+		}
+		views = append(views, view)
 	}
-	table.Columns = columns
 
-	// Extract primary key
-	pk, err := e.extractPrimaryKey(ctx, tableName)
+	return views, rows.Err()
+}
+
+// loadCatalog loads the metadata of the tables and views to extract. Each
+// kind of metadata is one query for all of them, and the queries run on up
+// to the client's concurrency connections at once.
+func (e *Extractor) loadCatalog(ctx context.Context, tableNames []string, views []viewMetadata) (*catalog, error) {
+	c := &catalog{}
+	viewNames := relationNames(nil, views)
+	var tableColumns, viewColumns map[string][]schema.Column
+	var tableTypes, viewTypes userDefinedTypes
+	var enumValues map[string][]string
+
+	err := runConcurrently(ctx, e.client.concurrency,
+		func(ctx context.Context) error {
+			var err error
+			if c.comments, err = e.extractComments(ctx, relationNames(tableNames, views)); err != nil {
+				return fmt.Errorf("failed to extract comments: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if tableColumns, tableTypes, err = e.extractColumns(ctx, tableNames); err != nil {
+				return fmt.Errorf("failed to extract columns: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if viewColumns, viewTypes, err = e.extractViewColumns(ctx, viewNames); err != nil {
+				return fmt.Errorf("failed to extract view columns: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if enumValues, err = e.extractEnumValuesMap(ctx); err != nil {
+				return fmt.Errorf("failed to extract enum values: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if c.primaryKeys, err = e.extractPrimaryKeys(ctx, tableNames); err != nil {
+				return fmt.Errorf("failed to extract primary keys: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if c.indexes, err = e.extractIndexes(ctx, tableNames); err != nil {
+				return fmt.Errorf("failed to extract indexes: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if c.checks, err = e.extractCheckConstraints(ctx, tableNames); err != nil {
+				return fmt.Errorf("failed to extract check constraints: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if c.relations, err = e.extractRelations(ctx, tableNames); err != nil {
+				return fmt.Errorf("failed to extract relations: %w", err)
+			}
+			return nil
+		},
+		func(ctx context.Context) error {
+			var err error
+			if c.dependencies, err = e.extractViewDependencies(ctx, viewNames); err != nil {
+				return fmt.Errorf("failed to extract view dependencies: %w", err)
+			}
+			return nil
+		},
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract primary key: %w", err)
+		return nil, err
 	}
-	table.PrimaryKey = pk
 
-	// Extract indexes
-	indexes, err := e.extractIndexes(ctx, tableName)
+	c.columns = tableColumns
+	for name, columns := range viewColumns {
+		c.columns[name] = columns
+		tableTypes[name] = viewTypes[name]
+	}
+	for name, columns := range c.columns {
+		for i := range columns {
+			if values, ok := enumValues[tableTypes[name][columns[i].Name]]; ok {
+				columns[i].EnumValues = values
+			}
+		}
+	}
+	return c, nil
+}
+
+// userDefinedTypes maps relation and column names to the schema-qualified
+// name of the column's user-defined type, which may live in another schema
+type userDefinedTypes map[string]map[string]string
+
+func (t userDefinedTypes) add(relationName, columnName, typeSchema, typeName string) {
+	if t[relationName] == nil {
+		t[relationName] = make(map[string]string)
+	}
+	t[relationName][columnName] = schema.QualifiedName(typeSchema, typeName)
+}
+
+// extractViewColumns extracts column information for views. Materialized
+// views are missing from information_schema.columns, so the catalog is read
+// directly for both view kinds.
+func (e *Extractor) extractViewColumns(ctx context.Context, viewNames []string) (map[string][]schema.Column, userDefinedTypes, error) {
+	columns := make(map[string][]schema.Column)
+	types := make(userDefinedTypes)
+	if len(viewNames) == 0 {
+		return columns, types, nil
+	}
+
+	query := `
+		SELECT
+			c.relname,
+			a.attname,
+			format_type(a.atttypid, a.atttypmod),
+			t.typtype = 'e',
+			type_namespace.nspname,
+			t.typname,
+			col_description(a.attrelid, a.attnum)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_type t ON t.oid = a.atttypid
+		JOIN pg_namespace type_namespace ON type_namespace.oid = t.typnamespace
+		WHERE n.nspname = $1
+			AND c.relname = ANY($2::name[])
+			AND a.attnum > 0
+			AND NOT a.attisdropped
+		ORDER BY c.relname, a.attnum
+	`
+
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, viewNames)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract indexes: %w", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var viewName, formattedType, typeSchema, typeName string
+		var isEnum bool
+		var comment *string
+		col := schema.Column{Nullable: true}
+
+		if err := rows.Scan(&viewName, &col.Name, &formattedType, &isEnum, &typeSchema, &typeName, &comment); err != nil {
+			return nil, nil, err
+		}
+
+		col.Type = normalizeFormattedPostgresType(formattedType)
+		if isEnum {
+			col.Type = typeName
+			types.add(viewName, col.Name, typeSchema, typeName)
+		}
+		if comment != nil {
+			col.Comment = *comment
+		}
+		columns[viewName] = append(columns[viewName], col)
 	}
-	table.Indexes = indexes
-	applyUniqueKeys(table)
 
-	// Extract relations after keys and indexes so cardinality can be inferred.
-	relations, err := e.extractRelations(ctx, tableName, pk, indexes)
+	return columns, types, rows.Err()
+}
+
+// extractViewDependencies returns the relations each view's rewrite rule reads from
+func (e *Extractor) extractViewDependencies(ctx context.Context, viewNames []string) (map[string][]string, error) {
+	dependencies := make(map[string][]string)
+	if len(viewNames) == 0 {
+		return dependencies, nil
+	}
+
+	query := `
+		SELECT DISTINCT dependent.relname, source_namespace.nspname, source.relname
+		FROM pg_depend d
+		JOIN pg_rewrite r ON r.oid = d.objid
+		JOIN pg_class dependent ON dependent.oid = r.ev_class
+		JOIN pg_namespace dependent_namespace ON dependent_namespace.oid = dependent.relnamespace
+		JOIN pg_class source ON source.oid = d.refobjid
+		JOIN pg_namespace source_namespace ON source_namespace.oid = source.relnamespace
+		WHERE d.classid = 'pg_rewrite'::regclass
+			AND d.refclassid = 'pg_class'::regclass
+			AND dependent_namespace.nspname = $1
+			AND dependent.relname = ANY($2::name[])
+			AND source.oid <> dependent.oid
+		ORDER BY dependent.relname, source_namespace.nspname, source.relname
+	`
+
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, viewNames)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract relations: %w", err)
+		return nil, err
 	}
-	table.Relations = relations
+	defer rows.Close()
 
-	return table, nil
+	for rows.Next() {
+		var viewName, sourceSchema, sourceName string
+		if err := rows.Scan(&viewName, &sourceSchema, &sourceName); err != nil {
+			return nil, err
+		}
+		if e.qualified || sourceSchema != e.schema {
+			sourceName = sourceSchema + "." + sourceName
+		}
+		dependencies[viewName] = append(dependencies[viewName], sourceName)
+	}
+
+	return dependencies, rows.Err()
+}
+
+// extractCheckConstraints extracts CHECK constraints with the columns they reference
+func (e *Extractor) extractCheckConstraints(ctx context.Context, tableNames []string) (map[string][]schema.CheckConstraint, error) {
+	checks := make(map[string][]schema.CheckConstraint)
+	if len(tableNames) == 0 {
+		return checks, nil
+	}
+
+	query := `
+		SELECT
+			c.relname,
+			con.conname,
+			pg_get_constraintdef(con.oid),
+			ARRAY(
+				SELECT a.attname
+				FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, position)
+				JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				ORDER BY k.position
+			)
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE con.contype = 'c'
+			AND n.nspname = $1
+			AND c.relname = ANY($2::name[])
+		ORDER BY c.relname, con.conname
+	`
+
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName string
+		var check schema.CheckConstraint
+		var definition string
+		if err := rows.Scan(&tableName, &check.Name, &definition, &check.Columns); err != nil {
+			return nil, err
+		}
+		check.Expression = postgresCheckExpression(definition)
+		checks[tableName] = append(checks[tableName], check)
+	}
+
+	return checks, rows.Err()
+}
+
+// postgresCheckExpression extracts the condition from a pg_get_constraintdef
+// result such as "CHECK ((price > (0)::numeric)) NOT VALID"
+func postgresCheckExpression(definition string) string {
+	definition = strings.TrimSpace(definition)
+	for _, option := range []string{" NOT VALID", " NO INHERIT"} {
+		definition = strings.TrimSuffix(definition, option)
+	}
+	return unwrapCheckExpression(definition)
 }
 
 // normalizePostgresType maps verbose SQL type names to commonly-used PostgreSQL equivalents
@@ -158,6 +439,44 @@ func normalizePostgresType(dataType, udtName string, charMaxLength *int) string
 	}
 }
 
+// timeZoneSuffixes shorten the time zone suffixes of format_type results,
+// tried in order so the result never depends on map iteration
+var timeZoneSuffixes = []struct {
+	suffix      string
+	shortSuffix string
+}{
+	{suffix: " with time zone", shortSuffix: "tz"},
+	{suffix: " without time zone", shortSuffix: ""},
+}
+
+// normalizeFormattedPostgresType applies the shortcuts of normalizePostgresType
+// to a format_type result, which also carries precision and array suffixes
+func normalizeFormattedPostgresType(formatted string) string {
+	base, isArray := strings.CutSuffix(formatted, "[]")
+
+	switch {
+	case strings.HasPrefix(base, "character varying"):
+		base = varcharType + strings.TrimPrefix(base, "character varying")
+	case strings.HasPrefix(base, "character"):
+		base = "char" + strings.TrimPrefix(base, "character")
+	}
+	for _, zone := range timeZoneSuffixes {
+		if name, ok := strings.CutSuffix(base, zone.suffix); ok {
+			typeName, precision, hasPrecision := strings.Cut(name, "(")
+			base = typeName + zone.shortSuffix
+			if hasPrecision {
+				base += "(" + precision
+			}
+			break
+		}
+	}
+
+	if isArray {
+		return base + "[]"
+	}
+	return base
+}
+
 // normalizeUdtName converts PostgreSQL internal type names to more readable forms
 func normalizeUdtName(udtName string) string {
 	switch udtName {
@@ -180,95 +499,206 @@ func normalizeUdtName(udtName string) string {
 	}
 }
 
-// extractColumns extracts column information for a table
-func (e *Extractor) extractColumns(ctx context.Context, tableName string) ([]schema.Column, error) {
+// extractColumns extracts column information for tables
+func (e *Extractor) extractColumns(ctx context.Context, tableNames []string) (map[string][]schema.Column, userDefinedTypes, error) {
+	columns := make(map[string][]schema.Column)
+	types := make(userDefinedTypes)
+	if len(tableNames) == 0 {
+		return columns, types, nil
+	}
+
 	query := `
 		SELECT
+			c.table_name,
 			c.column_name,
 			c.data_type,
 			c.is_nullable,
 			c.column_default,
+			c.udt_schema,
 			c.udt_name,
-			c.character_maximum_length
+			c.character_maximum_length,
+			col_description(format('%I.%I', c.table_schema, c.table_name)::regclass, c.ordinal_position),
+			c.is_generated,
+			c.generation_expression,
+			c.is_identity,
+			c.identity_generation
 		FROM information_schema.columns c
-		WHERE table_schema = $1 AND table_name = $2
-		ORDER BY ordinal_position
+		WHERE table_schema = $1 AND table_name = ANY($2::name[])
+		ORDER BY table_name, ordinal_position
 	`
 
-	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableName)
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableNames)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var columns []schema.Column
-	var enumTypes []string
-
-	// First pass: collect all columns and enum type names
+	hasGeneratedColumns := false
 	for rows.Next() {
+		var tableName string
 		var col schema.Column
 		var nullable string
 		var defaultVal *string
 		var dataType string
-		var udtName string
+		var udtSchema, udtName string
 		var charMaxLength *int
-
-		if err := rows.Scan(&col.Name, &dataType, &nullable, &defaultVal, &udtName, &charMaxLength); err != nil {
-			return nil, err
+		var comment *string
+		var isGenerated *string
+		var generationExpression *string
+		var isIdentity *string
+		var identityGeneration *string
+
+		if err := rows.Scan(&tableName, &col.Name, &dataType, &nullable, &defaultVal, &udtSchema, &udtName, &charMaxLength, &comment, &isGenerated, &generationExpression, &isIdentity, &identityGeneration); err != nil {
+			return nil, nil, err
 		}
 
 		col.Nullable = (nullable == "YES")
 		col.DefaultValue = defaultVal
+		col.Identity = postgresIdentity(isIdentity, identityGeneration, defaultVal)
+		if col.Identity != "" {
+			// Sequence defaults of serial columns are implementation details
+			col.DefaultValue = nil
+		}
+		if comment != nil {
+			col.Comment = *comment
+		}
+		if isGenerated != nil && *isGenerated == "ALWAYS" && generationExpression != nil {
+			col.Generated = &schema.GeneratedColumn{
+				Expression: unwrapSQLExpression(*generationExpression),
+				Stored:     true,
+			}
+			hasGeneratedColumns = true
+		}
 
 		// Use SQL standard type names, but apply PostgreSQL-specific shortcuts for verbose types
 		col.Type = normalizePostgresType(dataType, udtName, charMaxLength)
-
-		// If it's a USER-DEFINED type, remember it for later lookup of enum values
 		if dataType == "USER-DEFINED" {
-			enumTypes = append(enumTypes, udtName)
+			types.add(tableName, col.Name, udtSchema, udtName)
 		}
 
-		columns = append(columns, col)
+		columns[tableName] = append(columns[tableName], col)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	// The connection is needed again for the virtual columns
+	rows.Close()
 
-	// Second pass: fetch enum values for all USER-DEFINED types
-	if len(enumTypes) > 0 {
-		enumValuesMap, err := e.extractEnumValuesMap(ctx, enumTypes)
+	// Generated columns are stored unless declared VIRTUAL (PostgreSQL 18+)
+	if hasGeneratedColumns {
+		virtualColumns, err := e.extractVirtualColumns(ctx, tableNames)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-
-		// Update columns with enum values
-		for i := range columns {
-			if values, ok := enumValuesMap[columns[i].Type]; ok {
-				columns[i].EnumValues = values
+		for tableName, tableColumns := range columns {
+			for i := range tableColumns {
+				if tableColumns[i].Generated != nil && virtualColumns[tableName][tableColumns[i].Name] {
+					tableColumns[i].Generated.Stored = false
+				}
 			}
 		}
 	}
 
-	return columns, nil
+	return columns, types, nil
+}
+
+// postgresIdentity normalizes identity columns and sequence-backed serial columns
+func postgresIdentity(isIdentity, identityGeneration, defaultVal *string) string {
+	if isIdentity != nil && *isIdentity == "YES" {
+		if identityGeneration != nil && *identityGeneration == "ALWAYS" {
+			return schema.IdentityAlways
+		}
+		return schema.IdentityByDefault
+	}
+	if defaultVal != nil && strings.HasPrefix(*defaultVal, "nextval(") {
+		return schema.IdentityByDefault
+	}
+	return ""
+}
+
+// extractVirtualColumns returns the names of virtual generated columns by table
+func (e *Extractor) extractVirtualColumns(ctx context.Context, tableNames []string) (map[string]map[string]bool, error) {
+	query := `
+		SELECT c.relname, a.attname
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+			AND c.relname = ANY($2::name[])
+			AND a.attgenerated = 'v'
+			AND NOT a.attisdropped
+	`
+
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	virtualColumns := make(map[string]map[string]bool)
+	for rows.Next() {
+		var tableName, name string
+		if err := rows.Scan(&tableName, &name); err != nil {
+			return nil, err
+		}
+		if virtualColumns[tableName] == nil {
+			virtualColumns[tableName] = make(map[string]bool)
+		}
+		virtualColumns[tableName][name] = true
+	}
+
+	return virtualColumns, rows.Err()
 }
 
-// extractEnumValuesMap extracts enum values for multiple enum types at once
-func (e *Extractor) extractEnumValuesMap(ctx context.Context, enumTypeNames []string) (map[string][]string, error) {
-	if len(enumTypeNames) == 0 {
-		return make(map[string][]string), nil
+// extractComments extracts the COMMENT ON TABLE and COMMENT ON VIEW text of relations
+func (e *Extractor) extractComments(ctx context.Context, names []string) (map[string]string, error) {
+	comments := make(map[string]string)
+	if len(names) == 0 {
+		return comments, nil
+	}
+
+	query := `
+		SELECT c.relname, d.description
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_description d
+			ON d.objoid = c.oid
+			AND d.classoid = 'pg_class'::regclass
+			AND d.objsubid = 0
+		WHERE n.nspname = $1 AND c.relname = ANY($2::name[])
+	`
+
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, names)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, comment string
+		if err := rows.Scan(&name, &comment); err != nil {
+			return nil, err
+		}
+		comments[name] = comment
+	}
+
+	return comments, rows.Err()
+}
 
+// extractEnumValuesMap extracts the values of every enum type in the
+// database, keyed by schema-qualified type name. Columns may use enum types
+// from any schema, not only the one being extracted.
+func (e *Extractor) extractEnumValuesMap(ctx context.Context) (map[string][]string, error) {
 	query := `
-		SELECT t.typname, e.enumlabel
+		SELECT n.nspname, t.typname, e.enumlabel
 		FROM pg_type t
 		JOIN pg_enum e ON t.oid = e.enumtypid
 		JOIN pg_namespace n ON t.typnamespace = n.oid
-		WHERE n.nspname = $1 AND t.typname = ANY($2)
-		ORDER BY t.typname, e.enumsortorder
+		ORDER BY n.nspname, t.typname, e.enumsortorder
 	`
 
-	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, enumTypeNames)
+	rows, err := e.client.GetConnection().Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -276,55 +706,66 @@ func (e *Extractor) extractEnumValuesMap(ctx context.Context, enumTypeNames []st
 
 	result := make(map[string][]string)
 	for rows.Next() {
-		var typName, enumLabel string
-		if err := rows.Scan(&typName, &enumLabel); err != nil {
+		var typeSchema, typeName, enumLabel string
+		if err := rows.Scan(&typeSchema, &typeName, &enumLabel); err != nil {
 			return nil, err
 		}
-		result[typName] = append(result[typName], enumLabel)
+		qualifiedName := schema.QualifiedName(typeSchema, typeName)
+		result[qualifiedName] = append(result[qualifiedName], enumLabel)
 	}
 
 	return result, rows.Err()
 }
 
-// extractPrimaryKey extracts primary key columns
-func (e *Extractor) extractPrimaryKey(ctx context.Context, tableName string) ([]string, error) {
+// extractPrimaryKeys extracts primary key columns by table
+func (e *Extractor) extractPrimaryKeys(ctx context.Context, tableNames []string) (map[string][]string, error) {
+	primaryKeys := make(map[string][]string)
+	if len(tableNames) == 0 {
+		return primaryKeys, nil
+	}
+
 	query := `
-		SELECT column_name
-		FROM information_schema.key_column_usage
-		WHERE table_schema = $1
-			AND table_name = $2
-			AND constraint_name IN (
-				SELECT constraint_name
-				FROM information_schema.table_constraints
-				WHERE table_schema = $1
-					AND table_name = $2
-					AND constraint_type = 'PRIMARY KEY'
-			)
-		ORDER BY ordinal_position
+		SELECT kcu.table_name, kcu.column_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON kcu.constraint_schema = tc.constraint_schema
+			AND kcu.constraint_name = tc.constraint_name
+			AND kcu.table_schema = tc.table_schema
+			AND kcu.table_name = tc.table_name
+		WHERE tc.table_schema = $1
+			AND tc.table_name = ANY($2::name[])
+			AND tc.constraint_type = 'PRIMARY KEY'
+		ORDER BY kcu.table_name, kcu.ordinal_position
 	`
 
-	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableName)
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableNames)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var pk []string
 	for rows.Next() {
-		var colName string
-		if err := rows.Scan(&colName); err != nil {
+		var tableName, colName string
+		if err := rows.Scan(&tableName, &colName); err != nil {
 			return nil, err
 		}
-		pk = append(pk, colName)
+		primaryKeys[tableName] = append(primaryKeys[tableName], colName)
 	}
 
-	return pk, rows.Err()
+	return primaryKeys, rows.Err()
 }
 
-// extractRelations extracts foreign key relationships
-func (e *Extractor) extractRelations(ctx context.Context, tableName string, primaryKey []string, indexes []schema.Index) ([]schema.Relation, error) {
+// extractRelations extracts foreign key relationships by table. They are
+// finalized once the table's keys and indexes are known.
+func (e *Extractor) extractRelations(ctx context.Context, tableNames []string) (map[string][]schema.Relation, error) {
+	relations := make(map[string][]schema.Relation)
+	if len(tableNames) == 0 {
+		return relations, nil
+	}
+
 	query := `
 		SELECT
+			source_table.relname,
 			con.conname,
 			source_attribute.attname,
 			target_namespace.nspname,
@@ -347,46 +788,40 @@ func (e *Extractor) extractRelations(ctx context.Context, tableName string, prim
 			AND target_attribute.attnum = key_columns.target_attnum
 		WHERE con.contype = 'f'
 			AND source_namespace.nspname = $1
-			AND source_table.relname = $2
-		ORDER BY con.conname, key_columns.position
+			AND source_table.relname = ANY($2::name[])
+		ORDER BY source_table.relname, con.conname, key_columns.position
 	`
 
-	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableName)
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableNames)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var relations []schema.Relation
-	var current *schema.Relation
 	for rows.Next() {
-		var name, sourceColumn, targetSchema, targetTable, targetColumn, updateCode, deleteCode string
-		if err := rows.Scan(&name, &sourceColumn, &targetSchema, &targetTable, &targetColumn, &updateCode, &deleteCode); err != nil {
+		var tableName, name, sourceColumn, targetSchema, targetTable, targetColumn, updateCode, deleteCode string
+		if err := rows.Scan(&tableName, &name, &sourceColumn, &targetSchema, &targetTable, &targetColumn, &updateCode, &deleteCode); err != nil {
 			return nil, err
 		}
 
-		if current == nil || current.Name != name {
-			if current != nil {
-				finalizeRelation(current, primaryKey, indexes)
-				relations = append(relations, *current)
-			}
-			current = &schema.Relation{
+		tableRelations := relations[tableName]
+		if len(tableRelations) == 0 || tableRelations[len(tableRelations)-1].Name != name {
+			relation := schema.Relation{
 				Name:         name,
 				TargetSchema: targetSchema,
 				TargetTable:  targetTable,
 				OnUpdate:     postgresReferentialAction(updateCode),
 				OnDelete:     postgresReferentialAction(deleteCode),
 			}
-			if current.TargetSchema == e.schema {
-				current.TargetSchema = ""
+			if !e.qualified && relation.TargetSchema == e.schema {
+				relation.TargetSchema = ""
 			}
+			tableRelations = append(tableRelations, relation)
 		}
+		current := &tableRelations[len(tableRelations)-1]
 		current.SourceColumns = append(current.SourceColumns, sourceColumn)
 		current.TargetColumns = append(current.TargetColumns, targetColumn)
-	}
-	if current != nil {
-		finalizeRelation(current, primaryKey, indexes)
-		relations = append(relations, *current)
+		relations[tableName] = tableRelations
 	}
 
 	return relations, rows.Err()
@@ -409,10 +844,16 @@ func postgresReferentialAction(code string) string {
 	}
 }
 
-// extractIndexes extracts index information
-func (e *Extractor) extractIndexes(ctx context.Context, tableName string) ([]schema.Index, error) {
+// extractIndexes extracts index information by table
+func (e *Extractor) extractIndexes(ctx context.Context, tableNames []string) (map[string][]schema.Index, error) {
+	indexes := make(map[string][]schema.Index)
+	if len(tableNames) == 0 {
+		return indexes, nil
+	}
+
 	query := `
 		SELECT
+			t.relname AS table_name,
 			i.relname AS index_name,
 			ix.indisunique AS is_unique,
 			COALESCE(
@@ -431,25 +872,25 @@ func (e *Extractor) extractIndexes(ctx context.Context, tableName string) ([]sch
 		JOIN pg_namespace n ON n.oid = t.relnamespace
 		WHERE t.relkind IN ('r', 'p')
 			AND n.nspname = $1
-			AND t.relname = $2
+			AND t.relname = ANY($2::name[])
 			AND NOT ix.indisprimary
-		GROUP BY i.relname, ix.indisunique, (ix.indpred IS NOT NULL)
-		ORDER BY i.relname
+		GROUP BY t.relname, i.relname, ix.indisunique, (ix.indpred IS NOT NULL)
+		ORDER BY t.relname, i.relname
 	`
 
-	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableName)
+	rows, err := e.client.GetConnection().Query(ctx, query, e.schema, tableNames)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var indexes []schema.Index
 	for rows.Next() {
+		var tableName string
 		var idx schema.Index
-		if err := rows.Scan(&idx.Name, &idx.IsUnique, &idx.Columns, &idx.IsPartial, &idx.HasExpressions); err != nil {
+		if err := rows.Scan(&tableName, &idx.Name, &idx.IsUnique, &idx.Columns, &idx.IsPartial, &idx.HasExpressions); err != nil {
 			return nil, err
 		}
-		indexes = append(indexes, idx)
+		indexes[tableName] = append(indexes[tableName], idx)
 	}
 
 	return indexes, rows.Err()
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tordrt/llmschema/schema"
+)
+
+func TestNormalizeFormattedPostgresType(t *testing.T) {
+	tests := []struct {
+		formatted string
+		want      string
+	}{
+		{formatted: "integer", want: "integer"},
+		{formatted: "character varying(50)", want: "varchar(50)"},
+		{formatted: "character varying", want: "varchar"},
+		{formatted: "character(2)", want: "char(2)"},
+		{formatted: "timestamp without time zone", want: "timestamp"},
+		{formatted: "timestamp(3) with time zone", want: "timestamptz(3)"},
+		{formatted: "time with time zone", want: "timetz"},
+		{formatted: "time(6) without time zone[]", want: "time(6)[]"},
+		{formatted: "numeric(10,2)", want: "numeric(10,2)"},
+		{formatted: "text[]", want: "text[]"},
+		{formatted: "character varying(20)[]", want: "varchar(20)[]"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeFormattedPostgresType(tt.formatted); got != tt.want {
+			t.Errorf("normalizeFormattedPostgresType(%q) = %q, want %q", tt.formatted, got, tt.want)
+		}
+	}
+}
+
+func TestPostgresCheckExpression(t *testing.T) {
+	tests := []struct {
+		definition string
+		want       string
+	}{
+		{definition: "CHECK ((price > (0)::numeric))", want: "price > (0)::numeric"},
+		{definition: "CHECK (((starts_at < ends_at) OR (ends_at IS NULL)))", want: "(starts_at < ends_at) OR (ends_at IS NULL)"},
+		{definition: "CHECK ((quantity > 0)) NOT VALID", want: "quantity > 0"},
+		{definition: "CHECK ((a > 0)) NO INHERIT", want: "a > 0"},
+		{definition: "CHECK (((label)::text <> ')'::text))", want: "(label)::text <> ')'::text"},
+	}
+
+	for _, tt := range tests {
+		if got := postgresCheckExpression(tt.definition); got != tt.want {
+			t.Errorf("postgresCheckExpression(%q) = %q, want %q", tt.definition, got, tt.want)
+		}
+	}
+}
+
+func TestPostgresIdentity(t *testing.T) {
+	yes, no := "YES", "NO"
+	always, byDefault := "ALWAYS", "BY DEFAULT"
+	serialDefault := "nextval('users_id_seq'::regclass)"
+	literalDefault := "0"
+
+	tests := []struct {
+		name               string
+		isIdentity         *string
+		identityGeneration *string
+		defaultVal         *string
+		want               string
+	}{
+		{name: "identity always", isIdentity: &yes, identityGeneration: &always, want: schema.IdentityAlways},
+		{name: "identity by default", isIdentity: &yes, identityGeneration: &byDefault, want: schema.IdentityByDefault},
+		{name: "serial", isIdentity: &no, defaultVal: &serialDefault, want: schema.IdentityByDefault},
+		{name: "plain default", isIdentity: &no, defaultVal: &literalDefault, want: ""},
+		{name: "no metadata", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postgresIdentity(tt.isIdentity, tt.identityGeneration, tt.defaultVal); got != tt.want {
+				t.Errorf("postgresIdentity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitQualifiedTables(t *testing.T) {
+	schemaNames := []string{"public", "billing"}
+	tables := []string{"billing.invoices", "public.users", "audit.log", "accounts"}
+
+	qualified, unqualified := splitQualifiedTables("billing", schemaNames, tables)
+	if strings.Join(qualified, ",") != "invoices" {
+		t.Errorf("qualified = %v, want [invoices]", qualified)
+	}
+	// audit is not an extracted schema, so audit.log may be a table name containing a dot
+	if strings.Join(unqualified, ",") != "audit.log,accounts" {
+		t.Errorf("unqualified = %v, want [audit.log accounts]", unqualified)
+	}
+}
+
+// countingQuerier answers every query with no rows and counts the round trips
+type countingQuerier struct {
+	queries atomic.Int64
+}
+
+func (q *countingQuerier) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	q.queries.Add(1)
+	return emptyRows{}, nil
+}
+
+func (q *countingQuerier) QueryRow(context.Context, string, ...any) pgx.Row {
+	q.queries.Add(1)
+	return emptyRows{}
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Close()                                       {}
+func (emptyRows) Err() error                                   { return nil }
+func (emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (emptyRows) Next() bool                                   { return false }
+func (emptyRows) Scan(...any) error                            { return pgx.ErrNoRows }
+func (emptyRows) Values() ([]any, error)                       { return nil, nil }
+func (emptyRows) RawValues() [][]byte                          { return nil }
+func (emptyRows) Conn() *pgx.Conn                              { return nil }
+
+func tableNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("table_%d", i)
+	}
+	return names
+}
+
+// extractPostgresRoundTrips returns the number of queries that extracting
+// the given number of tables sends to the server
+func extractPostgresRoundTrips(t testing.TB, tables int) int64 {
+	querier := &countingQuerier{}
+	extractor := NewExtractor(NewPostgresClientFromQuerier(querier, 0), "public")
+	s, err := extractor.ExtractSchema(context.Background(), tableNames(tables))
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	if len(s.Tables) != tables {
+		t.Fatalf("extracted %d tables, want %d", len(s.Tables), tables)
+	}
+	return querier.queries.Load()
+}
+
+func TestPostgresRoundTripsDoNotGrowWithTables(t *testing.T) {
+	one := extractPostgresRoundTrips(t, 1)
+	if many := extractPostgresRoundTrips(t, 500); many != one {
+		t.Errorf("extracting 500 tables took %d queries, want %d as for one table", many, one)
+	}
+}
+
+func BenchmarkPostgresExtractRoundTrips(b *testing.B) {
+	for _, tables := range []int{1, 10, 100, 1000} {
+		b.Run(fmt.Sprintf("tables=%d", tables), func(b *testing.B) {
+			var queries int64
+			for b.Loop() {
+				queries = extractPostgresRoundTrips(b, tables)
+			}
+			b.ReportMetric(float64(queries), "queries/op")
+		})
+	}
+}
+
+func TestPostgresEnumValuesFromOtherSchemas(t *testing.T) {
+	tx := textTx{rowsFor: func(sql string) [][]any {
+		switch {
+		case strings.Contains(sql, "information_schema.columns"):
+			return [][]any{{"invoices", "status", "USER-DEFINED", "NO", nil, "public", "status", nil, nil, "NEVER", nil, "NO", nil}}
+		case strings.Contains(sql, "pg_enum"):
+			return [][]any{
+				{"billing", "status", "draft"},
+				{"public", "status", "open"},
+				{"public", "status", "paid"},
+			}
+		}
+		return nil
+	}}
+	extractor := NewExtractor(&PostgresClient{querier: tx, concurrency: 1}, "billing")
+
+	c, err := extractor.loadCatalog(context.Background(), []string{"invoices"}, nil)
+	if err != nil {
+		t.Fatalf("loadCatalog() failed: %v", err)
+	}
+	status := c.table("invoices").Columns[0]
+	if status.Type != "status" || !slices.Equal(status.EnumValues, []string{"open", "paid"}) {
+		t.Errorf("status = %+v, want the values of public.status", status)
+	}
+}
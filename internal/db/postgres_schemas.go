@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// ListPostgresSchemas returns the user-defined schemas in the database,
+// excluding pg_catalog, information_schema, and the pg_toast and pg_temp families
+func ListPostgresSchemas(ctx context.Context, client *PostgresClient) ([]string, error) {
+	query := `
+		SELECT nspname
+		FROM pg_namespace
+		WHERE nspname <> 'information_schema'
+			AND nspname NOT LIKE 'pg\_%'
+		ORDER BY nspname
+	`
+
+	rows, err := client.GetConnection().Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schemaNames []string
+	for rows.Next() {
+		var schemaName string
+		if err := rows.Scan(&schemaName); err != nil {
+			return nil, err
+		}
+		schemaNames = append(schemaNames, schemaName)
+	}
+
+	return schemaNames, rows.Err()
+}
+
+// ExtractPostgresSchemas extracts several schemas into one Schema. Tables and
+// views carry their schema name, and every relation names its target schema,
+// so references between the extracted schemas can be resolved.
+//
+// Requested tables may be qualified as schema.table to select them from one
+// schema; unqualified names select matching tables and views in every schema.
+func ExtractPostgresSchemas(ctx context.Context, client *PostgresClient, schemaNames []string, tables []string) (*schema.Schema, error) {
+	merged := &schema.Schema{Schemas: schemaNames}
+
+	for _, schemaName := range schemaNames {
+		extractor := &Extractor{client: client, schema: schemaName, qualified: true}
+
+		requested, err := extractor.requestedTablesInSchema(ctx, schemaNames, tables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tables in schema %s: %w", schemaName, err)
+		}
+		if len(tables) > 0 && len(requested) == 0 {
+			continue
+		}
+
+		extracted, err := extractor.ExtractSchema(ctx, requested)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract schema %s: %w", schemaName, err)
+		}
+		if merged.DatabaseType == "" {
+			merged.DatabaseType = extracted.DatabaseType
+			merged.DatabaseVersion = extracted.DatabaseVersion
+			merged.DatabaseName = extracted.DatabaseName
+		}
+
+		qualifySchema(extracted, schemaName)
+		merged.Tables = append(merged.Tables, extracted.Tables...)
+		merged.Views = append(merged.Views, extracted.Views...)
+	}
+
+	if merged.DatabaseType == "" {
+		merged.DatabaseType = "PostgreSQL"
+	}
+	return merged, nil
+}
+
+// requestedTablesInSchema selects the requested tables that belong to this
+// extractor's schema, with any schema qualifier removed
+func (e *Extractor) requestedTablesInSchema(ctx context.Context, schemaNames, tables []string) ([]string, error) {
+	if len(tables) == 0 {
+		return nil, nil
+	}
+
+	requested, unqualified := splitQualifiedTables(e.schema, schemaNames, tables)
+	if len(unqualified) == 0 {
+		return requested, nil
+	}
+
+	// Unqualified names are only extracted where they exist, since the
+	// extractor would document a table missing from its schema as empty
+	tableNames, err := e.getTableNames(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	views, err := e.getViews(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(tableNames)+len(views))
+	for _, name := range tableNames {
+		existing[name] = true
+	}
+	for _, view := range views {
+		existing[view.name] = true
+	}
+	for _, name := range unqualified {
+		if existing[name] {
+			requested = append(requested, name)
+		}
+	}
+	return requested, nil
+}
+
+// splitQualifiedTables returns the table names qualified with schemaName,
+// without their qualifier, and the names not qualified with any extracted schema
+func splitQualifiedTables(schemaName string, schemaNames, tables []string) (qualified, unqualified []string) {
+	extractedSchemas := make(map[string]bool, len(schemaNames))
+	for _, name := range schemaNames {
+		extractedSchemas[name] = true
+	}
+
+	for _, table := range tables {
+		if tableSchema, name, ok := strings.Cut(table, "."); ok && extractedSchemas[tableSchema] {
+			if tableSchema == schemaName {
+				qualified = append(qualified, name)
+			}
+			continue
+		}
+		unqualified = append(unqualified, table)
+	}
+	return qualified, unqualified
+}
+
+// qualifySchema records schemaName on the extracted tables and views
+func qualifySchema(s *schema.Schema, schemaName string) {
+	for i := range s.Tables {
+		s.Tables[i].Schema = schemaName
+	}
+	for i := range s.Views {
+		s.Views[i].Schema = schemaName
+	}
+}
@@ -3,7 +3,7 @@ package db
 import (
 	"slices"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 func relationCardinality(sourceColumns, primaryKey []string, indexes []schema.Index) string {
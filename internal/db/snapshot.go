@@ -0,0 +1,281 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SQLQuerier runs read queries. *sql.DB, *sql.Conn, and *sql.Tx implement it.
+type SQLQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// postgresSnapshotOptions start the transactions that extraction reads in
+var postgresSnapshotOptions = pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+
+// postgresBeginner starts transactions. *pgx.Conn and *pgxpool.Pool implement
+// it; pgx.Tx does not, because its transactions are savepoints.
+type postgresBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// ReadSnapshot calls fn with a client whose queries all read one consistent
+// snapshot of the database, in a REPEATABLE READ READ ONLY transaction, and
+// returns the time the snapshot was taken.
+//
+// When the client has a pool and a concurrency above one, further pooled
+// connections import the snapshot with SET TRANSACTION SNAPSHOT, so catalog
+// queries still run concurrently. When it queries through a transaction of
+// the caller's, fn reads in that transaction.
+func (c *PostgresClient) ReadSnapshot(ctx context.Context, fn func(snapshot *PostgresClient) error) (time.Time, error) {
+	beginner, ok := c.querier.(postgresBeginner)
+	if !ok {
+		snapshotTime, err := postgresSnapshotTime(ctx, c.querier)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return snapshotTime, fn(c)
+	}
+
+	tx, err := beginner.BeginTx(ctx, postgresSnapshotOptions)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin read-only transaction: %w", err)
+	}
+	// Rolling back a read-only transaction only releases it
+	defer func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }()
+
+	// The first query takes the snapshot
+	snapshotTime, err := postgresSnapshotTime(ctx, tx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	snapshot := &PostgresClient{querier: tx, concurrency: 1}
+	if pool, ok := c.querier.(*pgxpool.Pool); ok && c.concurrency > 1 {
+		shared := sharePostgresSnapshot(ctx, pool, tx, min(c.concurrency, int(pool.Config().MaxConns)))
+		defer shared.rollback(context.WithoutCancel(ctx))
+		snapshot = &PostgresClient{querier: shared, concurrency: cap(shared.txs)}
+	}
+	return snapshotTime, fn(snapshot)
+}
+
+// postgresSnapshotTime returns the start time of the transaction that
+// querier reads in
+func postgresSnapshotTime(ctx context.Context, querier PostgresQuerier) (time.Time, error) {
+	var snapshotTime time.Time
+	if err := querier.QueryRow(ctx, "SELECT now()").Scan(&snapshotTime); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read snapshot time: %w", err)
+	}
+	return snapshotTime.UTC(), nil
+}
+
+// sharedPostgresSnapshot runs each query in one of several transactions that
+// read the same exported snapshot
+type sharedPostgresSnapshot struct {
+	txs chan pgx.Tx
+	all []pgx.Tx
+}
+
+// sharePostgresSnapshot exports the snapshot of tx and imports it into up to
+// n-1 further transactions from pool. On servers that cannot export
+// snapshots, queries read through tx alone.
+func sharePostgresSnapshot(ctx context.Context, pool *pgxpool.Pool, tx pgx.Tx, n int) *sharedPostgresSnapshot {
+	txs := []pgx.Tx{tx}
+
+	var snapshotID string
+	// A savepoint keeps tx usable when the export fails
+	if export, err := tx.Begin(ctx); err == nil {
+		if err := export.QueryRow(ctx, "SELECT pg_export_snapshot()").Scan(&snapshotID); err != nil {
+			_ = export.Rollback(ctx)
+			snapshotID = ""
+		} else {
+			_ = export.Commit(ctx)
+		}
+	}
+
+	for snapshotID != "" && len(txs) < n {
+		imported, err := pool.BeginTx(ctx, postgresSnapshotOptions)
+		if err != nil {
+			break
+		}
+		if _, err := imported.Exec(ctx, "SET TRANSACTION SNAPSHOT '"+strings.ReplaceAll(snapshotID, "'", "''")+"'"); err != nil {
+			_ = imported.Rollback(ctx)
+			break
+		}
+		txs = append(txs, imported)
+	}
+
+	shared := &sharedPostgresSnapshot{txs: make(chan pgx.Tx, len(txs)), all: txs}
+	for _, tx := range txs {
+		shared.txs <- tx
+	}
+	return shared
+}
+
+// rollback ends the transactions that imported the snapshot; the exporting
+// transaction is ended by its owner
+func (s *sharedPostgresSnapshot) rollback(ctx context.Context) {
+	for _, tx := range s.all[1:] {
+		_ = tx.Rollback(ctx)
+	}
+}
+
+// acquire waits for a transaction that is not running a query
+func (s *sharedPostgresSnapshot) acquire(ctx context.Context) (pgx.Tx, error) {
+	select {
+	case tx := <-s.txs:
+		return tx, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *sharedPostgresSnapshot) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		s.txs <- tx
+		return nil, err
+	}
+	return &sharedSnapshotRows{Rows: rows, release: func() { s.txs <- tx }}, nil
+}
+
+// QueryRow reads the first row before returning it, so the transaction is
+// released even when the row is never scanned
+func (s *sharedPostgresSnapshot) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := s.Query(ctx, sql, args...)
+	if err != nil {
+		return errorRow{err: err}
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return errorRow{err: err}
+		}
+		return errorRow{err: pgx.ErrNoRows}
+	}
+	// Raw values are only valid until the rows move on
+	values := make([][]byte, len(rows.RawValues()))
+	for i, value := range rows.RawValues() {
+		values[i] = bytes.Clone(value)
+	}
+	row := bufferedRow{
+		typeMap: pgtype.NewMap(),
+		fields:  slices.Clone(rows.FieldDescriptions()),
+		values:  values,
+	}
+	if conn := rows.Conn(); conn != nil {
+		row.typeMap = conn.TypeMap()
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return errorRow{err: err}
+	}
+	return row
+}
+
+// sharedSnapshotRows returns its transaction once read to the end or closed
+type sharedSnapshotRows struct {
+	pgx.Rows
+	release func()
+	once    sync.Once
+}
+
+func (r *sharedSnapshotRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+func (r *sharedSnapshotRows) Close() {
+	r.Rows.Close()
+	r.once.Do(r.release)
+}
+
+// bufferedRow is a row read ahead of its scan
+type bufferedRow struct {
+	typeMap *pgtype.Map
+	fields  []pgconn.FieldDescription
+	values  [][]byte
+}
+
+func (r bufferedRow) Scan(dest ...any) error {
+	return pgx.ScanRow(r.typeMap, r.fields, r.values, dest...)
+}
+
+type errorRow struct {
+	err error
+}
+
+func (r errorRow) Scan(...any) error {
+	return r.err
+}
+
+// ReadSnapshot calls fn with a client whose queries all read one consistent
+// snapshot of the database, and returns the time the snapshot was taken. The
+// snapshot is a REPEATABLE READ transaction started WITH CONSISTENT SNAPSHOT
+// on one connection, so queries run one at a time.
+func (c *MySQLClient) ReadSnapshot(ctx context.Context, fn func(snapshot *MySQLClient) error) (time.Time, error) {
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// SET TRANSACTION applies to the next transaction only
+	if _, err := conn.ExecContext(ctx, "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ"); err != nil {
+		return time.Time{}, fmt.Errorf("failed to set isolation level: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY"); err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin read-only transaction: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK") }()
+
+	var micros int64
+	if err := conn.QueryRowContext(ctx, "SELECT CAST(UNIX_TIMESTAMP(NOW(6)) * 1000000 AS UNSIGNED)").Scan(&micros); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read snapshot time: %w", err)
+	}
+
+	snapshot := &MySQLClient{db: c.db, querier: conn, borrowed: true}
+	return time.UnixMicro(micros).UTC(), fn(snapshot)
+}
+
+// ReadSnapshot calls fn with a client whose queries all read one consistent
+// snapshot of the database, in a read transaction, and returns the time the
+// snapshot was taken.
+func (c *SQLiteClient) ReadSnapshot(ctx context.Context, fn func(snapshot *SQLiteClient) error) (time.Time, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin read transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// A deferred transaction takes its snapshot at the first read
+	var objects int
+	if err := tx.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master").Scan(&objects); err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin read transaction: %w", err)
+	}
+	snapshotTime := time.Now().UTC()
+
+	snapshot := &SQLiteClient{db: c.db, querier: tx, databaseName: c.databaseName, borrowed: true}
+	return snapshotTime, fn(snapshot)
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestSQLiteReadSnapshotIgnoresConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewSQLiteClient(ctx, filepath.Join(t.TempDir(), "snapshot.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	// In WAL mode a writer does not wait for readers, so the migration below
+	// runs while the snapshot is open
+	for _, statement := range []string{
+		`PRAGMA journal_mode = WAL`,
+		`CREATE TABLE users (id INTEGER PRIMARY KEY)`,
+	} {
+		if _, err := client.GetDB().ExecContext(ctx, statement); err != nil {
+			t.Fatalf("executing %q failed: %v", statement, err)
+		}
+	}
+
+	before := time.Now().UTC()
+	var tableNames []string
+	snapshotTime, err := client.ReadSnapshot(ctx, func(snapshot *SQLiteClient) error {
+		if _, err := client.GetDB().ExecContext(ctx, `CREATE TABLE orders (id INTEGER PRIMARY KEY)`); err != nil {
+			return err
+		}
+		s, err := NewSQLiteExtractor(snapshot).ExtractSchema(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, table := range s.Tables {
+			tableNames = append(tableNames, table.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadSnapshot() failed: %v", err)
+	}
+
+	if len(tableNames) != 1 || tableNames[0] != "users" {
+		t.Errorf("tables = %v, want [users] as before the concurrent CREATE TABLE", tableNames)
+	}
+	if snapshotTime.Before(before) || snapshotTime.After(time.Now().UTC()) || snapshotTime.Location() != time.UTC {
+		t.Errorf("snapshot time = %v, want a UTC time during ReadSnapshot", snapshotTime)
+	}
+}
+
+// textTx answers each query with the text rows that rowsFor returns for its
+// SQL; nil values are NULL
+type textTx struct {
+	pgx.Tx
+	rowsFor func(sql string) [][]any
+}
+
+func (tx textTx) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	return &textRows{rows: tx.rowsFor(sql), current: -1}, nil
+}
+
+type textRows struct {
+	emptyRows
+	rows    [][]any
+	current int
+}
+
+func (r *textRows) Next() bool {
+	r.current++
+	return r.current < len(r.rows)
+}
+
+func (r *textRows) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(r.rows[r.current]))
+	for i := range fields {
+		fields[i] = pgconn.FieldDescription{DataTypeOID: pgtype.TextOID, Format: pgtype.TextFormatCode}
+	}
+	return fields
+}
+
+func (r *textRows) RawValues() [][]byte {
+	values := make([][]byte, len(r.rows[r.current]))
+	for i, value := range r.rows[r.current] {
+		if value != nil {
+			values[i] = []byte(value.(string))
+		}
+	}
+	return values
+}
+
+func (r *textRows) Scan(dest ...any) error {
+	return pgx.ScanRow(pgtype.NewMap(), r.FieldDescriptions(), r.RawValues(), dest...)
+}
+
+// singleTxSnapshot shares one transaction, as when a server cannot export
+// snapshots to further connections
+func singleTxSnapshot(tx pgx.Tx) *sharedPostgresSnapshot {
+	shared := &sharedPostgresSnapshot{txs: make(chan pgx.Tx, 1), all: []pgx.Tx{tx}}
+	shared.txs <- tx
+	return shared
+}
+
+func TestSharedPostgresSnapshotReleasesUnscannedRows(t *testing.T) {
+	shared := singleTxSnapshot(textTx{rowsFor: func(string) [][]any { return [][]any{{"snapshot"}} }})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// With a single transaction, a row that held on to it until scanned
+	// would block the next query
+	_ = shared.QueryRow(ctx, "SELECT 'snapshot'")
+
+	var value string
+	if err := shared.QueryRow(ctx, "SELECT 'snapshot'").Scan(&value); err != nil {
+		t.Fatalf("QueryRow() after an unscanned row failed: %v", err)
+	}
+	if value != "snapshot" {
+		t.Errorf("value = %q, want %q", value, "snapshot")
+	}
+}
+
+func TestSharedPostgresSnapshotReadsGeneratedColumnsWithOneTransaction(t *testing.T) {
+	shared := singleTxSnapshot(textTx{rowsFor: func(sql string) [][]any {
+		if strings.Contains(sql, "information_schema.columns") {
+			return [][]any{{"users", "full_name", "text", "YES", nil, "pg_catalog", "text", nil, nil, "ALWAYS", "(first || last)", "NO", nil}}
+		}
+		return nil
+	}})
+	extractor := NewExtractor(&PostgresClient{querier: shared, concurrency: 1}, "public")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Looking up virtual columns must not wait for the transaction still
+	// held by the column rows
+	columns, _, err := extractor.extractColumns(ctx, []string{"users"})
+	if err != nil {
+		t.Fatalf("extractColumns() failed: %v", err)
+	}
+	if len(columns["users"]) != 1 || columns["users"][0].Generated == nil || !columns["users"][0].Generated.Stored {
+		t.Errorf("columns = %+v, want one stored generated column", columns["users"])
+	}
+}
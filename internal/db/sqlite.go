@@ -13,7 +13,9 @@ import (
 // SQLiteClient manages the connection to SQLite
 type SQLiteClient struct {
 	db           *sql.DB
+	querier      SQLQuerier
 	databaseName string
+	borrowed     bool // owned by the caller and never closed
 }
 
 // NewSQLiteClient creates a new SQLite client
@@ -37,12 +39,30 @@ func NewSQLiteClient(ctx context.Context, path string) (*SQLiteClient, error) {
 
 	return &SQLiteClient{
 		db:           db,
+		querier:      db,
 		databaseName: sqliteDatabaseName(path),
 	}, nil
 }
 
-// Close closes the database connection
+// NewSQLiteClientFromDB creates a client that queries through an existing
+// connection pool. The caller keeps ownership: Close leaves it open.
+func NewSQLiteClientFromDB(ctx context.Context, db *sql.DB) *SQLiteClient {
+	// The file name is display metadata, so a failed lookup leaves it empty
+	var path string
+	_ = db.QueryRowContext(ctx, "SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&path)
+	return &SQLiteClient{
+		db:           db,
+		querier:      db,
+		databaseName: sqliteDatabaseName(path),
+		borrowed:     true,
+	}
+}
+
+// Close closes the database connection if the client opened it
 func (c *SQLiteClient) Close() error {
+	if c.borrowed {
+		return nil
+	}
 	return c.db.Close()
 }
 
@@ -51,6 +71,12 @@ func (c *SQLiteClient) GetDB() *sql.DB {
 	return c.db
 }
 
+// GetConnection returns the connection that queries run on, which is a
+// transaction within ReadSnapshot
+func (c *SQLiteClient) GetConnection() SQLQuerier {
+	return c.querier
+}
+
 // GetDatabaseName returns a display-safe name for the main SQLite database.
 func (c *SQLiteClient) GetDatabaseName() string {
 	return c.databaseName
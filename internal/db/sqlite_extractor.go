@@ -4,10 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 // SQLiteExtractor handles schema extraction from SQLite
@@ -29,12 +30,17 @@ func (e *SQLiteExtractor) ExtractSchema(ctx context.Context, tables []string) (*
 	var databaseVersion string
 	// Version metadata is optional: compatible drivers may not support this
 	// query even when schema extraction itself works.
-	_ = e.client.GetDB().QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&databaseVersion)
+	_ = e.client.GetConnection().QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&databaseVersion)
 
 	tableNames, err := e.getTableNames(ctx, tables)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get table names: %w", err)
 	}
+	viewCatalog, err := e.getViews(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get views: %w", err)
+	}
+	tableNames, views := splitRequestedViews(tables, tableNames, viewCatalog)
 
 	for _, tableName := range tableNames {
 		table, err := e.extractTable(ctx, tableName)
@@ -44,11 +50,27 @@ func (e *SQLiteExtractor) ExtractSchema(ctx context.Context, tables []string) (*
 		extractedTables = append(extractedTables, *table)
 	}
 
+	var extractedViews []schema.View
+	if len(views) > 0 {
+		relationNames, err := e.getRelationNames(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relation names: %w", err)
+		}
+		for _, metadata := range views {
+			view, err := e.extractView(ctx, metadata, relationNames)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extract view %s: %w", metadata.name, err)
+			}
+			extractedViews = append(extractedViews, *view)
+		}
+	}
+
 	return &schema.Schema{
 		DatabaseType:    "SQLite",
 		DatabaseVersion: databaseVersion,
 		DatabaseName:    e.client.GetDatabaseName(),
 		Tables:          extractedTables,
+		Views:           extractedViews,
 	}, nil
 }
 
@@ -65,7 +87,7 @@ func (e *SQLiteExtractor) getTableNames(ctx context.Context, requestedTables []s
 		ORDER BY name
 	`
 
-	rows, err := e.client.GetDB().QueryContext(ctx, query)
+	rows, err := e.client.GetConnection().QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +105,85 @@ func (e *SQLiteExtractor) getTableNames(ctx context.Context, requestedTables []s
 	return tableList, rows.Err()
 }
 
+// getViews returns the views in the database
+func (e *SQLiteExtractor) getViews(ctx context.Context) ([]viewMetadata, error) {
+	query := `
+		SELECT name, sql
+		FROM sqlite_master
+		WHERE type = 'view'
+		ORDER BY name
+	`
+
+	rows, err := e.client.GetConnection().QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var views []viewMetadata
+	for rows.Next() {
+		var view viewMetadata
+		var createSQL sql.NullString
+		if err := rows.Scan(&view.name, &createSQL); err != nil {
+			return nil, err
+		}
+		view.definition = sqliteViewQuery(createSQL.String)
+		views = append(views, view)
+	}
+
+	return views, rows.Err()
+}
+
+// getRelationNames maps the lowercased names of all tables and views to
+// their declared names, since SQLite resolves identifiers case-insensitively
+func (e *SQLiteExtractor) getRelationNames(ctx context.Context) (map[string]string, error) {
+	query := `
+		SELECT name
+		FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+	`
+
+	rows, err := e.client.GetConnection().QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	names := make(map[string]string)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[strings.ToLower(name)] = name
+	}
+
+	return names, rows.Err()
+}
+
+// extractView extracts columns and dependencies for a single view. SQLite
+// does not record view dependencies, so they are read from the view's query.
+func (e *SQLiteExtractor) extractView(ctx context.Context, metadata viewMetadata, relationNames map[string]string) (*schema.View, error) {
+	columns, err := e.extractColumns(ctx, metadata.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract columns: %w", err)
+	}
+
+	seen := map[string]bool{metadata.name: true}
+	var dependencies []string
+	for _, reference := range sqliteQueryRelations(metadata.definition) {
+		name, ok := relationNames[strings.ToLower(reference)]
+		if ok && !seen[name] {
+			seen[name] = true
+			dependencies = append(dependencies, name)
+		}
+	}
+	sort.Strings(dependencies)
+
+	view := newView(metadata, columns, dependencies)
+	return &view, nil
+}
+
 // extractTable extracts all information for a single table
 func (e *SQLiteExtractor) extractTable(ctx context.Context, tableName string) (*schema.Table, error) {
 	table := &schema.Table{Name: tableName}
@@ -109,6 +210,14 @@ func (e *SQLiteExtractor) extractTable(ctx context.Context, tableName string) (*
 	table.Indexes = indexes
 	applyUniqueKeys(table)
 
+	// CHECK constraints and rowid aliases are only visible in the table definition
+	sqlText, err := e.getTableSQL(ctx, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table definition: %w", err)
+	}
+	applyCheckConstraints(table, sqliteCheckConstraints(sqlText, columns))
+	markSQLiteRowidAlias(table, sqlText)
+
 	// Extract relations after keys and indexes so cardinality can be inferred.
 	relations, err := e.extractRelations(ctx, tableName, pk, indexes)
 	if err != nil {
@@ -119,24 +228,36 @@ func (e *SQLiteExtractor) extractTable(ctx context.Context, tableName string) (*
 	return table, nil
 }
 
-// extractColumns extracts column information for a table
+// pragma_table_xinfo hidden values for generated columns
+const (
+	sqliteHiddenVirtualTableColumn = 1
+	sqliteVirtualGeneratedColumn   = 2
+	sqliteStoredGeneratedColumn    = 3
+)
+
+// extractColumns extracts column information for a table or view.
+// pragma_table_xinfo is used because pragma_table_info omits generated columns.
 func (e *SQLiteExtractor) extractColumns(ctx context.Context, tableName string) ([]schema.Column, error) {
-	rows, err := e.client.GetDB().QueryContext(ctx, "SELECT * FROM pragma_table_info(?)", tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, "SELECT * FROM pragma_table_xinfo(?)", tableName)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
 	var columns []schema.Column
+	hasGeneratedColumns := false
 	for rows.Next() {
 		var cid int
 		var name, colType string
-		var notNull, pk int
+		var notNull, pk, hidden int
 		var defaultValue sql.NullString
 
-		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk, &hidden); err != nil {
 			return nil, err
 		}
+		if hidden == sqliteHiddenVirtualTableColumn {
+			continue
+		}
 
 		col := schema.Column{
 			Name:     name,
@@ -147,24 +268,31 @@ func (e *SQLiteExtractor) extractColumns(ctx context.Context, tableName string)
 		if defaultValue.Valid {
 			col.DefaultValue = &defaultValue.String
 		}
+		if hidden == sqliteVirtualGeneratedColumn || hidden == sqliteStoredGeneratedColumn {
+			col.Generated = &schema.GeneratedColumn{Stored: hidden == sqliteStoredGeneratedColumn}
+			hasGeneratedColumns = true
+		}
 
 		columns = append(columns, col)
 	}
-
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-
-	// Extract CHECK constraints
-	checkConstraints, err := e.extractCheckConstraints(ctx, tableName)
-	if err != nil {
+	if err := rows.Close(); err != nil {
 		return nil, err
 	}
 
-	// Apply CHECK constraints to columns
-	for i := range columns {
-		if check, ok := checkConstraints[columns[i].Name]; ok {
-			columns[i].CheckConstraint = &check
+	// The generating expressions are only recorded in the table definition
+	if hasGeneratedColumns {
+		sqlText, err := e.getTableSQL(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
+		expressions := sqliteGeneratedExpressions(sqlText)
+		for i := range columns {
+			if columns[i].Generated != nil {
+				columns[i].Generated.Expression = expressions[strings.ToLower(columns[i].Name)]
+			}
 		}
 	}
 
@@ -173,7 +301,7 @@ func (e *SQLiteExtractor) extractColumns(ctx context.Context, tableName string)
 
 // extractPrimaryKey extracts primary key columns
 func (e *SQLiteExtractor) extractPrimaryKey(ctx context.Context, tableName string) ([]string, error) {
-	rows, err := e.client.GetDB().QueryContext(ctx, "SELECT * FROM pragma_table_info(?)", tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, "SELECT * FROM pragma_table_info(?)", tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -213,7 +341,7 @@ func (e *SQLiteExtractor) extractPrimaryKey(ctx context.Context, tableName strin
 
 // extractRelations extracts foreign key relationships
 func (e *SQLiteExtractor) extractRelations(ctx context.Context, tableName string, primaryKey []string, indexes []schema.Index) ([]schema.Relation, error) {
-	rows, err := e.client.GetDB().QueryContext(ctx, "SELECT * FROM pragma_foreign_key_list(?)", tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, "SELECT * FROM pragma_foreign_key_list(?)", tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -277,7 +405,7 @@ func hasEmptyColumn(columns []string) bool {
 
 // extractIndexes extracts index information
 func (e *SQLiteExtractor) extractIndexes(ctx context.Context, tableName string) ([]schema.Index, error) {
-	rows, err := e.client.GetDB().QueryContext(ctx, "SELECT * FROM pragma_index_list(?)", tableName)
+	rows, err := e.client.GetConnection().QueryContext(ctx, "SELECT * FROM pragma_index_list(?)", tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -319,7 +447,7 @@ func (e *SQLiteExtractor) extractIndexes(ctx context.Context, tableName string)
 	var indexes []schema.Index
 	for _, item := range metadata {
 		// Get index columns
-		indexRows, err := e.client.GetDB().QueryContext(ctx, "SELECT * FROM pragma_index_info(?)", item.name)
+		indexRows, err := e.client.GetConnection().QueryContext(ctx, "SELECT * FROM pragma_index_info(?)", item.name)
 		if err != nil {
 			return nil, err
 		}
@@ -358,31 +486,6 @@ func (e *SQLiteExtractor) extractIndexes(ctx context.Context, tableName string)
 	return indexes, nil
 }
 
-// extractCheckConstraints extracts CHECK constraints from the table definition
-func (e *SQLiteExtractor) extractCheckConstraints(ctx context.Context, tableName string) (map[string]string, error) {
-	sqlText, err := e.getTableSQL(ctx, tableName)
-	if err != nil {
-		return nil, err
-	}
-
-	checkConstraints := make(map[string]string)
-	lines := strings.Split(sqlText, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if e.shouldSkipLine(line) {
-			continue
-		}
-
-		if constraint := e.parseCheckConstraintFromLine(line); constraint != nil {
-			checkConstraints[constraint.columnName] = constraint.expression
-		}
-	}
-
-	return checkConstraints, nil
-}
-
 // getTableSQL retrieves the CREATE TABLE SQL for a given table
 func (e *SQLiteExtractor) getTableSQL(ctx context.Context, tableName string) (string, error) {
 	query := `
@@ -392,85 +495,247 @@ func (e *SQLiteExtractor) getTableSQL(ctx context.Context, tableName string) (st
 	`
 
 	var sqlText string
-	err := e.client.GetDB().QueryRowContext(ctx, query, tableName).Scan(&sqlText)
+	err := e.client.GetConnection().QueryRowContext(ctx, query, tableName).Scan(&sqlText)
 	return sqlText, err
 }
 
-// shouldSkipLine determines if a line should be skipped during CHECK constraint parsing
-func (e *SQLiteExtractor) shouldSkipLine(line string) bool {
-	if line == "" {
-		return true
+// sqliteCheckConstraints parses the CHECK constraints of a CREATE TABLE
+// statement. Column constraints apply to their column; table constraints
+// apply to the columns their expression refers to.
+func sqliteCheckConstraints(createSQL string, columns []schema.Column) []schema.CheckConstraint {
+	var checks []schema.CheckConstraint
+	for _, definition := range sqliteTableDefinitions(tokenizeSQL(createSQL)) {
+		columnName := ""
+		if !isSQLiteTableConstraint(definition[0]) {
+			columnName = definition[0].identifier()
+		}
+
+		for i := 0; i < len(definition); i++ {
+			if definition[i].isPunctuation("(") {
+				i = skipParenthesized(definition, i) - 1
+				continue
+			}
+			if !definition[i].isKeyword("CHECK") || i+1 >= len(definition) || !definition[i+1].isPunctuation("(") {
+				continue
+			}
+
+			end := skipParenthesized(definition, i+1) - 1
+			check := schema.CheckConstraint{}
+			if end > i+1 && definition[end].isPunctuation(")") {
+				check.Expression = unwrapSQLExpression(createSQL[definition[i+1].end:definition[end].start])
+			}
+			if i >= 2 && definition[i-2].isKeyword("CONSTRAINT") {
+				check.Name = definition[i-1].identifier()
+			}
+			if columnName != "" {
+				check.Columns = []string{columnName}
+			} else {
+				check.Columns = checkExpressionColumns(check.Expression, columns)
+			}
+			checks = append(checks, check)
+			i = end
+		}
 	}
+	return checks
+}
 
-	skipPrefixes := []string{"CREATE TABLE", "FOREIGN KEY", "PRIMARY KEY", "UNIQUE"}
-	for _, prefix := range skipPrefixes {
-		if strings.HasPrefix(line, prefix) {
-			return true
+// markSQLiteRowidAlias marks an INTEGER PRIMARY KEY column as an identity.
+// Such a column aliases the rowid, which SQLite assigns when it is omitted,
+// except in WITHOUT ROWID tables and for the "INTEGER PRIMARY KEY DESC" quirk.
+func markSQLiteRowidAlias(table *schema.Table, createSQL string) {
+	if len(table.PrimaryKey) != 1 {
+		return
+	}
+	column := findColumn(table.Columns, table.PrimaryKey[0])
+	if column == nil || !strings.EqualFold(column.Type, "INTEGER") {
+		return
+	}
+
+	tokens := tokenizeSQL(createSQL)
+	open := slices.IndexFunc(tokens, func(token sqlToken) bool { return token.isPunctuation("(") })
+	if open < 0 {
+		return
+	}
+	for _, token := range tokens[skipParenthesized(tokens, open):] {
+		if token.isKeyword("WITHOUT") {
+			return
+		}
+	}
+	for _, definition := range sqliteTableDefinitions(tokens) {
+		if isSQLiteTableConstraint(definition[0]) || !strings.EqualFold(definition[0].identifier(), column.Name) {
+			continue
+		}
+		for i := 2; i < len(definition); i++ {
+			if definition[i-2].isKeyword("PRIMARY") && definition[i-1].isKeyword("KEY") && definition[i].isKeyword("DESC") {
+				return
+			}
 		}
 	}
 
-	return false
+	column.Identity = schema.IdentityByDefault
 }
 
-// checkConstraint represents a parsed CHECK constraint
-type checkConstraint struct {
-	columnName string
-	expression string
+// sqliteGeneratedExpressions maps the lowercased names of generated columns
+// to their "[GENERATED ALWAYS] AS (expression)" expressions
+func sqliteGeneratedExpressions(createSQL string) map[string]string {
+	expressions := make(map[string]string)
+	for _, definition := range sqliteTableDefinitions(tokenizeSQL(createSQL)) {
+		if isSQLiteTableConstraint(definition[0]) {
+			continue
+		}
+		for i := 1; i < len(definition); i++ {
+			if definition[i].isPunctuation("(") {
+				i = skipParenthesized(definition, i) - 1
+				continue
+			}
+			if !definition[i].isKeyword("AS") || i+1 >= len(definition) || !definition[i+1].isPunctuation("(") {
+				continue
+			}
+			end := skipParenthesized(definition, i+1) - 1
+			if end > i+1 && definition[end].isPunctuation(")") {
+				name := strings.ToLower(definition[0].identifier())
+				expressions[name] = strings.TrimSpace(createSQL[definition[i+1].end:definition[end].start])
+			}
+			break
+		}
+	}
+	return expressions
 }
 
-// parseCheckConstraintFromLine parses a CHECK constraint from a single line
-func (e *SQLiteExtractor) parseCheckConstraintFromLine(line string) *checkConstraint {
-	checkIdx := strings.Index(line, "CHECK(")
-	if checkIdx == -1 {
-		return nil
+// sqliteTableDefinitions returns the non-empty column definitions and table
+// constraints of a CREATE TABLE statement
+func sqliteTableDefinitions(tokens []sqlToken) [][]sqlToken {
+	open := slices.IndexFunc(tokens, func(token sqlToken) bool { return token.isPunctuation("(") })
+	if open < 0 {
+		return nil // CREATE TABLE ... AS SELECT
 	}
+	closing := max(skipParenthesized(tokens, open)-1, open+1)
 
-	columnName := e.extractColumnName(line)
-	if columnName == "" {
-		return nil
+	var definitions [][]sqlToken
+	for _, definition := range splitSQLList(tokens[open+1 : closing]) {
+		if len(definition) > 0 {
+			definitions = append(definitions, definition)
+		}
 	}
+	return definitions
+}
 
-	expression := e.extractCheckExpression(line, checkIdx)
-	if expression == "" {
-		return nil
+// splitSQLList splits tokens at commas outside parentheses
+func splitSQLList(tokens []sqlToken) [][]sqlToken {
+	var items [][]sqlToken
+	start := 0
+	for i := 0; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isPunctuation("("):
+			i = skipParenthesized(tokens, i) - 1
+		case tokens[i].isPunctuation(","):
+			items = append(items, tokens[start:i])
+			start = i + 1
+		}
 	}
+	return append(items, tokens[start:])
+}
 
-	return &checkConstraint{
-		columnName: columnName,
-		expression: expression,
+// isSQLiteTableConstraint reports whether a table definition item starting
+// with token is a table constraint rather than a column definition
+func isSQLiteTableConstraint(token sqlToken) bool {
+	for _, keyword := range []string{"CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN"} {
+		if token.isKeyword(keyword) {
+			return true
+		}
 	}
+	return false
 }
 
-// extractColumnName extracts the column name from a line (first word)
-func (e *SQLiteExtractor) extractColumnName(line string) string {
-	parts := strings.Fields(line)
-	if len(parts) == 0 {
-		return ""
+// sqliteViewQuery returns the SELECT statement of a CREATE VIEW statement
+func sqliteViewQuery(createSQL string) string {
+	depth := 0
+	for _, token := range tokenizeSQL(createSQL) {
+		switch {
+		case token.isPunctuation("("):
+			depth++
+		case token.isPunctuation(")"):
+			depth--
+		case depth == 0 && token.isKeyword("AS"):
+			return strings.TrimSuffix(strings.TrimSpace(createSQL[token.end:]), ";")
+		}
 	}
-	return parts[0]
+	return strings.TrimSpace(createSQL)
 }
 
-// extractCheckExpression extracts the CHECK expression from a line
-func (e *SQLiteExtractor) extractCheckExpression(line string, checkIdx int) string {
-	checkStart := checkIdx + 6 // Length of "CHECK("
-	depth := 1
-	checkEnd := checkStart
+// sqliteFromClauseEnd lists keywords that end a FROM clause at its own nesting level
+var sqliteFromClauseEnd = []string{"WHERE", "GROUP", "HAVING", "WINDOW", "ORDER", "LIMIT", "UNION", "INTERSECT", "EXCEPT"}
 
-	for i := checkStart; i < len(line) && depth > 0; i++ {
-		if line[i] == '(' {
+// sqliteQueryRelations returns the names of the tables and views read by a
+// query's FROM and JOIN clauses, including those of subqueries. Common table
+// expressions and table-valued functions are not reported.
+func sqliteQueryRelations(query string) []string {
+	tokens := tokenizeSQL(query)
+	cteNames := sqliteCommonTableExpressionNames(tokens)
+
+	var relations []string
+	addRelation := func(i int) {
+		if i >= len(tokens) || !tokens[i].isIdentifier() {
+			return
+		}
+		name := tokens[i]
+		next := i + 1
+		if next+1 < len(tokens) && tokens[next].isPunctuation(".") && tokens[next+1].isIdentifier() {
+			name = tokens[next+1]
+			next += 2
+		}
+		if next < len(tokens) && tokens[next].isPunctuation("(") {
+			return // table-valued function
+		}
+		if !cteNames[strings.ToLower(name.identifier())] {
+			relations = append(relations, name.identifier())
+		}
+	}
+
+	depth := 0
+	inFromClause := make(map[int]bool)
+	for i, token := range tokens {
+		switch {
+		case token.isPunctuation("("):
 			depth++
-		} else if line[i] == ')' {
+		case token.isPunctuation(")"):
+			delete(inFromClause, depth)
 			depth--
-			if depth == 0 {
-				checkEnd = i
-				break
-			}
+		case token.isKeyword("FROM") && (i == 0 || !tokens[i-1].isKeyword("DISTINCT")),
+			token.isKeyword("JOIN"):
+			inFromClause[depth] = true
+			addRelation(i + 1)
+		case token.isPunctuation(",") && inFromClause[depth]:
+			addRelation(i + 1)
+		case inFromClause[depth] && slices.ContainsFunc(sqliteFromClauseEnd, token.isKeyword):
+			delete(inFromClause, depth)
 		}
 	}
+	return relations
+}
 
-	if depth != 0 {
-		return ""
+// sqliteCommonTableExpressionNames returns the lowercased names declared by
+// "name [(columns)] AS [NOT] [MATERIALIZED] (" in a query
+func sqliteCommonTableExpressionNames(tokens []sqlToken) map[string]bool {
+	names := make(map[string]bool)
+	for i, token := range tokens {
+		if !token.isIdentifier() || token.isKeyword("AS") {
+			continue
+		}
+		next := i + 1
+		if next < len(tokens) && tokens[next].isPunctuation("(") {
+			next = skipParenthesized(tokens, next)
+		}
+		if next >= len(tokens) || !tokens[next].isKeyword("AS") {
+			continue
+		}
+		next++
+		for next < len(tokens) && (tokens[next].isKeyword("NOT") || tokens[next].isKeyword("MATERIALIZED")) {
+			next++
+		}
+		if next < len(tokens) && tokens[next].isPunctuation("(") {
+			names[strings.ToLower(token.identifier())] = true
+		}
 	}
-
-	return line[checkStart:checkEnd]
+	return names
 }
@@ -7,7 +7,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 func TestSQLiteClientWaitsForTransientLocks(t *testing.T) {
@@ -303,6 +303,111 @@ func TestSQLiteExtractorPreservesConstraintsAndCardinality(t *testing.T) {
 	}
 }
 
+func TestSQLiteExtractorIncludesViews(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewSQLiteClient(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	statements := []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, active INTEGER)`,
+		`CREATE TABLE "Order Items" (user_id INTEGER, total REAL)`,
+		`CREATE VIEW active_users AS SELECT id, name FROM users WHERE active = 1`,
+		`CREATE VIEW user_totals (user_id, total) AS
+			-- totals per user, FROM "Order Items"
+			SELECT u.id, sum(i.total)
+			FROM active_users u JOIN "order items" AS i ON i.user_id = u.id
+			GROUP BY u.id;`,
+	}
+	for _, statement := range statements {
+		if _, err := client.GetDB().ExecContext(ctx, statement); err != nil {
+			t.Fatalf("creating test schema failed: %v", err)
+		}
+	}
+
+	s, err := NewSQLiteExtractor(client).ExtractSchema(ctx, nil)
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	if len(s.Tables) != 2 {
+		t.Errorf("got %d tables, want 2", len(s.Tables))
+	}
+	if len(s.Views) != 2 {
+		t.Fatalf("got %d views, want 2", len(s.Views))
+	}
+
+	activeUsers := s.Views[0]
+	if activeUsers.Name != "active_users" || activeUsers.Kind != schema.ViewKindView {
+		t.Errorf("first view = %s (%s), want active_users (view)", activeUsers.Name, activeUsers.Kind)
+	}
+	if activeUsers.Definition != "SELECT id, name FROM users WHERE active = 1" {
+		t.Errorf("active_users definition = %q", activeUsers.Definition)
+	}
+	if name := columnNamed(t, activeUsers.Columns, "name"); name.Type != "TEXT" {
+		t.Errorf("active_users.name type = %q, want TEXT", name.Type)
+	}
+
+	userTotals := s.Views[1]
+	if got := []string{userTotals.Columns[0].Name, userTotals.Columns[1].Name}; !slices.Equal(got, []string{"user_id", "total"}) {
+		t.Errorf("user_totals columns = %v, want [user_id total]", got)
+	}
+	if want := []string{"Order Items", "active_users"}; !slices.Equal(userTotals.Dependencies, want) {
+		t.Errorf("user_totals dependencies = %v, want %v", userTotals.Dependencies, want)
+	}
+
+	s, err = NewSQLiteExtractor(client).ExtractSchema(ctx, []string{"active_users"})
+	if err != nil {
+		t.Fatalf("ExtractSchema(active_users) failed: %v", err)
+	}
+	if len(s.Tables) != 0 || len(s.Views) != 1 {
+		t.Errorf("requesting a view returned %d tables and %d views, want 0 and 1", len(s.Tables), len(s.Views))
+	}
+}
+
+func TestSQLiteQueryRelations(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "comma join",
+			query: "SELECT * FROM users u, main.orders AS o WHERE u.id = o.user_id",
+			want:  []string{"users", "orders"},
+		},
+		{
+			name:  "joins and subquery",
+			query: "SELECT * FROM a LEFT JOIN [b c] ON 1 WHERE x IN (SELECT y FROM `d`)",
+			want:  []string{"a", "b c", "d"},
+		},
+		{
+			name:  "common table expression",
+			query: "WITH recent(id) AS (SELECT id FROM orders) SELECT * FROM recent JOIN users ON 1",
+			want:  []string{"orders", "users"},
+		},
+		{
+			name:  "strings, comments, and functions",
+			query: "SELECT 'FROM fake', x IS DISTINCT FROM y /* FROM hidden */ FROM json_each('[]'), items",
+			want:  []string{"items"},
+		},
+		{
+			name:  "compound select",
+			query: "SELECT id FROM a WHERE id IN (1, 2) UNION SELECT id FROM b",
+			want:  []string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqliteQueryRelations(tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("sqliteQueryRelations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRelationCardinalityUsesColumnSets(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -330,6 +435,234 @@ func TestRelationCardinalityUsesColumnSets(t *testing.T) {
 	}
 }
 
+func TestSQLiteCheckConstraints(t *testing.T) {
+	columns := []schema.Column{{Name: "id"}, {Name: "status"}, {Name: "starts at"}, {Name: "ends_at"}, {Name: "label"}}
+	tests := []struct {
+		name      string
+		createSQL string
+		want      []schema.CheckConstraint
+	}{
+		{
+			name:      "space before parenthesis and lowercase keyword",
+			createSQL: "CREATE TABLE t (status TEXT check (status IN ('a', 'b')))",
+			want:      []schema.CheckConstraint{{Expression: "status IN ('a', 'b')", Columns: []string{"status"}}},
+		},
+		{
+			name:      "one-line definition with several columns",
+			createSQL: "CREATE TABLE t (id INTEGER CHECK(id > 0), label TEXT NOT NULL CHECK(length(label) < 10))",
+			want: []schema.CheckConstraint{
+				{Expression: "id > 0", Columns: []string{"id"}},
+				{Expression: "length(label) < 10", Columns: []string{"label"}},
+			},
+		},
+		{
+			name: "quoted column and named column constraint",
+			createSQL: `CREATE TABLE "t" (
+				"starts at" TEXT CONSTRAINT "starts set" CHECK ("starts at" <> ''),
+				[ends_at] TEXT
+			)`,
+			want: []schema.CheckConstraint{{Name: "starts set", Expression: `"starts at" <> ''`, Columns: []string{"starts at"}}},
+		},
+		{
+			name: "table constraint",
+			createSQL: `CREATE TABLE t (
+				"starts at" TEXT,
+				ends_at TEXT,
+				CONSTRAINT period CHECK ("starts at" < ends_at)
+			)`,
+			want: []schema.CheckConstraint{{Name: "period", Expression: `"starts at" < ends_at`, Columns: []string{"starts at", "ends_at"}}},
+		},
+		{
+			name:      "string literals, comments, and nested parentheses",
+			createSQL: "CREATE TABLE t (label TEXT /* CHECK(fake) */ CHECK (label NOT IN (')', 'CHECK(x)')) -- CHECK(y)\n, CHECK ((id > 0)))",
+			want: []schema.CheckConstraint{
+				{Expression: "label NOT IN (')', 'CHECK(x)')", Columns: []string{"label"}},
+				{Expression: "id > 0", Columns: []string{"id"}},
+			},
+		},
+		{
+			name:      "redundant parentheses around a table constraint",
+			createSQL: "CREATE TABLE t (id INTEGER, status TEXT, CHECK ((id > 0)), CHECK ((id > 0) AND (status <> '')))",
+			want: []schema.CheckConstraint{
+				{Expression: "id > 0", Columns: []string{"id"}},
+				{Expression: "(id > 0) AND (status <> '')", Columns: []string{"id", "status"}},
+			},
+		},
+		{
+			name:      "check-like text in defaults and generated columns",
+			createSQL: "CREATE TABLE t (label TEXT DEFAULT ('check(1)'), status TEXT GENERATED ALWAYS AS (CHECKSUM(label)))",
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sqliteCheckConstraints(tt.createSQL, columns)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d checks %v, want %d %v", len(got), got, len(tt.want), tt.want)
+			}
+			for i := range got {
+				if got[i].Name != tt.want[i].Name || got[i].Expression != tt.want[i].Expression || !slices.Equal(got[i].Columns, tt.want[i].Columns) {
+					t.Errorf("check %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteExtractorAttributesCheckConstraints(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewSQLiteClient(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if _, err := client.GetDB().ExecContext(ctx, `CREATE TABLE bookings (id INTEGER PRIMARY KEY, starts_at TEXT check (starts_at <> ''), ends_at TEXT, CONSTRAINT period CHECK (starts_at < ends_at))`); err != nil {
+		t.Fatalf("creating test schema failed: %v", err)
+	}
+
+	s, err := NewSQLiteExtractor(client).ExtractSchema(ctx, nil)
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	table := tableNamed(t, s.Tables, "bookings")
+	if check := columnNamed(t, table.Columns, "starts_at").CheckConstraint; check == nil || *check != "starts_at <> ''" {
+		t.Errorf("starts_at check = %v, want starts_at <> ''", check)
+	}
+	if len(table.Checks) != 1 || table.Checks[0].Name != "period" || !slices.Equal(table.Checks[0].Columns, []string{"starts_at", "ends_at"}) {
+		t.Errorf("table checks = %+v, want period on (starts_at, ends_at)", table.Checks)
+	}
+}
+
+func TestSQLiteExtractorIncludesGeneratedColumns(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewSQLiteClient(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if _, err := client.GetDB().ExecContext(ctx, `CREATE TABLE items (
+		quantity INTEGER NOT NULL CHECK (quantity > 0),
+		unit_price REAL NOT NULL,
+		"Line Total" REAL GENERATED ALWAYS AS (quantity * unit_price) STORED,
+		label TEXT,
+		label_length INTEGER AS (length(coalesce(label, ''))) CHECK (label_length < 100)
+	)`); err != nil {
+		t.Fatalf("creating test schema failed: %v", err)
+	}
+
+	s, err := NewSQLiteExtractor(client).ExtractSchema(ctx, nil)
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	table := tableNamed(t, s.Tables, "items")
+	if len(table.Columns) != 5 {
+		t.Fatalf("got %d columns, want 5", len(table.Columns))
+	}
+
+	lineTotal := columnNamed(t, table.Columns, "Line Total").Generated
+	if lineTotal == nil || lineTotal.Expression != "quantity * unit_price" || !lineTotal.Stored {
+		t.Errorf("Line Total generated = %+v, want stored quantity * unit_price", lineTotal)
+	}
+	labelLength := columnNamed(t, table.Columns, "label_length")
+	if labelLength.Generated == nil || labelLength.Generated.Expression != "length(coalesce(label, ''))" || labelLength.Generated.Stored {
+		t.Errorf("label_length generated = %+v, want virtual length(coalesce(label, ''))", labelLength.Generated)
+	}
+	if labelLength.CheckConstraint == nil || *labelLength.CheckConstraint != "label_length < 100" {
+		t.Errorf("label_length check = %v, want label_length < 100", labelLength.CheckConstraint)
+	}
+	if columnNamed(t, table.Columns, "quantity").Generated != nil {
+		t.Error("quantity marked as generated")
+	}
+}
+
+func TestSQLiteExtractorDetectsRowidAliases(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewSQLiteClient(ctx, ":memory:")
+	if err != nil {
+		t.Fatalf("NewSQLiteClient() failed: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	statements := []string{
+		`CREATE TABLE autoincrement_keys (id INTEGER PRIMARY KEY AUTOINCREMENT)`,
+		`CREATE TABLE table_constraint_keys ("Key" integer, PRIMARY KEY ("Key"))`,
+		`CREATE TABLE descending_keys (id INTEGER PRIMARY KEY DESC)`,
+		`CREATE TABLE int_keys (id INT PRIMARY KEY)`,
+		`CREATE TABLE without_rowid_keys (id INTEGER PRIMARY KEY) WITHOUT ROWID`,
+		`CREATE TABLE composite_keys (a INTEGER, b INTEGER, PRIMARY KEY (a, b))`,
+	}
+	for _, statement := range statements {
+		if _, err := client.GetDB().ExecContext(ctx, statement); err != nil {
+			t.Fatalf("creating test schema failed: %v", err)
+		}
+	}
+
+	s, err := NewSQLiteExtractor(client).ExtractSchema(ctx, nil)
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	tests := []struct {
+		table, column, want string
+	}{
+		{"autoincrement_keys", "id", schema.IdentityByDefault},
+		{"table_constraint_keys", "Key", schema.IdentityByDefault},
+		{"descending_keys", "id", ""},
+		{"int_keys", "id", ""},
+		{"without_rowid_keys", "id", ""},
+		{"composite_keys", "a", ""},
+	}
+	for _, tt := range tests {
+		if got := columnNamed(t, tableNamed(t, s.Tables, tt.table).Columns, tt.column).Identity; got != tt.want {
+			t.Errorf("%s.%s identity = %q, want %q", tt.table, tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestApplyCheckConstraintsKeepsTableLevelChecks(t *testing.T) {
+	table := schema.Table{Columns: []schema.Column{{Name: "price"}, {Name: "discount"}}}
+	applyCheckConstraints(&table, []schema.CheckConstraint{
+		{Name: "price_positive", Expression: "price > 0", Columns: []string{"price"}},
+		{Name: "price_below_limit", Expression: "price < 1000", Columns: []string{"price"}},
+		{Name: "discount_below_price", Expression: "discount < price", Columns: []string{"discount", "price"}},
+	})
+
+	if check := columnNamed(t, table.Columns, "price").CheckConstraint; check == nil || *check != "price > 0" {
+		t.Errorf("price check = %v, want price > 0", check)
+	}
+	if check := columnNamed(t, table.Columns, "discount").CheckConstraint; check != nil {
+		t.Errorf("discount check = %q, want none", *check)
+	}
+	var names []string
+	for _, check := range table.Checks {
+		names = append(names, check.Name)
+	}
+	if want := []string{"price_below_limit", "discount_below_price"}; !slices.Equal(names, want) {
+		t.Errorf("table checks = %v, want %v", names, want)
+	}
+}
+
+func TestCheckExpressionColumns(t *testing.T) {
+	columns := []schema.Column{{Name: "price"}, {Name: "Sale Price"}, {Name: "lower"}, {Name: "status"}}
+	tests := []struct {
+		expression string
+		want       []string
+	}{
+		{expression: "`price` >= 0", want: []string{"price"}},
+		{expression: "`sale price` < PRICE", want: []string{"price", "Sale Price"}},
+		{expression: "lower(status) <> 'price'", want: []string{"status"}},
+		{expression: "random() > 0", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := checkExpressionColumns(tt.expression, columns); !slices.Equal(got, tt.want) {
+			t.Errorf("checkExpressionColumns(%q) = %v, want %v", tt.expression, got, tt.want)
+		}
+	}
+}
+
 func tableNamed(t *testing.T, tables []schema.Table, name string) schema.Table {
 	t.Helper()
 	for _, table := range tables {
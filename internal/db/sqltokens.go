@@ -0,0 +1,184 @@
+package db
+
+import "strings"
+
+// sqlTokenKind classifies a token produced by tokenizeSQL
+type sqlTokenKind int
+
+const (
+	sqlWord             sqlTokenKind = iota // keyword or unquoted identifier
+	sqlQuotedIdentifier                     // "name", `name`, or [name]
+	sqlString                               // 'text'
+	sqlNumber
+	sqlPunctuation // parentheses, commas, dots, semicolons, and operators
+)
+
+// sqlToken is a lexical token with its byte offsets in the source text
+type sqlToken struct {
+	kind  sqlTokenKind
+	text  string // source text, including quotes
+	start int
+	end   int
+}
+
+// isKeyword reports whether the token is the given unquoted keyword
+func (t sqlToken) isKeyword(keyword string) bool {
+	return t.kind == sqlWord && strings.EqualFold(t.text, keyword)
+}
+
+// isPunctuation reports whether the token is the given punctuation
+func (t sqlToken) isPunctuation(punctuation string) bool {
+	return t.kind == sqlPunctuation && t.text == punctuation
+}
+
+// isIdentifier reports whether the token can name a relation or column
+func (t sqlToken) isIdentifier() bool {
+	return t.kind == sqlWord || t.kind == sqlQuotedIdentifier
+}
+
+// identifier returns the token's name with quoting removed
+func (t sqlToken) identifier() string {
+	if t.kind != sqlQuotedIdentifier || len(t.text) < 2 {
+		return t.text
+	}
+	body := t.text[1 : len(t.text)-1]
+	switch t.text[0] {
+	case '"':
+		return strings.ReplaceAll(body, `""`, `"`)
+	case '`':
+		return strings.ReplaceAll(body, "``", "`")
+	}
+	return body
+}
+
+// tokenizeSQL splits SQL text into tokens, skipping whitespace and comments.
+// Quoted strings and identifiers may contain any character, including
+// doubled quotes; an unterminated quote extends to the end of the input.
+func tokenizeSQL(sqlText string) []sqlToken {
+	return tokenize(sqlText, false)
+}
+
+// tokenizeMySQL splits MySQL text into tokens like tokenizeSQL, also
+// skipping # comments and backslash escapes in quoted strings
+func tokenizeMySQL(sqlText string) []sqlToken {
+	return tokenize(sqlText, true)
+}
+
+func tokenize(sqlText string, mysql bool) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(sqlText); {
+		c := sqlText[i]
+		start := i
+		var kind sqlTokenKind
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+			continue
+		case (c == '-' && strings.HasPrefix(sqlText[i:], "--")) || (c == '#' && mysql):
+			if end := strings.IndexByte(sqlText[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(sqlText)
+			}
+			continue
+		case c == '/' && strings.HasPrefix(sqlText[i:], "/*"):
+			if end := strings.Index(sqlText[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(sqlText)
+			}
+			continue
+		case c == '\'':
+			kind = sqlString
+			i = scanQuoted(sqlText, i, mysql)
+		case c == '"' || c == '`':
+			kind = sqlQuotedIdentifier
+			i = scanQuoted(sqlText, i, mysql && c == '"')
+		case c == '[':
+			kind = sqlQuotedIdentifier
+			if end := strings.IndexByte(sqlText[i:], ']'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(sqlText)
+			}
+		case isSQLDigit(c) || (c == '.' && i+1 < len(sqlText) && isSQLDigit(sqlText[i+1])):
+			kind = sqlNumber
+			for i < len(sqlText) && (isSQLWordByte(sqlText[i]) || sqlText[i] == '.') {
+				i++
+			}
+		case isSQLWordByte(c):
+			kind = sqlWord
+			for i < len(sqlText) && isSQLWordByte(sqlText[i]) {
+				i++
+			}
+		default:
+			kind = sqlPunctuation
+			i++
+		}
+
+		tokens = append(tokens, sqlToken{kind: kind, text: sqlText[start:i], start: start, end: i})
+	}
+	return tokens
+}
+
+// scanQuoted returns the offset just past the quoted text starting at start,
+// treating a doubled quote character as an escaped quote. MySQL strings also
+// escape characters with a backslash.
+func scanQuoted(sqlText string, start int, backslashEscapes bool) int {
+	quote := sqlText[start]
+	for i := start + 1; i < len(sqlText); i++ {
+		switch sqlText[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(sqlText) && sqlText[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sqlText)
+}
+
+// skipParenthesized returns the index just past the parenthesized group
+// opening at tokens[open]
+func skipParenthesized(tokens []sqlToken, open int) int {
+	depth := 0
+	for i := open; i < len(tokens); i++ {
+		switch {
+		case tokens[i].isPunctuation("("):
+			depth++
+		case tokens[i].isPunctuation(")"):
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(tokens)
+}
+
+// unwrapSQLExpression removes parentheses that enclose a whole expression
+func unwrapSQLExpression(expression string) string {
+	expression = strings.TrimSpace(expression)
+	for {
+		tokens := tokenizeSQL(expression)
+		if len(tokens) < 2 || !tokens[0].isPunctuation("(") || skipParenthesized(tokens, 0) != len(tokens) {
+			return expression
+		}
+		expression = strings.TrimSpace(expression[tokens[0].end:tokens[len(tokens)-1].start])
+	}
+}
+
+func isSQLDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSQLWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || isSQLDigit(c)
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// viewMetadata holds a view's catalog entry before its columns are extracted
+type viewMetadata struct {
+	name         string
+	materialized bool
+	definition   string
+}
+
+// splitRequestedViews removes view names from tableNames and returns the
+// views to extract. Views keep their catalog order. When no relations were
+// requested, tableNames is the catalog's table list and every view is selected.
+func splitRequestedViews(requested, tableNames []string, views []viewMetadata) ([]string, []viewMetadata) {
+	if len(requested) == 0 {
+		return tableNames, views
+	}
+
+	requestedSet := make(map[string]bool, len(requested))
+	for _, name := range requested {
+		requestedSet[name] = true
+	}
+	viewSet := make(map[string]bool, len(views))
+	var selected []viewMetadata
+	for _, view := range views {
+		viewSet[view.name] = true
+		if requestedSet[view.name] {
+			selected = append(selected, view)
+		}
+	}
+
+	tables := make([]string, 0, len(tableNames))
+	for _, name := range tableNames {
+		if !viewSet[name] {
+			tables = append(tables, name)
+		}
+	}
+	return tables, selected
+}
+
+func newView(metadata viewMetadata, columns []schema.Column, dependencies []string) schema.View {
+	kind := schema.ViewKindView
+	if metadata.materialized {
+		kind = schema.ViewKindMaterialized
+	}
+	return schema.View{
+		Name:         metadata.name,
+		Kind:         kind,
+		Columns:      columns,
+		Definition:   strings.TrimSpace(metadata.definition),
+		Dependencies: dependencies,
+	}
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitRequestedViews(t *testing.T) {
+	views := []viewMetadata{{name: "active_users"}, {name: "order_totals", materialized: true}}
+
+	tables, selected := splitRequestedViews(nil, []string{"orders", "users"}, views)
+	if !slices.Equal(tables, []string{"orders", "users"}) || len(selected) != 2 {
+		t.Errorf("without a request got tables %v and %d views, want all", tables, len(selected))
+	}
+
+	tables, selected = splitRequestedViews(
+		[]string{"order_totals", "users"},
+		[]string{"order_totals", "users"},
+		views,
+	)
+	if !slices.Equal(tables, []string{"users"}) {
+		t.Errorf("tables = %v, want [users]", tables)
+	}
+	if len(selected) != 1 || selected[0].name != "order_totals" {
+		t.Errorf("selected views = %v, want [order_totals]", selected)
+	}
+}
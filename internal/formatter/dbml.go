@@ -0,0 +1,349 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// DBMLFormatter formats tables, enums, indexes, and relations as DBML
+// (https://dbml.dbdiagram.io)
+type DBMLFormatter struct {
+	writer           io.Writer
+	OmitDatabaseInfo bool
+	OmitComments     bool
+}
+
+// NewDBMLFormatter creates a new DBML formatter
+func NewDBMLFormatter(w io.Writer) *DBMLFormatter {
+	return &DBMLFormatter{writer: w}
+}
+
+var (
+	dbmlIdentifier    = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+	dbmlPlainType     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\(\s*[0-9]+\s*(,\s*[0-9]+\s*)?\))?(\[\])*$`)
+	dbmlNumber        = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	dbmlStringLiteral = regexp.MustCompile(`^'((?:[^']|'')*)'$`)
+)
+
+// dbmlEnum is an enum definition shared by the columns that use it
+type dbmlEnum struct {
+	name   string
+	values []string
+}
+
+// Format writes the schema as DBML. Views are not included.
+func (f *DBMLFormatter) Format(s *schema.Schema) error {
+	if !f.OmitDatabaseInfo && s.DatabaseName != "" {
+		if _, err := fmt.Fprintf(f.writer, "Project %s {\n", dbmlName(s.DatabaseName)); err != nil {
+			return err
+		}
+		if s.DatabaseType != "" {
+			if _, err := fmt.Fprintf(f.writer, "  database_type: %s\n", dbmlString(s.DatabaseType)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(f.writer, "}\n\n"); err != nil {
+			return err
+		}
+	}
+
+	enums, columnEnums := dbmlEnums(s.Tables)
+	for _, enum := range enums {
+		if err := f.formatEnum(enum); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range s.Tables {
+		if err := f.formatTable(table, columnEnums); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range s.Tables {
+		for _, rel := range table.Relations {
+			if _, err := fmt.Fprintln(f.writer, dbmlRef(table, rel)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dbmlEnums collects the enum definitions of all columns with enum values.
+// Named types such as PostgreSQL enums keep their name; inline types such as
+// MySQL ENUM(...) are named after their table and column. The returned map
+// holds the enum name of each such column, keyed by table and column.
+func dbmlEnums(tables []schema.Table) ([]dbmlEnum, map[[2]string]string) {
+	var enums []dbmlEnum
+	columnEnums := make(map[[2]string]string)
+	for _, table := range tables {
+		qualifiedTable := schema.QualifiedName(table.Schema, table.Name)
+		for _, col := range table.Columns {
+			if len(col.EnumValues) == 0 {
+				continue
+			}
+			baseName := col.Type
+			if !dbmlIdentifier.MatchString(baseName) {
+				baseName = strings.ReplaceAll(qualifiedTable, ".", "_") + "_" + col.Name + "_enum"
+			}
+			// Enums with different values may share a generated name, so number repeats
+			name := baseName
+			for suffix := 2; ; suffix++ {
+				index := slices.IndexFunc(enums, func(enum dbmlEnum) bool { return enum.name == name })
+				if index < 0 {
+					enums = append(enums, dbmlEnum{name: name, values: col.EnumValues})
+					break
+				}
+				if slices.Equal(enums[index].values, col.EnumValues) {
+					break
+				}
+				name = fmt.Sprintf("%s_%d", baseName, suffix)
+			}
+			columnEnums[[2]string{qualifiedTable, col.Name}] = name
+		}
+	}
+	return enums, columnEnums
+}
+
+func (f *DBMLFormatter) formatEnum(enum dbmlEnum) error {
+	if _, err := fmt.Fprintf(f.writer, "Enum %s {\n", dbmlName(enum.name)); err != nil {
+		return err
+	}
+	for _, value := range enum.values {
+		if _, err := fmt.Fprintf(f.writer, "  %s\n", dbmlName(value)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(f.writer, "}\n\n")
+	return err
+}
+
+func (f *DBMLFormatter) formatTable(table schema.Table, columnEnums map[[2]string]string) error {
+	qualifiedTable := schema.QualifiedName(table.Schema, table.Name)
+	if _, err := fmt.Fprintf(f.writer, "Table %s {\n", dbmlTableName(table.Schema, table.Name)); err != nil {
+		return err
+	}
+
+	singlePrimaryKey := len(table.PrimaryKey) == 1
+	for _, col := range table.Columns {
+		columnType := dbmlType(col.Type)
+		if enum, ok := columnEnums[[2]string{qualifiedTable, col.Name}]; ok {
+			columnType = dbmlName(enum)
+		}
+
+		var settings []string
+		isPK := singlePrimaryKey && table.PrimaryKey[0] == col.Name
+		if isPK {
+			settings = append(settings, "pk")
+		}
+		if col.Identity != "" {
+			settings = append(settings, "increment")
+		}
+		if !col.Nullable && !isPK {
+			settings = append(settings, "not null")
+		}
+		if col.IsUnique && !isPK {
+			settings = append(settings, "unique")
+		}
+		if col.DefaultValue != nil && col.Generated == nil {
+			settings = append(settings, "default: "+dbmlDefault(*col.DefaultValue))
+		}
+		if !f.OmitComments && col.Comment != "" {
+			settings = append(settings, "note: "+dbmlString(col.Comment))
+		}
+
+		line := "  " + dbmlName(col.Name) + " " + columnType
+		if len(settings) > 0 {
+			line += " [" + strings.Join(settings, ", ") + "]"
+		}
+		if _, err := fmt.Fprintln(f.writer, line); err != nil {
+			return err
+		}
+	}
+
+	if indexes := dbmlIndexes(table); len(indexes) > 0 {
+		if _, err := fmt.Fprint(f.writer, "\n  indexes {\n"); err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			if _, err := fmt.Fprintf(f.writer, "    %s\n", index); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(f.writer, "  }\n"); err != nil {
+			return err
+		}
+	}
+
+	if !f.OmitComments && table.Comment != "" {
+		if _, err := fmt.Fprintf(f.writer, "\n  Note: %s\n", dbmlString(table.Comment)); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(f.writer, "}\n\n")
+	return err
+}
+
+// dbmlIndexes returns the index definitions of a table: a composite primary
+// key, the indexes of the table, and composite unique keys that have no
+// matching index. Single-column unique indexes are shown as the column's
+// unique setting instead.
+func dbmlIndexes(table schema.Table) []string {
+	var indexes []string
+	if len(table.PrimaryKey) > 1 {
+		indexes = append(indexes, dbmlIndexColumns(table.PrimaryKey)+" [pk]")
+	}
+
+	for _, index := range table.Indexes {
+		if len(index.Columns) == 0 {
+			// Expression-only indexes have no columns to show
+			continue
+		}
+		if isRepresentedAsUniqueKey(index) && len(index.Columns) == 1 && tableColumnIsUnique(table, index.Columns[0]) {
+			continue
+		}
+
+		var settings []string
+		if index.IsUnique {
+			settings = append(settings, "unique")
+		}
+		if index.Name != "" {
+			settings = append(settings, "name: "+dbmlString(index.Name))
+		}
+		var notes []string
+		if index.IsPartial {
+			notes = append(notes, "partial")
+		}
+		if index.HasExpressions {
+			notes = append(notes, "contains expressions")
+		}
+		if len(notes) > 0 {
+			settings = append(settings, "note: "+dbmlString(strings.Join(notes, ", ")))
+		}
+
+		definition := dbmlIndexColumns(index.Columns)
+		if len(settings) > 0 {
+			definition += " [" + strings.Join(settings, ", ") + "]"
+		}
+		indexes = append(indexes, definition)
+	}
+
+	for _, uniqueKey := range table.UniqueKeys {
+		hasIndex := slices.ContainsFunc(table.Indexes, func(index schema.Index) bool {
+			return isRepresentedAsUniqueKey(index) && slices.Equal(index.Columns, uniqueKey)
+		})
+		if !hasIndex {
+			indexes = append(indexes, dbmlIndexColumns(uniqueKey)+" [unique]")
+		}
+	}
+	return indexes
+}
+
+func tableColumnIsUnique(table schema.Table, name string) bool {
+	return slices.ContainsFunc(table.Columns, func(col schema.Column) bool {
+		return col.Name == name && col.IsUnique
+	})
+}
+
+func dbmlIndexColumns(columns []string) string {
+	if len(columns) == 1 {
+		return dbmlName(columns[0])
+	}
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = dbmlName(column)
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+// dbmlRef returns the Ref line of a relation. DBML reads ">" as many-to-one
+// and "-" as one-to-one.
+func dbmlRef(table schema.Table, rel schema.Relation) string {
+	operator := ">"
+	if rel.Cardinality == "1:1" {
+		operator = "-"
+	}
+
+	ref := "Ref"
+	if rel.Name != "" {
+		ref += " " + dbmlName(rel.Name)
+	}
+	ref += fmt.Sprintf(": %s.%s %s %s.%s",
+		dbmlTableName(table.Schema, table.Name), dbmlIndexColumns(relationSourceColumns(rel)),
+		operator,
+		dbmlTableName(rel.TargetSchema, rel.TargetTable), dbmlIndexColumns(relationTargetColumns(rel)))
+
+	var settings []string
+	if rel.OnDelete != "" && rel.OnDelete != "NO ACTION" {
+		settings = append(settings, "delete: "+strings.ToLower(rel.OnDelete))
+	}
+	if rel.OnUpdate != "" && rel.OnUpdate != "NO ACTION" {
+		settings = append(settings, "update: "+strings.ToLower(rel.OnUpdate))
+	}
+	if len(settings) > 0 {
+		ref += " [" + strings.Join(settings, ", ") + "]"
+	}
+	return ref
+}
+
+// dbmlDefault converts a SQL default to a DBML number, string, boolean, or
+// null, or to a backtick expression when it is anything else
+func dbmlDefault(value string) string {
+	switch strings.ToLower(value) {
+	case "true", "false", "null":
+		return strings.ToLower(value)
+	}
+	if dbmlNumber.MatchString(value) {
+		return value
+	}
+	if match := dbmlStringLiteral.FindStringSubmatch(value); match != nil {
+		return dbmlString(strings.ReplaceAll(match[1], "''", "'"))
+	}
+	// Backtick expressions cannot escape backticks
+	return "`" + strings.ReplaceAll(value, "`", "'") + "`"
+}
+
+func dbmlTableName(schemaName, name string) string {
+	if schemaName == "" {
+		return dbmlName(name)
+	}
+	return dbmlName(schemaName) + "." + dbmlName(name)
+}
+
+// dbmlName quotes names that are not plain DBML identifiers
+func dbmlName(name string) string {
+	if dbmlIdentifier.MatchString(name) {
+		return name
+	}
+	return dbmlQuote(name)
+}
+
+// dbmlType quotes types that DBML cannot parse as written, such as
+// "character varying(255)" or "int unsigned"
+func dbmlType(columnType string) string {
+	if dbmlPlainType.MatchString(columnType) {
+		return columnType
+	}
+	return dbmlQuote(columnType)
+}
+
+// dbmlQuote quotes a name or type in double quotes
+func dbmlQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// dbmlString quotes a note or other string value, using a multi-line string
+// when it contains line breaks
+func dbmlString(value string) string {
+	if strings.ContainsAny(value, "\r\n") {
+		return "'''" + strings.NewReplacer(`\`, `\\`, "'''", `\'''`).Replace(value) + "'''"
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
@@ -0,0 +1,180 @@
+package formatter
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+func TestDBMLFormatterWritesTablesEnumsIndexesAndRefs(t *testing.T) {
+	active := "'active'"
+	createdAt := "now()"
+	zero := "0"
+	s := &schema.Schema{
+		DatabaseType: "PostgreSQL",
+		DatabaseName: "app",
+		Tables: []schema.Table{
+			{
+				Name:       "users",
+				PrimaryKey: []string{"id"},
+				Comment:    "Registered users",
+				Columns: []schema.Column{
+					{Name: "id", Type: "integer", Identity: schema.IdentityByDefault},
+					{Name: "email", Type: "character varying(255)", IsUnique: true, Comment: "Login address"},
+					{Name: "status", Type: "user_status", EnumValues: []string{"active", "on hold"}, DefaultValue: &active},
+					{Name: "created_at", Type: "timestamp", Nullable: true, DefaultValue: &createdAt},
+				},
+				Indexes: []schema.Index{
+					{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true},
+					{Name: "idx_users_status_created", Columns: []string{"status", "created_at"}},
+				},
+			},
+			{
+				Name:       "memberships",
+				PrimaryKey: []string{"user_id", "team_id"},
+				UniqueKeys: [][]string{{"team_id", "position"}},
+				Columns: []schema.Column{
+					{Name: "user_id", Type: "integer"},
+					{Name: "team_id", Type: "integer"},
+					{Name: "position", Type: "int", DefaultValue: &zero},
+					{Name: "role", Type: "enum('owner','member')", EnumValues: []string{"owner", "member"}},
+				},
+				Relations: []schema.Relation{
+					{Name: "memberships_user_id_fkey", TargetTable: "users", SourceColumns: []string{"user_id"}, TargetColumns: []string{"id"}, Cardinality: "N:1", OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+					{TargetSchema: "org", TargetTable: "teams", SourceColumns: []string{"team_id", "user_id"}, TargetColumns: []string{"id", "owner_id"}, Cardinality: "1:1", OnUpdate: "SET NULL"},
+				},
+			},
+		},
+		Views: []schema.View{{Name: "active_users"}},
+	}
+
+	var output bytes.Buffer
+	if err := NewDBMLFormatter(&output).Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	want := `Project app {
+  database_type: 'PostgreSQL'
+}
+
+Enum user_status {
+  active
+  "on hold"
+}
+
+Enum memberships_role_enum {
+  owner
+  member
+}
+
+Table users {
+  id integer [pk, increment]
+  email "character varying(255)" [not null, unique, note: 'Login address']
+  status user_status [not null, default: 'active']
+  created_at timestamp [default: ` + "`now()`" + `]
+
+  indexes {
+    (status, created_at) [name: 'idx_users_status_created']
+  }
+
+  Note: 'Registered users'
+}
+
+Table memberships {
+  user_id integer [not null]
+  team_id integer [not null]
+  position int [not null, default: 0]
+  role memberships_role_enum [not null]
+
+  indexes {
+    (user_id, team_id) [pk]
+    (team_id, position) [unique]
+  }
+}
+
+Ref memberships_user_id_fkey: memberships.user_id > users.id [delete: cascade]
+Ref: memberships.(team_id, user_id) - org.teams.(id, owner_id) [update: set null]
+`
+	if got := output.String(); got != want {
+		t.Fatalf("Format() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDBMLFormatterCanOmitDatabaseInfoAndComments(t *testing.T) {
+	s := &schema.Schema{
+		DatabaseType: "SQLite",
+		DatabaseName: "app",
+		Tables:       []schema.Table{{Name: "users", Comment: "Registered users", Columns: []schema.Column{{Name: "id", Type: "INTEGER", Comment: "Row id"}}}},
+	}
+
+	var output bytes.Buffer
+	formatter := NewDBMLFormatter(&output)
+	formatter.OmitDatabaseInfo = true
+	formatter.OmitComments = true
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+	if got, want := output.String(), "Table users {\n  id INTEGER [not null]\n}\n\n"; got != want {
+		t.Fatalf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestDBMLFormatterNumbersEnumsWithCollidingNames(t *testing.T) {
+	s := &schema.Schema{Tables: []schema.Table{
+		{Name: "a_b", Columns: []schema.Column{{Name: "c", Type: "enum('x')", EnumValues: []string{"x"}}}},
+		{Name: "a", Columns: []schema.Column{{Name: "b_c", Type: "enum('y')", EnumValues: []string{"y"}}}},
+	}}
+
+	var output bytes.Buffer
+	if err := NewDBMLFormatter(&output).Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+	for _, want := range []string{"Enum a_b_c_enum {\n  x\n}", "Enum a_b_c_enum_2 {\n  y\n}", "b_c a_b_c_enum_2 [not null]"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("Format() does not contain %q:\n%s", want, output.String())
+		}
+	}
+}
+
+func TestDBMLDefault(t *testing.T) {
+	tests := map[string]string{
+		"42":                                "42",
+		"-1.5":                              "-1.5",
+		"TRUE":                              "true",
+		"NULL":                              "null",
+		"'it''s'":                           `'it\'s'`,
+		"'active'::character varying":       "`'active'::character varying`",
+		"CURRENT_TIMESTAMP":                 "`CURRENT_TIMESTAMP`",
+		"nextval('users_id_seq'::regclass)": "`nextval('users_id_seq'::regclass)`",
+	}
+	for value, want := range tests {
+		if got := dbmlDefault(value); got != want {
+			t.Errorf("dbmlDefault(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestDBMLQuotesNamesTypesAndStrings(t *testing.T) {
+	if got, want := dbmlName(`my "table"`), `"my \"table\""`; got != want {
+		t.Errorf("dbmlName() = %q, want %q", got, want)
+	}
+	if got, want := dbmlType("int unsigned"), `"int unsigned"`; got != want {
+		t.Errorf("dbmlType() = %q, want %q", got, want)
+	}
+	if got, want := dbmlType("numeric(10, 2)"), "numeric(10, 2)"; got != want {
+		t.Errorf("dbmlType() = %q, want %q", got, want)
+	}
+	if got, want := dbmlString("first\nsecond"), "'''first\nsecond'''"; got != want {
+		t.Errorf("dbmlString() = %q, want %q", got, want)
+	}
+}
+
+func TestDBMLFormatterPropagatesWriteErrors(t *testing.T) {
+	s := &schema.Schema{Tables: []schema.Table{{Name: "users"}}}
+	if err := NewDBMLFormatter(failingWriter{}).Format(s); !errors.Is(err, errWriteFailed) {
+		t.Fatalf("Format() error = %v, want %v", err, errWriteFailed)
+	}
+}
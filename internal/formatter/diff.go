@@ -0,0 +1,248 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// DiffMarkdownFormatter formats schema differences as markdown suitable for
+// pull request descriptions
+type DiffMarkdownFormatter struct {
+	writer io.Writer
+}
+
+// NewDiffMarkdownFormatter creates a new diff markdown formatter
+func NewDiffMarkdownFormatter(w io.Writer) *DiffMarkdownFormatter {
+	return &DiffMarkdownFormatter{writer: w}
+}
+
+// Format writes the differences in markdown format
+func (f *DiffMarkdownFormatter) Format(d *schema.Diff) error {
+	if _, err := fmt.Fprintln(f.writer, "# Schema Changes"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f.writer); err != nil {
+		return err
+	}
+	if d.IsEmpty() {
+		_, err := fmt.Fprintln(f.writer, "No changes.")
+		return err
+	}
+
+	var addedTables, removedTables []string
+	for _, table := range d.AddedTables {
+		addedTables = append(addedTables, fmt.Sprintf("%s (%s)", markdownInlineCode(schema.QualifiedName(table.Schema, table.Name)), pluralize(len(table.Columns), "column")))
+	}
+	for _, table := range d.RemovedTables {
+		removedTables = append(removedTables, markdownInlineCode(schema.QualifiedName(table.Schema, table.Name)))
+	}
+	if err := f.formatSection("Added tables", addedTables); err != nil {
+		return err
+	}
+	if err := f.formatSection("Removed tables", removedTables); err != nil {
+		return err
+	}
+
+	if len(d.ChangedTables) > 0 {
+		if _, err := fmt.Fprint(f.writer, "## Changed tables\n\n"); err != nil {
+			return err
+		}
+		for _, table := range d.ChangedTables {
+			if _, err := fmt.Fprintf(f.writer, "### %s\n\n", markdownInlineCode(schema.QualifiedName(table.Schema, table.Name))); err != nil {
+				return err
+			}
+			if err := f.formatItems(tableChanges(table)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := f.formatSection("Added views", viewNames(d.AddedViews)); err != nil {
+		return err
+	}
+	if err := f.formatSection("Removed views", viewNames(d.RemovedViews)); err != nil {
+		return err
+	}
+	return f.formatSection("Changed views", viewNames(d.ChangedViews))
+}
+
+func (f *DiffMarkdownFormatter) formatSection(heading string, items []string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(f.writer, "## %s\n\n", heading); err != nil {
+		return err
+	}
+	return f.formatItems(items)
+}
+
+func (f *DiffMarkdownFormatter) formatItems(items []string) error {
+	for _, item := range items {
+		if _, err := fmt.Fprintf(f.writer, "- %s\n", item); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(f.writer)
+	return err
+}
+
+// tableChanges describes each change to a table as one list item
+func tableChanges(table schema.TableDiff) []string {
+	var changes []string
+	for _, column := range table.AddedColumns {
+		changes = append(changes, fmt.Sprintf("Added column %s: %s", markdownInlineCode(column.Name), markdownInlineCode(buildTypeString(column, nil))))
+	}
+	for _, column := range table.RemovedColumns {
+		changes = append(changes, fmt.Sprintf("Removed column %s", markdownInlineCode(column.Name)))
+	}
+	for _, column := range table.ChangedColumns {
+		changes = append(changes, fmt.Sprintf("Changed column %s: %s", markdownInlineCode(column.Name), strings.Join(columnChanges(column), "; ")))
+	}
+	if table.PrimaryKey != nil {
+		changes = append(changes, fmt.Sprintf("Changed primary key: %s → %s", formatKey(table.PrimaryKey.Old), formatKey(table.PrimaryKey.New)))
+	}
+	for _, key := range table.AddedUniqueKeys {
+		changes = append(changes, "Added unique key "+formatKey(key))
+	}
+	for _, key := range table.RemovedUniqueKeys {
+		changes = append(changes, "Removed unique key "+formatKey(key))
+	}
+	for _, index := range table.AddedIndexes {
+		changes = append(changes, "Added index "+formatDiffIndex(index))
+	}
+	for _, index := range table.RemovedIndexes {
+		changes = append(changes, "Removed index "+formatDiffIndex(index))
+	}
+	for _, rel := range table.AddedRelations {
+		changes = append(changes, "Added reference "+formatDiffRelation(rel))
+	}
+	for _, rel := range table.RemovedRelations {
+		changes = append(changes, "Removed reference "+formatDiffRelation(rel))
+	}
+	for _, check := range table.AddedChecks {
+		changes = append(changes, "Added check "+markdownInlineCode(check.Expression))
+	}
+	for _, check := range table.RemovedChecks {
+		changes = append(changes, "Removed check "+markdownInlineCode(check.Expression))
+	}
+	return changes
+}
+
+// columnChanges describes each changed attribute of a column as old → new
+func columnChanges(column schema.ColumnDiff) []string {
+	changes := make([]string, 0, len(column.Changes))
+	for _, change := range column.Changes {
+		switch change {
+		case schema.ColumnChangeType:
+			changes = append(changes, fmt.Sprintf("type %s → %s", markdownInlineCode(columnType(column.Old)), markdownInlineCode(columnType(column.New))))
+		case schema.ColumnChangeNullable:
+			changes = append(changes, fmt.Sprintf("%s → %s", nullability(column.Old), nullability(column.New)))
+		case schema.ColumnChangeDefault:
+			changes = append(changes, fmt.Sprintf("default %s → %s", optionalCode(column.Old.DefaultValue), optionalCode(column.New.DefaultValue)))
+		case schema.ColumnChangeUnique:
+			if column.New.IsUnique {
+				changes = append(changes, "now UNIQUE")
+			} else {
+				changes = append(changes, "no longer UNIQUE")
+			}
+		case schema.ColumnChangeCheck:
+			changes = append(changes, fmt.Sprintf("check %s → %s", optionalCode(column.Old.CheckConstraint), optionalCode(column.New.CheckConstraint)))
+		case schema.ColumnChangeGenerated:
+			changes = append(changes, fmt.Sprintf("generated %s → %s", generatedCode(column.Old.Generated), generatedCode(column.New.Generated)))
+		case schema.ColumnChangeIdentity:
+			changes = append(changes, fmt.Sprintf("identity %s → %s", identityDescription(column.Old.Identity), identityDescription(column.New.Identity)))
+		default:
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func columnType(column schema.Column) string {
+	if len(column.EnumValues) > 0 {
+		return fmt.Sprintf("%s (%s)", column.Type, strings.Join(column.EnumValues, ", "))
+	}
+	return column.Type
+}
+
+func nullability(column schema.Column) string {
+	if column.Nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+func optionalCode(value *string) string {
+	if value == nil {
+		return "none"
+	}
+	return markdownInlineCode(*value)
+}
+
+func generatedCode(generated *schema.GeneratedColumn) string {
+	if generated == nil {
+		return "none"
+	}
+	return markdownInlineCode(formatGenerated(*generated))
+}
+
+func identityDescription(identity string) string {
+	if identity == "" {
+		return "none"
+	}
+	return identity
+}
+
+func formatKey(columns []string) string {
+	if len(columns) == 0 {
+		return "none"
+	}
+	return "(" + strings.Join(columns, ", ") + ")"
+}
+
+func formatDiffIndex(index schema.Index) string {
+	description := fmt.Sprintf("%s on (%s)", markdownInlineCode(index.Name), strings.Join(index.Columns, ", "))
+	if index.IsUnique {
+		description += ", unique"
+	}
+	if index.IsPartial {
+		description += ", partial"
+	}
+	if index.HasExpressions {
+		description += ", contains expressions"
+	}
+	return description
+}
+
+func formatDiffRelation(rel schema.Relation) string {
+	description := fmt.Sprintf("%s → %s", formatSourceColumns(relationSourceColumns(rel)), formatRelationTarget(rel))
+	var actions []string
+	if rel.OnDelete != "" && rel.OnDelete != "NO ACTION" {
+		actions = append(actions, "ON DELETE "+rel.OnDelete)
+	}
+	if rel.OnUpdate != "" && rel.OnUpdate != "NO ACTION" {
+		actions = append(actions, "ON UPDATE "+rel.OnUpdate)
+	}
+	if len(actions) > 0 {
+		description += " (" + strings.Join(actions, "; ") + ")"
+	}
+	return description
+}
+
+func viewNames(views []schema.View) []string {
+	names := make([]string, 0, len(views))
+	for _, view := range views {
+		names = append(names, markdownInlineCode(schema.QualifiedName(view.Schema, view.Name)))
+	}
+	return names
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,77 @@
+package formatter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+func TestDiffMarkdownFormatter(t *testing.T) {
+	oldDefault := "0"
+	diff := &schema.Diff{
+		AddedTables:   []schema.Table{{Schema: "billing", Name: "invoices", Columns: []schema.Column{{Name: "id"}, {Name: "total"}}}},
+		RemovedTables: []schema.Table{{Name: "legacy"}},
+		ChangedTables: []schema.TableDiff{{
+			Name:           "users",
+			AddedColumns:   []schema.Column{{Name: "email", Type: "text", IsUnique: true}},
+			RemovedColumns: []schema.Column{{Name: "nickname", Type: "text", Nullable: true}},
+			ChangedColumns: []schema.ColumnDiff{{
+				Name:    "age",
+				Changes: []string{schema.ColumnChangeType, schema.ColumnChangeNullable, schema.ColumnChangeDefault},
+				Old:     schema.Column{Name: "age", Type: "integer", Nullable: true, DefaultValue: &oldDefault},
+				New:     schema.Column{Name: "age", Type: "bigint"},
+			}},
+			PrimaryKey:      &schema.KeyChange{Old: []string{"id"}, New: []string{"tenant_id", "id"}},
+			AddedUniqueKeys: [][]string{{"tenant_id", "email"}},
+			RemovedIndexes:  []schema.Index{{Name: "users_age_idx", Columns: []string{"age"}}},
+			AddedRelations:  []schema.Relation{{TargetTable: "teams", SourceColumns: []string{"team_id"}, TargetColumns: []string{"id"}, OnDelete: "CASCADE"}},
+			AddedChecks:     []schema.CheckConstraint{{Expression: "age >= 0"}},
+		}},
+		ChangedViews: []schema.View{{Name: "adults"}},
+	}
+
+	var output bytes.Buffer
+	if err := NewDiffMarkdownFormatter(&output).Format(diff); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	want := "# Schema Changes\n\n" +
+		"## Added tables\n\n" +
+		"- `billing.invoices` (2 columns)\n\n" +
+		"## Removed tables\n\n" +
+		"- `legacy`\n\n" +
+		"## Changed tables\n\n" +
+		"### `users`\n\n" +
+		"- Added column `email`: `text NOT NULL UNIQUE`\n" +
+		"- Removed column `nickname`\n" +
+		"- Changed column `age`: type `integer` → `bigint`; NULL → NOT NULL; default `0` → none\n" +
+		"- Changed primary key: (id) → (tenant_id, id)\n" +
+		"- Added unique key (tenant_id, email)\n" +
+		"- Removed index `users_age_idx` on (age)\n" +
+		"- Added reference team_id → teams.id (ON DELETE CASCADE)\n" +
+		"- Added check `age >= 0`\n\n" +
+		"## Changed views\n\n" +
+		"- `adults`\n\n"
+	if output.String() != want {
+		t.Errorf("Format() output mismatch\ngot:\n%s\nwant:\n%s", output.String(), want)
+	}
+}
+
+func TestDiffMarkdownFormatterReportsNoChanges(t *testing.T) {
+	var output bytes.Buffer
+	if err := NewDiffMarkdownFormatter(&output).Format(&schema.Diff{}); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+	if want := "# Schema Changes\n\nNo changes.\n"; output.String() != want {
+		t.Errorf("Format() = %q, want %q", output.String(), want)
+	}
+}
+
+func TestDiffMarkdownFormatterReturnsWriteErrors(t *testing.T) {
+	err := NewDiffMarkdownFormatter(failingWriter{}).Format(&schema.Diff{})
+	if !errors.Is(err, errWriteFailed) {
+		t.Fatalf("Format() error = %v, want %v", err, errWriteFailed)
+	}
+}
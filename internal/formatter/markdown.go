@@ -6,16 +6,22 @@ import (
 	"strings"
 	"unicode"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 const schemaConvention = "**Conventions:** `PK` and `UNIQUE` identify unique keys; their backing indexes are omitted from Additional indexes."
 
 // MarkdownFormatter formats schema as markdown
 type MarkdownFormatter struct {
-	writer           io.Writer
-	OmitDatabaseInfo bool
-	OmitTableIndex   bool
+	writer              io.Writer
+	OmitDatabaseInfo    bool
+	OmitTableIndex      bool
+	OmitComments        bool
+	OmitViewDefinitions bool
+
+	// relationTargetLinks maps the tables documented alongside the one being
+	// formatted to the link destination of their documentation
+	relationTargetLinks map[documentedName]string
 }
 
 // NewMarkdownFormatter creates a new markdown formatter
@@ -54,6 +60,11 @@ func (f *MarkdownFormatter) Format(s *schema.Schema) error {
 				return err
 			}
 		}
+		if len(s.Schemas) > 0 {
+			if _, err := fmt.Fprintf(f.writer, "**Schemas:** %s\n", formatSchemaNames(s.Schemas)); err != nil {
+				return err
+			}
+		}
 		if _, err := fmt.Fprintln(f.writer); err != nil {
 			return err
 		}
@@ -64,8 +75,19 @@ func (f *MarkdownFormatter) Format(s *schema.Schema) error {
 		}
 	}
 
-	if !f.OmitTableIndex && len(s.Tables) > 0 {
-		if err := f.formatTableIndex(s.Tables); err != nil {
+	tableEntries, viewEntries := f.reserveHeadingAnchors(s.Tables, s.Views)
+	f.relationTargetLinks = make(map[documentedName]string, len(tableEntries))
+	for _, entry := range tableEntries {
+		if entry.anchor != "" {
+			f.relationTargetLinks[documentedName{schemaName: entry.schemaName, name: entry.name}] = "#" + entry.anchor
+		}
+	}
+
+	if !f.OmitTableIndex && (len(s.Tables) > 0 || len(s.Views) > 0) {
+		if err := f.formatIndexEntries("Tables", tableEntries); err != nil {
+			return err
+		}
+		if err := f.formatIndexEntries("Views", viewEntries); err != nil {
 			return err
 		}
 	}
@@ -75,6 +97,11 @@ func (f *MarkdownFormatter) Format(s *schema.Schema) error {
 			return err
 		}
 	}
+	for _, view := range s.Views {
+		if err := f.formatView(view); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -84,6 +111,14 @@ func shouldFormatSchemaName(s *schema.Schema) bool {
 		!(s.DatabaseType == "MySQL" && s.SchemaName == s.DatabaseName)
 }
 
+func formatSchemaNames(schemaNames []string) string {
+	formatted := make([]string, len(schemaNames))
+	for i, schemaName := range schemaNames {
+		formatted[i] = markdownInlineCode(schemaName)
+	}
+	return strings.Join(formatted, ", ")
+}
+
 func markdownInlineCode(value string) string {
 	normalized := strings.NewReplacer(
 		"\r\n", " ",
@@ -111,27 +146,24 @@ func markdownInlineCode(value string) string {
 	return delimiter + normalized + delimiter
 }
 
-func (f *MarkdownFormatter) formatTableIndex(tables []schema.Table) error {
-	if _, err := fmt.Fprint(f.writer, "**Tables:**\n\n"); err != nil {
-		return err
-	}
-
+// reserveHeadingAnchors returns the index entries of tables and views with the
+// anchors of their headings
+func (f *MarkdownFormatter) reserveHeadingAnchors(tables []schema.Table, views []schema.View) ([]indexEntry, []indexEntry) {
 	usedAnchors := make(map[string]bool)
 	reserveMarkdownHeadingAnchor("Database Schema", usedAnchors)
 
+	// Anchors are reserved in document order, before entries are grouped by schema
+	tableEntries := make([]indexEntry, 0, len(tables))
 	for _, table := range tables {
-		anchor := reserveMarkdownHeadingAnchor(table.Name, usedAnchors)
-		tableName := escapeMarkdownLinkText(table.Name)
-		if anchor == "" {
-			if _, err := fmt.Fprintf(f.writer, "- %s\n", tableName); err != nil {
-				return err
-			}
-		} else {
-			if _, err := fmt.Fprintf(f.writer, "- [%s](#%s)\n", tableName, anchor); err != nil {
-				return err
-			}
-		}
+		tableEntries = append(tableEntries, indexEntry{
+			schemaName: table.Schema,
+			name:       table.Name,
+			anchor:     reserveMarkdownHeadingAnchor(schema.QualifiedName(table.Schema, table.Name), usedAnchors),
+		})
 
+		if len(table.Checks) > 0 {
+			reserveMarkdownHeadingAnchor("Checks", usedAnchors)
+		}
 		if hasAdditionalIndexes(table.Indexes) {
 			reserveMarkdownHeadingAnchor("Additional indexes", usedAnchors)
 		}
@@ -139,7 +171,69 @@ func (f *MarkdownFormatter) formatTableIndex(tables []schema.Table) error {
 			reserveMarkdownHeadingAnchor("References", usedAnchors)
 		}
 	}
-	_, err := fmt.Fprintln(f.writer)
+
+	viewEntries := make([]indexEntry, 0, len(views))
+	for _, view := range views {
+		viewEntries = append(viewEntries, indexEntry{
+			schemaName: view.Schema,
+			name:       view.Name,
+			anchor:     reserveMarkdownHeadingAnchor(schema.QualifiedName(view.Schema, view.Name), usedAnchors),
+		})
+
+		if f.hasViewDefinition(view) {
+			reserveMarkdownHeadingAnchor("Definition", usedAnchors)
+		}
+	}
+
+	return tableEntries, viewEntries
+}
+
+// indexEntry is a table or view listed in the table index
+type indexEntry struct {
+	schemaName string
+	name       string
+	anchor     string
+}
+
+// formatIndexEntries writes one labeled list per schema, in the order the
+// schemas first appear. Entries without a schema share a single list.
+func (f *MarkdownFormatter) formatIndexEntries(label string, entries []indexEntry) error {
+	var schemaNames []string
+	groups := make(map[string][]indexEntry)
+	for _, entry := range entries {
+		if _, ok := groups[entry.schemaName]; !ok {
+			schemaNames = append(schemaNames, entry.schemaName)
+		}
+		groups[entry.schemaName] = append(groups[entry.schemaName], entry)
+	}
+
+	for _, schemaName := range schemaNames {
+		heading := label
+		if schemaName != "" {
+			heading = fmt.Sprintf("%s in %s", label, markdownInlineCode(schemaName))
+		}
+		if _, err := fmt.Fprintf(f.writer, "**%s:**\n\n", heading); err != nil {
+			return err
+		}
+		for _, entry := range groups[schemaName] {
+			if err := f.formatIndexEntry(entry); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(f.writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *MarkdownFormatter) formatIndexEntry(entry indexEntry) error {
+	linkText := escapeMarkdownLinkText(entry.name)
+	if entry.anchor == "" {
+		_, err := fmt.Fprintf(f.writer, "- %s\n", linkText)
+		return err
+	}
+	_, err := fmt.Fprintf(f.writer, "- [%s](#%s)\n", linkText, entry.anchor)
 	return err
 }
 
@@ -186,16 +280,22 @@ func (f *MarkdownFormatter) FormatTable(table schema.Table) error {
 
 func (f *MarkdownFormatter) formatTable(table schema.Table) error {
 	// Table header
-	if _, err := fmt.Fprintf(f.writer, "## %s\n\n", table.Name); err != nil {
+	if _, err := fmt.Fprintf(f.writer, "## %s\n\n", schema.QualifiedName(table.Schema, table.Name)); err != nil {
 		return err
 	}
 
+	if err := f.formatTableComment(f.writer, table.Comment); err != nil {
+		return err
+	}
 	if err := f.FormatColumns(f.writer, table.Columns, table.PrimaryKey, table.Relations); err != nil {
 		return err
 	}
 	if err := f.formatKeyConstraints(f.writer, table.PrimaryKey, table.UniqueKeys); err != nil {
 		return err
 	}
+	if err := f.formatChecks(f.writer, table.Checks); err != nil {
+		return err
+	}
 	if err := f.FormatIndexes(f.writer, table.Indexes); err != nil {
 		return err
 	}
@@ -206,6 +306,77 @@ func (f *MarkdownFormatter) formatTable(table schema.Table) error {
 	return nil
 }
 
+// FormatView formats a single view (exported for use by multifile formatter)
+func (f *MarkdownFormatter) FormatView(view schema.View) error {
+	return f.formatView(view)
+}
+
+func (f *MarkdownFormatter) formatView(view schema.View) error {
+	if _, err := fmt.Fprintf(f.writer, "## %s\n\n", schema.QualifiedName(view.Schema, view.Name)); err != nil {
+		return err
+	}
+
+	if err := f.formatTableComment(f.writer, view.Comment); err != nil {
+		return err
+	}
+	if err := f.formatViewSummary(f.writer, view); err != nil {
+		return err
+	}
+	if err := f.FormatColumns(f.writer, view.Columns, nil, nil); err != nil {
+		return err
+	}
+	return f.formatViewDefinition(f.writer, view)
+}
+
+// formatViewSummary writes the view kind and the relations it reads from
+func (f *MarkdownFormatter) formatViewSummary(w io.Writer, view schema.View) error {
+	kind := view.Kind
+	if kind == "" {
+		kind = schema.ViewKindView
+	}
+	if _, err := fmt.Fprintf(w, "**Kind:** %s\n", kind); err != nil {
+		return err
+	}
+	if len(view.Dependencies) > 0 {
+		if _, err := fmt.Fprintf(w, "**Depends on:** %s\n", strings.Join(view.Dependencies, ", ")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func (f *MarkdownFormatter) hasViewDefinition(view schema.View) bool {
+	return !f.OmitViewDefinitions && strings.TrimSpace(view.Definition) != ""
+}
+
+// formatViewDefinition writes the defining query as a fenced SQL block
+func (f *MarkdownFormatter) formatViewDefinition(w io.Writer, view schema.View) error {
+	if !f.hasViewDefinition(view) {
+		return nil
+	}
+
+	definition := strings.TrimSpace(view.Definition)
+	fence := markdownCodeFence(definition)
+	_, err := fmt.Fprintf(w, "### Definition\n\n%ssql\n%s\n%s\n\n", fence, definition, fence)
+	return err
+}
+
+// markdownCodeFence returns a backtick fence longer than any backtick run in content
+func markdownCodeFence(content string) string {
+	longestRun := 0
+	currentRun := 0
+	for _, r := range content {
+		if r == '`' {
+			currentRun++
+			longestRun = max(longestRun, currentRun)
+		} else {
+			currentRun = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longestRun+1))
+}
+
 // formatKeyConstraints writes composite primary and unique keys that cannot be
 // represented unambiguously by per-column PK and UNIQUE markers.
 func (f *MarkdownFormatter) formatKeyConstraints(w io.Writer, primaryKey []string, uniqueKeys [][]string) error {
@@ -232,52 +403,97 @@ func (f *MarkdownFormatter) formatKeyConstraints(w io.Writer, primaryKey []strin
 	return err
 }
 
+// formatChecks writes CHECK constraints that are not shown on a single column
+func (f *MarkdownFormatter) formatChecks(w io.Writer, checks []schema.CheckConstraint) error {
+	if len(checks) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprint(w, "### Checks\n\n"); err != nil {
+		return err
+	}
+	for _, check := range checks {
+		line := "- " + markdownInlineCode(check.Expression)
+		if check.Name != "" {
+			line += fmt.Sprintf(" (%s)", check.Name)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
 // FormatColumns writes column information as a markdown table
 func (f *MarkdownFormatter) FormatColumns(w io.Writer, columns []schema.Column, primaryKey []string, relations []schema.Relation) error {
-	// Check if any column has CHECK constraints
+	// Optional columns appear only when at least one table column uses them
 	hasConstraints := false
+	hasComments := false
 	for _, col := range columns {
 		if col.CheckConstraint != nil {
 			hasConstraints = true
-			break
+		}
+		if col.Comment != "" && !f.OmitComments {
+			hasComments = true
 		}
 	}
 
 	// Build header
+	header := []string{"Column", "Type"}
 	if hasConstraints {
-		if _, err := fmt.Fprintln(w, "| Column | Type | Constraints |"); err != nil {
-			return err
+		header = append(header, "Constraints")
+	}
+	if hasComments {
+		header = append(header, "Comment")
+	}
+	separator := make([]string, len(header))
+	for i, name := range header {
+		separator[i] = strings.Repeat("-", len(name)+2)
+	}
+	if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | ")); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "|%s|\n", strings.Join(separator, "|")); err != nil {
+		return err
+	}
+
+	for _, col := range columns {
+		// Build type string with PK prefix, nullability, and default
+		cells := []string{col.Name, buildTypeString(col, primaryKey)}
+		if hasConstraints {
+			cells = append(cells, FormatTableConstraints(col, primaryKey))
 		}
-		if _, err := fmt.Fprintln(w, "|--------|------|-------------|"); err != nil {
-			return err
+		if hasComments {
+			cells = append(cells, col.Comment)
 		}
-	} else {
-		if _, err := fmt.Fprintln(w, "| Column | Type |"); err != nil {
-			return err
+		for i, cell := range cells {
+			cells[i] = escapeMarkdownTableCell(cell)
 		}
-		if _, err := fmt.Fprintln(w, "|--------|------|"); err != nil {
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | ")); err != nil {
 			return err
 		}
 	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
 
-	for _, col := range columns {
-		// Build type string with PK prefix, nullability, and default
-		typeStr := buildTypeString(col, primaryKey)
-
-		if hasConstraints {
-			constraints := FormatTableConstraints(col, primaryKey)
-			if _, err := fmt.Fprintf(w, "| %s | %s | %s |\n",
-				escapeMarkdownTableCell(col.Name),
-				escapeMarkdownTableCell(typeStr),
-				escapeMarkdownTableCell(constraints)); err != nil {
-				return err
-			}
-		} else {
-			if _, err := fmt.Fprintf(w, "| %s | %s |\n",
-				escapeMarkdownTableCell(col.Name),
-				escapeMarkdownTableCell(typeStr)); err != nil {
+// formatTableComment writes the table comment as a block quote below the table heading
+func (f *MarkdownFormatter) formatTableComment(w io.Writer, comment string) error {
+	comment = strings.TrimSpace(comment)
+	if comment == "" || f.OmitComments {
+		return nil
+	}
+	lines := strings.Split(strings.ReplaceAll(comment, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			if _, err := fmt.Fprintln(w, ">"); err != nil {
 				return err
 			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "> %s\n", line); err != nil {
+			return err
 		}
 	}
 	_, err := fmt.Fprintln(w)
@@ -323,8 +539,18 @@ func buildTypeString(col schema.Column, primaryKey []string) string {
 		parts = append(parts, "NOT NULL")
 	}
 
-	// Add DEFAULT if present
-	if col.DefaultValue != nil {
+	// Identity columns are filled in by the database when omitted on insert
+	switch col.Identity {
+	case schema.IdentityAlways:
+		parts = append(parts, "IDENTITY ALWAYS")
+	case schema.IdentityByDefault:
+		parts = append(parts, "IDENTITY")
+	}
+
+	// Generated columns are computed by the database and cannot be written
+	if col.Generated != nil {
+		parts = append(parts, formatGenerated(*col.Generated))
+	} else if col.DefaultValue != nil {
 		parts = append(parts, fmt.Sprintf("DEFAULT %s", *col.DefaultValue))
 	}
 
@@ -359,7 +585,7 @@ func (f *MarkdownFormatter) FormatRelations(w io.Writer, tableName string, relat
 		}
 		if _, err := fmt.Fprintf(w, "- %s → %s (%s)\n",
 			formatSourceColumns(relationSourceColumns(rel)),
-			formatRelationTarget(rel),
+			f.formatLinkedRelationTarget(rel),
 			strings.Join(details, "; ")); err != nil {
 			return err
 		}
@@ -384,8 +610,22 @@ func formatRelationTable(rel schema.Relation) string {
 }
 
 func formatRelationTarget(rel schema.Relation) string {
-	table := formatRelationTable(rel)
-	targetColumns := relationTargetColumns(rel)
+	return formatRelationTargetColumns(formatRelationTable(rel), relationTargetColumns(rel))
+}
+
+// formatLinkedRelationTarget formats the target of rel, linking the target
+// table when it is documented alongside the source table. Targets in schemas
+// that were not extracted stay plain text.
+func (f *MarkdownFormatter) formatLinkedRelationTarget(rel schema.Relation) string {
+	destination, ok := f.relationTargetLinks[documentedName{schemaName: rel.TargetSchema, name: rel.TargetTable}]
+	if !ok {
+		return formatRelationTarget(rel)
+	}
+	link := fmt.Sprintf("[%s](%s)", escapeMarkdownLinkText(formatRelationTable(rel)), destination)
+	return formatRelationTargetColumns(link, relationTargetColumns(rel))
+}
+
+func formatRelationTargetColumns(table string, targetColumns []string) string {
 	if len(targetColumns) == 1 {
 		return table + "." + targetColumns[0]
 	}
@@ -463,6 +703,18 @@ func isRepresentedAsUniqueKey(index schema.Index) bool {
 	return index.IsUnique && !index.IsPartial && !index.HasExpressions && len(index.Columns) > 0
 }
 
+// formatGenerated renders a generated column the way it is declared in DDL
+func formatGenerated(generated schema.GeneratedColumn) string {
+	clause := "GENERATED ALWAYS"
+	if generated.Expression != "" {
+		clause += fmt.Sprintf(" AS (%s)", generated.Expression)
+	}
+	if generated.Stored {
+		return clause + " STORED"
+	}
+	return clause + " VIRTUAL"
+}
+
 // FormatTableConstraints formats constraints for table output (only CHECK constraints now)
 func FormatTableConstraints(col schema.Column, primaryKey []string) string {
 	if col.CheckConstraint != nil {
@@ -6,7 +6,7 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 var errWriteFailed = errors.New("write failed")
@@ -186,6 +186,89 @@ func TestFormatIncludesLinkedTableIndexByDefault(t *testing.T) {
 	}
 }
 
+func TestFormatGroupsTableIndexBySchema(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	s := &schema.Schema{
+		DatabaseType: "PostgreSQL",
+		Schemas:      []string{"public", "billing"},
+		Tables: []schema.Table{
+			{Schema: "public", Name: "users"},
+			{Schema: "billing", Name: "invoices", Relations: []schema.Relation{{
+				SourceColumns: []string{"user_id"},
+				TargetSchema:  "public",
+				TargetTable:   "users",
+				TargetColumns: []string{"id"},
+				Cardinality:   "N:1",
+			}}},
+			{Schema: "billing", Name: "users"},
+		},
+		Views: []schema.View{{Schema: "billing", Name: "open_invoices"}},
+	}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	got := output.String()
+	for _, want := range []string{
+		"**Database:** PostgreSQL\n**Schemas:** `public`, `billing`\n",
+		"**Tables in `public`:**\n\n- [users](#publicusers)\n\n" +
+			"**Tables in `billing`:**\n\n- [invoices](#billinginvoices)\n- [users](#billingusers)\n\n" +
+			"**Views in `billing`:**\n\n- [open_invoices](#billingopen_invoices)\n\n",
+		"## public.users\n",
+		"## billing.invoices\n",
+		"- user_id → [public.users](#publicusers).id (many invoices to one users)\n",
+		"## billing.open_invoices\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatLinksRelationTargetsAcrossSchemas(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	formatter.OmitTableIndex = true
+	s := &schema.Schema{
+		Schemas: []string{"billing", "sales"},
+		Tables: []schema.Table{
+			{Schema: "billing", Name: "invoices"},
+			{Schema: "sales", Name: "orders", Relations: []schema.Relation{
+				{
+					SourceColumns: []string{"invoice_id"},
+					TargetSchema:  "billing",
+					TargetTable:   "invoices",
+					TargetColumns: []string{"id"},
+					Cardinality:   "N:1",
+				},
+				{
+					SourceColumns: []string{"user_id"},
+					TargetSchema:  "identity",
+					TargetTable:   "users",
+					TargetColumns: []string{"id"},
+					Cardinality:   "N:1",
+				},
+			}},
+		},
+	}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	got := output.String()
+	for _, want := range []string{
+		"- invoice_id → [billing.invoices](#billinginvoices).id (many orders to one invoices)\n",
+		"- user_id → identity.users.id (many orders to one users)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
 func TestFormatCanOmitTableIndex(t *testing.T) {
 	var output bytes.Buffer
 	formatter := NewMarkdownFormatter(&output)
@@ -320,7 +403,7 @@ func TestSingleFileOmitsReferencedBy(t *testing.T) {
 	}
 
 	got := output.String()
-	if !strings.Contains(got, "user_id → users.id (many orders to one users)") {
+	if !strings.Contains(got, "user_id → [users](#users).id (many orders to one users)") {
 		t.Fatalf("output does not contain outgoing reference:\n%s", got)
 	}
 	if strings.Contains(got, "### Referenced by") {
@@ -351,6 +434,193 @@ func TestFormatColumnsEscapesMarkdownTableCells(t *testing.T) {
 	}
 }
 
+func TestFormatIncludesTableAndColumnComments(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	s := &schema.Schema{Tables: []schema.Table{{
+		Name:    "users",
+		Comment: "Registered accounts.\n\n# Not a heading",
+		Columns: []schema.Column{
+			{Name: "id", Type: "integer"},
+			{Name: "kyc_lvl", Type: "smallint", Nullable: true, Comment: "KYC level | 0 = none"},
+		},
+		PrimaryKey: []string{"id"},
+	}}}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	want := "## users\n\n> Registered accounts.\n>\n> # Not a heading\n\n" +
+		"| Column | Type | Comment |\n" +
+		"|--------|------|---------|\n" +
+		"| id | PK integer NOT NULL |  |\n" +
+		"| kyc_lvl | smallint | KYC level \\| 0 = none |\n"
+	if got := output.String(); !strings.Contains(got, want) {
+		t.Fatalf("output missing %q:\n%s", want, got)
+	}
+}
+
+func TestFormatColumnsOmitsCommentColumnWithoutComments(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+
+	if err := formatter.FormatColumns(&output, []schema.Column{{Name: "id", Type: "integer"}}, nil, nil); err != nil {
+		t.Fatalf("FormatColumns() failed: %v", err)
+	}
+
+	if got, want := output.String(), "| Column | Type |\n|--------|------|\n| id | integer NOT NULL |\n\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestFormatIncludesViews(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	s := &schema.Schema{
+		Tables: []schema.Table{{Name: "users"}},
+		Views: []schema.View{{
+			Name:         "order_totals",
+			Kind:         schema.ViewKindMaterialized,
+			Columns:      []schema.Column{{Name: "user_id", Type: "integer", Nullable: true}},
+			Definition:   " SELECT user_id FROM orders GROUP BY user_id;\n",
+			Dependencies: []string{"orders", "billing.invoices"},
+		}},
+	}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"**Views:**\n\n- [order_totals](#order_totals)\n",
+		"## order_totals\n\n**Kind:** materialized view\n**Depends on:** orders, billing.invoices\n\n",
+		"| user_id | integer |",
+		"### Definition\n\n```sql\nSELECT user_id FROM orders GROUP BY user_id;\n```\n",
+	} {
+		if got := output.String(); !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatCanOmitViewDefinitions(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	formatter.OmitViewDefinitions = true
+	s := &schema.Schema{Views: []schema.View{
+		{Name: "active_users", Definition: "SELECT id FROM users"},
+		{Name: "Definition"},
+	}}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	got := output.String()
+	if strings.Contains(got, "SELECT id FROM users") {
+		t.Errorf("output contains omitted view definition:\n%s", got)
+	}
+	if !strings.Contains(got, "- [Definition](#definition)") {
+		t.Errorf("output reserves an anchor for an omitted definition:\n%s", got)
+	}
+}
+
+func TestFormatTableIndexAccountsForViewDefinitions(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	s := &schema.Schema{Views: []schema.View{
+		{Name: "active_users", Definition: "SELECT id FROM users"},
+		{Name: "Definition"},
+	}}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	if got := output.String(); !strings.Contains(got, "- [Definition](#definition-1)") {
+		t.Errorf("output missing anchor after the generated definition heading:\n%s", got)
+	}
+}
+
+func TestFormatIncludesTableLevelChecks(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	s := &schema.Schema{Tables: []schema.Table{
+		{
+			Name: "bookings",
+			Checks: []schema.CheckConstraint{
+				{Name: "bookings_period_check", Expression: "starts_at < ends_at"},
+				{Expression: "note <> '`'"},
+			},
+		},
+		{Name: "Checks"},
+	}}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"### Checks\n\n- `starts_at < ends_at` (bookings_period_check)\n- `` note <> '`' ``\n\n",
+		"- [Checks](#checks-1)",
+	} {
+		if got := output.String(); !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatColumnsMarksGeneratedColumns(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	defaultValue := "0"
+	columns := []schema.Column{
+		{
+			Name:         "line_total",
+			Type:         "numeric",
+			DefaultValue: &defaultValue,
+			Generated:    &schema.GeneratedColumn{Expression: "quantity * unit_price", Stored: true},
+		},
+		{Name: "label_length", Type: "INTEGER", Nullable: true, Generated: &schema.GeneratedColumn{}},
+	}
+
+	if err := formatter.FormatColumns(&output, columns, nil, nil); err != nil {
+		t.Fatalf("FormatColumns() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"| line_total | numeric NOT NULL GENERATED ALWAYS AS (quantity * unit_price) STORED |",
+		"| label_length | INTEGER GENERATED ALWAYS VIRTUAL |",
+	} {
+		if got := output.String(); !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatColumnsMarksIdentityColumns(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMarkdownFormatter(&output)
+	columns := []schema.Column{
+		{Name: "id", Type: "integer", Identity: schema.IdentityByDefault},
+		{Name: "event_id", Type: "bigint", Identity: schema.IdentityAlways},
+	}
+
+	if err := formatter.FormatColumns(&output, columns, []string{"id"}, nil); err != nil {
+		t.Fatalf("FormatColumns() failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"| id | PK integer NOT NULL IDENTITY |",
+		"| event_id | bigint NOT NULL IDENTITY ALWAYS |",
+	} {
+		if got := output.String(); !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
 func TestFormatIndexesMarksExpressions(t *testing.T) {
 	var output bytes.Buffer
 	formatter := NewMarkdownFormatter(&output)
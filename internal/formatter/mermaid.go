@@ -0,0 +1,156 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// MermaidFormatter formats tables and their relations as a Mermaid erDiagram
+type MermaidFormatter struct {
+	writer       io.Writer
+	OmitComments bool
+}
+
+// NewMermaidFormatter creates a new Mermaid formatter
+func NewMermaidFormatter(w io.Writer) *MermaidFormatter {
+	return &MermaidFormatter{writer: w}
+}
+
+var (
+	mermaidIdentifier      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	mermaidUnsupportedName = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+	mermaidUnsupportedType = regexp.MustCompile(`[^A-Za-z0-9_()\[\]-]+`)
+)
+
+// Format writes the schema as a Mermaid erDiagram. Views are not included.
+func (f *MermaidFormatter) Format(s *schema.Schema) error {
+	if _, err := fmt.Fprintln(f.writer, "erDiagram"); err != nil {
+		return err
+	}
+
+	for _, table := range s.Tables {
+		if err := f.formatEntity(table); err != nil {
+			return err
+		}
+	}
+	for _, table := range s.Tables {
+		for _, rel := range table.Relations {
+			if _, err := fmt.Fprintf(f.writer, "    %s %s %s : %s\n",
+				mermaidEntityName(schema.QualifiedName(table.Schema, table.Name)),
+				mermaidRelationship(table, rel),
+				mermaidEntityName(schema.QualifiedName(rel.TargetSchema, rel.TargetTable)),
+				mermaidString(strings.Join(relationSourceColumns(rel), ", "))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *MermaidFormatter) formatEntity(table schema.Table) error {
+	name := mermaidEntityName(schema.QualifiedName(table.Schema, table.Name))
+	if len(table.Columns) == 0 {
+		_, err := fmt.Fprintf(f.writer, "    %s\n", name)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(f.writer, "    %s {\n", name); err != nil {
+		return err
+	}
+	foreignKeyColumns := make(map[string]bool)
+	for _, rel := range table.Relations {
+		for _, column := range relationSourceColumns(rel) {
+			foreignKeyColumns[column] = true
+		}
+	}
+	for _, col := range table.Columns {
+		var keys []string
+		if slices.Contains(table.PrimaryKey, col.Name) {
+			keys = append(keys, "PK")
+		}
+		if foreignKeyColumns[col.Name] {
+			keys = append(keys, "FK")
+		}
+		if col.IsUnique && !slices.Contains(table.PrimaryKey, col.Name) {
+			keys = append(keys, "UK")
+		}
+
+		attribute := mermaidType(col.Type) + " " + mermaidAttributeName(col.Name)
+		if len(keys) > 0 {
+			attribute += " " + strings.Join(keys, ", ")
+		}
+		if !f.OmitComments && col.Comment != "" {
+			attribute += " " + mermaidString(col.Comment)
+		}
+		if _, err := fmt.Fprintf(f.writer, "        %s\n", attribute); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(f.writer, "    }")
+	return err
+}
+
+// mermaidRelationship returns the crow's foot notation of a relation, read
+// from the source table to the target table. The source side is one row for
+// 1:1 relations and many rows for N:1 relations; the target side is optional
+// when a source column is nullable.
+func mermaidRelationship(table schema.Table, rel schema.Relation) string {
+	source := "}o"
+	if rel.Cardinality == "1:1" {
+		source = "|o"
+	}
+
+	target := "||"
+	for _, column := range relationSourceColumns(rel) {
+		index := slices.IndexFunc(table.Columns, func(col schema.Column) bool {
+			return col.Name == column
+		})
+		if index >= 0 && table.Columns[index].Nullable {
+			target = "o|"
+			break
+		}
+	}
+	return source + "--" + target
+}
+
+// mermaidEntityName quotes names that are not plain Mermaid identifiers,
+// such as schema-qualified names
+func mermaidEntityName(name string) string {
+	if mermaidIdentifier.MatchString(name) {
+		return name
+	}
+	return mermaidString(name)
+}
+
+// mermaidAttributeName replaces characters that Mermaid attribute names cannot contain
+func mermaidAttributeName(name string) string {
+	if mermaidIdentifier.MatchString(name) {
+		return name
+	}
+	return startMermaidIdentifier(mermaidUnsupportedName.ReplaceAllString(name, "_"))
+}
+
+// mermaidType replaces characters that Mermaid attribute types cannot
+// contain, such as the spaces in "character varying(255)"
+func mermaidType(columnType string) string {
+	return startMermaidIdentifier(mermaidUnsupportedType.ReplaceAllString(columnType, "_"))
+}
+
+// startMermaidIdentifier prefixes values that do not start like an identifier
+func startMermaidIdentifier(value string) string {
+	if value == "" || !mermaidIdentifier.MatchString(value[:1]) {
+		return "_" + value
+	}
+	return value
+}
+
+// mermaidString quotes a label; Mermaid strings cannot escape double quotes
+func mermaidString(value string) string {
+	value = strings.NewReplacer(`"`, "'", "\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+	return `"` + value + `"`
+}
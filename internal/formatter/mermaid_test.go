@@ -0,0 +1,113 @@
+package formatter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+func mermaidTestSchema() *schema.Schema {
+	return &schema.Schema{
+		Tables: []schema.Table{
+			{
+				Name:       "users",
+				PrimaryKey: []string{"id"},
+				Columns: []schema.Column{
+					{Name: "id", Type: "integer"},
+					{Name: "email", Type: "character varying(255)", IsUnique: true, Comment: `Login "address"`},
+				},
+			},
+			{
+				Name:       "profiles",
+				PrimaryKey: []string{"user_id"},
+				Columns:    []schema.Column{{Name: "user_id", Type: "integer"}},
+				Relations: []schema.Relation{
+					{TargetTable: "users", SourceColumns: []string{"user_id"}, TargetColumns: []string{"id"}, Cardinality: "1:1"},
+				},
+			},
+			{
+				Name:       "posts",
+				PrimaryKey: []string{"id"},
+				Columns: []schema.Column{
+					{Name: "id", Type: "integer"},
+					{Name: "author id", Type: "integer", Nullable: true},
+				},
+				Relations: []schema.Relation{
+					{TargetSchema: "auth", TargetTable: "accounts", SourceColumns: []string{"author id"}, TargetColumns: []string{"id"}, Cardinality: "N:1"},
+				},
+			},
+			{Name: "empty"},
+		},
+		Views: []schema.View{{Name: "active_users"}},
+	}
+}
+
+func TestMermaidFormatterWritesEntitiesAndRelations(t *testing.T) {
+	var output bytes.Buffer
+	if err := NewMermaidFormatter(&output).Format(mermaidTestSchema()); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	want := `erDiagram
+    users {
+        integer id PK
+        character_varying(255) email UK "Login 'address'"
+    }
+    profiles {
+        integer user_id PK, FK
+    }
+    posts {
+        integer id PK
+        integer author_id FK
+    }
+    empty
+    profiles |o--|| users : "user_id"
+    posts }o--o| "auth.accounts" : "author id"
+`
+	if got := output.String(); got != want {
+		t.Fatalf("Format() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMermaidFormatterCanOmitComments(t *testing.T) {
+	var output bytes.Buffer
+	formatter := NewMermaidFormatter(&output)
+	formatter.OmitComments = true
+	if err := formatter.Format(mermaidTestSchema()); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+	if strings.Contains(output.String(), "Login") {
+		t.Fatalf("Format() included a comment:\n%s", output.String())
+	}
+}
+
+func TestMermaidFormatterPropagatesWriteErrors(t *testing.T) {
+	if err := NewMermaidFormatter(failingWriter{}).Format(mermaidTestSchema()); !errors.Is(err, errWriteFailed) {
+		t.Fatalf("Format() error = %v, want %v", err, errWriteFailed)
+	}
+}
+
+func TestMarkdownOverviewCanEmbedMermaidDiagram(t *testing.T) {
+	outputDir := t.TempDir()
+	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
+	formatter.OverviewDiagram = true
+	if err := formatter.Format(mermaidTestSchema()); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "_overview.md"))
+	if err != nil {
+		t.Fatalf("failed to read overview: %v", err)
+	}
+	if !strings.Contains(string(content), "\n## Diagram\n\n```mermaid\nerDiagram\n    users {\n") {
+		t.Fatalf("overview does not contain a diagram:\n%s", content)
+	}
+	if !strings.HasSuffix(string(content), "    profiles |o--|| users : \"user_id\"\n    posts }o--o| \"auth.accounts\" : \"author id\"\n```\n") {
+		t.Fatalf("overview does not end with the diagram:\n%s", content)
+	}
+}
@@ -1,9 +1,9 @@
 package formatter
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,7 +11,8 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/internal/textdiff"
+	"github.com/tordrt/llmschema/schema"
 )
 
 const (
@@ -24,10 +25,13 @@ const (
 
 // MultiFileFormatter writes schema to multiple files in a directory
 type MultiFileFormatter struct {
-	OutputDir          string
-	OutputFormat       string // "text" or "markdown"
-	OmitDatabaseInfo   bool
-	PreserveStaleFiles bool
+	OutputDir           string
+	OutputFormat        string // "text" or "markdown"
+	OmitDatabaseInfo    bool
+	OmitComments        bool
+	OmitViewDefinitions bool
+	PreserveStaleFiles  bool
+	OverviewDiagram     bool // Embed a Mermaid ER diagram in the markdown overview
 }
 
 // NewMultiFileFormatter creates a new multi-file formatter
@@ -40,7 +44,8 @@ func NewMultiFileFormatter(outputDir, format string) *MultiFileFormatter {
 
 // Format writes the schema to multiple files
 func (f *MultiFileFormatter) Format(s *schema.Schema) error {
-	if err := f.validateTableFileNames(s.Tables); err != nil {
+	files, err := f.renderFiles(s)
+	if err != nil {
 		return err
 	}
 
@@ -54,23 +59,17 @@ func (f *MultiFileFormatter) Format(s *schema.Schema) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Write overview file
-	if err := f.writeOverview(s); err != nil {
-		return fmt.Errorf("failed to write overview: %w", err)
-	}
-
-	// Write per-table files
-	for _, table := range s.Tables {
-		if err := f.writeTableFile(&table, s); err != nil {
-			return fmt.Errorf("failed to write table file for %s: %w", table.Name, err)
+	for _, file := range files {
+		if err := os.WriteFile(filepath.Join(f.OutputDir, file.name), file.content, 0666); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
 		}
 	}
 
-	currentFiles := f.tableFileNames(s.Tables)
-	if f.PreserveStaleFiles {
-		currentFiles = mergeFileNames(previousFiles, currentFiles)
-	} else if err := f.removeStaleGeneratedFiles(previousFiles, currentFiles); err != nil {
-		return err
+	currentFiles := f.manifestFiles(s, previousFiles)
+	if !f.PreserveStaleFiles {
+		if err := f.removeStaleGeneratedFiles(previousFiles, currentFiles); err != nil {
+			return err
+		}
 	}
 	if err := f.writeGeneratedFilesManifest(currentFiles); err != nil {
 		return fmt.Errorf("failed to write generated files manifest: %w", err)
@@ -79,15 +78,145 @@ func (f *MultiFileFormatter) Format(s *schema.Schema) error {
 	return nil
 }
 
-func (f *MultiFileFormatter) tableFileNames(tables []schema.Table) []string {
-	files := make([]string, 0, len(tables))
-	for _, table := range tables {
-		files = append(files, f.tableFileName(table.Name))
+// Diff compares the files Format would write with those in OutputDir without
+// writing anything. It returns a unified diff of the changes, which is empty
+// when the directory is up to date. Generated files that Format would delete
+// as stale are reported as removed, and the generated files manifest is
+// compared like the other files.
+func (f *MultiFileFormatter) Diff(s *schema.Schema) (string, error) {
+	files, err := f.renderFiles(s)
+	if err != nil {
+		return "", err
+	}
+
+	previousFiles, err := f.readGeneratedFilesManifest()
+	if err != nil {
+		return "", fmt.Errorf("failed to read generated files manifest: %w", err)
+	}
+	manifest, err := generatedFilesManifestContent(f.manifestFiles(s, previousFiles))
+	if err != nil {
+		return "", fmt.Errorf("failed to render generated files manifest: %w", err)
+	}
+	files = append(files, generatedFile{name: generatedFilesManifest, content: manifest})
+
+	var diff strings.Builder
+	for _, file := range files {
+		path := filepath.Join(f.OutputDir, file.name)
+		existing, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		oldPath := path
+		if os.IsNotExist(err) {
+			oldPath = ""
+		}
+		diff.WriteString(textdiff.Unified(oldPath, path, existing, file.content))
+	}
+
+	if !f.PreserveStaleFiles {
+		current := make(map[string]bool, len(files))
+		for _, file := range files {
+			current[file.name] = true
+		}
+		for _, name := range previousFiles {
+			if current[name] {
+				continue
+			}
+			path := filepath.Join(f.OutputDir, name)
+			existing, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			diff.WriteString(textdiff.Unified(path, "", existing, nil))
+		}
+	}
+
+	return diff.String(), nil
+}
+
+// generatedFile is the content of one file written by Format
+type generatedFile struct {
+	name    string
+	content []byte
+}
+
+// renderFiles renders the overview and the table and view files in memory
+func (f *MultiFileFormatter) renderFiles(s *schema.Schema) ([]generatedFile, error) {
+	if err := f.validateTableFileNames(documentedNames(s)); err != nil {
+		return nil, err
+	}
+
+	files := make([]generatedFile, 0, 1+len(s.Tables)+len(s.Views))
+
+	var overview bytes.Buffer
+	if err := f.writeOverview(&overview, s); err != nil {
+		return nil, fmt.Errorf("failed to write overview: %w", err)
+	}
+	files = append(files, generatedFile{name: "_overview" + f.getFileExtension(), content: overview.Bytes()})
+
+	for _, table := range s.Tables {
+		var content bytes.Buffer
+		if err := f.writeTableFile(&content, &table, s); err != nil {
+			return nil, fmt.Errorf("failed to write table file for %s: %w", schema.QualifiedName(table.Schema, table.Name), err)
+		}
+		files = append(files, generatedFile{name: f.qualifiedFileName(table.Schema, table.Name), content: content.Bytes()})
+	}
+
+	for _, view := range s.Views {
+		var content bytes.Buffer
+		if err := f.writeViewFile(&content, view); err != nil {
+			return nil, fmt.Errorf("failed to write view file for %s: %w", schema.QualifiedName(view.Schema, view.Name), err)
+		}
+		files = append(files, generatedFile{name: f.qualifiedFileName(view.Schema, view.Name), content: content.Bytes()})
+	}
+
+	return files, nil
+}
+
+func (f *MultiFileFormatter) tableFileNames(names []documentedName) []string {
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		files = append(files, f.qualifiedFileName(name.schemaName, name.name))
 	}
 	sort.Strings(files)
 	return files
 }
 
+// documentedName identifies a table or view that gets its own file
+type documentedName struct {
+	schemaName string
+	name       string
+}
+
+func (n documentedName) String() string {
+	return schema.QualifiedName(n.schemaName, n.name)
+}
+
+// documentedNames returns the names of all tables and views that get their own file
+func documentedNames(s *schema.Schema) []documentedName {
+	names := make([]documentedName, 0, len(s.Tables)+len(s.Views))
+	for _, table := range s.Tables {
+		names = append(names, documentedName{schemaName: table.Schema, name: table.Name})
+	}
+	for _, view := range s.Views {
+		names = append(names, documentedName{schemaName: view.Schema, name: view.Name})
+	}
+	return names
+}
+
+// manifestFiles returns the generated files that the manifest lists once
+// Format has written s
+func (f *MultiFileFormatter) manifestFiles(s *schema.Schema, previousFiles []string) []string {
+	currentFiles := f.tableFileNames(documentedNames(s))
+	if f.PreserveStaleFiles {
+		return mergeFileNames(previousFiles, currentFiles)
+	}
+	return currentFiles
+}
+
 func (f *MultiFileFormatter) readGeneratedFilesManifest() ([]string, error) {
 	content, err := os.ReadFile(filepath.Join(f.OutputDir, generatedFilesManifest))
 	if os.IsNotExist(err) {
@@ -109,12 +238,19 @@ func (f *MultiFileFormatter) readGeneratedFilesManifest() ([]string, error) {
 	return files, nil
 }
 
-func (f *MultiFileFormatter) writeGeneratedFilesManifest(files []string) error {
+func generatedFilesManifestContent(files []string) ([]byte, error) {
 	content, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+func (f *MultiFileFormatter) writeGeneratedFilesManifest(files []string) error {
+	content, err := generatedFilesManifestContent(files)
 	if err != nil {
 		return err
 	}
-	content = append(content, '\n')
 
 	tempFile, err := os.CreateTemp(f.OutputDir, ".llmschema-manifest-*.tmp")
 	if err != nil {
@@ -182,18 +318,7 @@ func mergeFileNames(first, second []string) []string {
 }
 
 // writeOverview writes the overview file
-func (f *MultiFileFormatter) writeOverview(s *schema.Schema) (err error) {
-	ext := f.getFileExtension()
-	filename := filepath.Join(f.OutputDir, "_overview"+ext)
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		err = errors.Join(err, file.Close())
-	}()
-
+func (f *MultiFileFormatter) writeOverview(file io.Writer, s *schema.Schema) error {
 	if f.OutputFormat == formatMarkdown {
 		return f.writeMarkdownOverview(file, s)
 	}
@@ -226,6 +351,11 @@ func (f *MultiFileFormatter) writeMarkdownOverview(file io.Writer, s *schema.Sch
 				return err
 			}
 		}
+		if len(s.Schemas) > 0 {
+			if _, err := fmt.Fprintf(file, "**Schemas:** %s\n", formatSchemaNames(s.Schemas)); err != nil {
+				return err
+			}
+		}
 		if _, err := fmt.Fprintln(file); err != nil {
 			return err
 		}
@@ -235,22 +365,16 @@ func (f *MultiFileFormatter) writeMarkdownOverview(file io.Writer, s *schema.Sch
 			return err
 		}
 	}
-	if _, err := fmt.Fprint(file, "Each table has its own documentation file listed below.\n\n"); err != nil {
+	if _, err := fmt.Fprint(file, overviewFilesSentence(s)); err != nil {
 		return err
 	}
 	if _, err := fmt.Fprintf(file, "## Tables\n\n"); err != nil {
 		return err
 	}
 
-	// Sort tables alphabetically
-	sortedTables := make([]schema.Table, len(s.Tables))
-	copy(sortedTables, s.Tables)
-	sort.Slice(sortedTables, func(i, j int) bool {
-		return sortedTables[i].Name < sortedTables[j].Name
-	})
-
-	for _, table := range sortedTables {
-		if _, err := fmt.Fprintf(file, "- **%s** (file: `%s`)", table.Name, f.tableFileName(table.Name)); err != nil {
+	for _, table := range sortedTables(s.Tables) {
+		if _, err := fmt.Fprintf(file, "- **%s** (file: `%s`)",
+			schema.QualifiedName(table.Schema, table.Name), f.qualifiedFileName(table.Schema, table.Name)); err != nil {
 			return err
 		}
 
@@ -269,26 +393,90 @@ func (f *MultiFileFormatter) writeMarkdownOverview(file io.Writer, s *schema.Sch
 		}
 	}
 
+	if len(s.Views) > 0 {
+		if _, err := fmt.Fprintf(file, "\n## Views\n\n"); err != nil {
+			return err
+		}
+		for _, view := range sortedViews(s.Views) {
+			if _, err := fmt.Fprintf(file, "- **%s** (file: `%s`) (%s)\n",
+				schema.QualifiedName(view.Schema, view.Name), f.qualifiedFileName(view.Schema, view.Name),
+				formatViewOverviewDetails(view, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+
+	if f.OverviewDiagram && len(s.Tables) > 0 {
+		if _, err := fmt.Fprintf(file, "\n## Diagram\n\n```mermaid\n"); err != nil {
+			return err
+		}
+		diagram := NewMermaidFormatter(file)
+		diagram.OmitComments = f.OmitComments
+		if err := diagram.Format(s); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(file, "```\n"); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+func overviewFilesSentence(s *schema.Schema) string {
+	if len(s.Views) > 0 {
+		return "Each table and view has its own documentation file listed below.\n\n"
+	}
+	return "Each table has its own documentation file listed below.\n\n"
+}
+
+// sortedTables orders tables alphabetically by schema, then name
+func sortedTables(tables []schema.Table) []schema.Table {
+	sorted := make([]schema.Table, len(tables))
+	copy(sorted, tables)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Schema != sorted[j].Schema {
+			return sorted[i].Schema < sorted[j].Schema
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+func sortedViews(views []schema.View) []schema.View {
+	sorted := make([]schema.View, len(views))
+	copy(sorted, views)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Schema != sorted[j].Schema {
+			return sorted[i].Schema < sorted[j].Schema
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+func formatViewOverviewDetails(view schema.View, separator string) string {
+	details := view.Kind
+	if details == "" {
+		details = schema.ViewKindView
+	}
+	if len(view.Dependencies) > 0 {
+		details += "; depends on: " + strings.Join(view.Dependencies, separator)
+	}
+	return details
+}
+
 func (f *MultiFileFormatter) writeTextOverview(file io.Writer, s *schema.Schema) error {
 	if _, err := fmt.Fprintf(file, "SCHEMA OVERVIEW\n"); err != nil {
 		return err
 	}
-	if _, err := fmt.Fprint(file, "Each table has its own documentation file listed below.\n\n"); err != nil {
+	if _, err := fmt.Fprint(file, overviewFilesSentence(s)); err != nil {
 		return err
 	}
 
-	// Sort tables alphabetically
-	sortedTables := make([]schema.Table, len(s.Tables))
-	copy(sortedTables, s.Tables)
-	sort.Slice(sortedTables, func(i, j int) bool {
-		return sortedTables[i].Name < sortedTables[j].Name
-	})
-
-	for _, table := range sortedTables {
-		if _, err := fmt.Fprintf(file, "%s (file: %s)", table.Name, f.tableFileName(table.Name)); err != nil {
+	for _, table := range sortedTables(s.Tables) {
+		if _, err := fmt.Fprintf(file, "%s (file: %s)",
+			schema.QualifiedName(table.Schema, table.Name), f.qualifiedFileName(table.Schema, table.Name)); err != nil {
 			return err
 		}
 		if len(table.Relations) > 0 {
@@ -305,37 +493,51 @@ func (f *MultiFileFormatter) writeTextOverview(file io.Writer, s *schema.Schema)
 		}
 	}
 
+	if len(s.Views) > 0 {
+		if _, err := fmt.Fprintf(file, "\nVIEWS\n"); err != nil {
+			return err
+		}
+		for _, view := range sortedViews(s.Views) {
+			if _, err := fmt.Fprintf(file, "%s (file: %s) (%s)\n",
+				schema.QualifiedName(view.Schema, view.Name), f.qualifiedFileName(view.Schema, view.Name),
+				formatViewOverviewDetails(view, ",")); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
 
-// writeTableFile writes a single table to its own file
-func (f *MultiFileFormatter) writeTableFile(table *schema.Table, s *schema.Schema) (err error) {
-	filename := filepath.Join(f.OutputDir, f.tableFileName(table.Name))
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		err = errors.Join(err, file.Close())
-	}()
-
+// writeTableFile writes the file of a single table
+func (f *MultiFileFormatter) writeTableFile(file io.Writer, table *schema.Table, s *schema.Schema) error {
 	if f.OutputFormat == formatMarkdown {
 		// Create a markdown formatter to reuse formatting logic
 		mdFormatter := NewMarkdownFormatter(file)
+		mdFormatter.OmitComments = f.OmitComments
+		mdFormatter.relationTargetLinks = make(map[documentedName]string, len(s.Tables))
+		for _, target := range s.Tables {
+			mdFormatter.relationTargetLinks[documentedName{schemaName: target.Schema, name: target.Name}] = f.qualifiedFileName(target.Schema, target.Name)
+		}
 
 		// Format table header
-		if _, err := fmt.Fprintf(file, "## %s\n\n", table.Name); err != nil {
+		if _, err := fmt.Fprintf(file, "## %s\n\n", schema.QualifiedName(table.Schema, table.Name)); err != nil {
 			return err
 		}
 
 		// Use shared formatting methods
+		if err := mdFormatter.formatTableComment(file, table.Comment); err != nil {
+			return err
+		}
 		if err := mdFormatter.FormatColumns(file, table.Columns, table.PrimaryKey, table.Relations); err != nil {
 			return err
 		}
 		if err := mdFormatter.formatKeyConstraints(file, table.PrimaryKey, table.UniqueKeys); err != nil {
 			return err
 		}
+		if err := mdFormatter.formatChecks(file, table.Checks); err != nil {
+			return err
+		}
 		if err := mdFormatter.FormatIndexes(file, table.Indexes); err != nil {
 			return err
 		}
@@ -344,7 +546,7 @@ func (f *MultiFileFormatter) writeTableFile(table *schema.Table, s *schema.Schem
 		}
 
 		// Add incoming relationships
-		incomingRels := f.findIncomingRelations(table.Name, s)
+		incomingRels := f.findIncomingRelations(table.Schema, table.Name, s)
 		if len(incomingRels) > 0 {
 			if _, err := fmt.Fprintf(file, "### Referenced by\n\n"); err != nil {
 				return err
@@ -367,6 +569,18 @@ func (f *MultiFileFormatter) writeTableFile(table *schema.Table, s *schema.Schem
 	return nil
 }
 
+// writeViewFile writes the file of a single view
+func (f *MultiFileFormatter) writeViewFile(file io.Writer, view schema.View) error {
+	if f.OutputFormat == formatMarkdown {
+		mdFormatter := NewMarkdownFormatter(file)
+		mdFormatter.OmitComments = f.OmitComments
+		mdFormatter.OmitViewDefinitions = f.OmitViewDefinitions
+		return mdFormatter.formatView(view)
+	}
+
+	return nil
+}
+
 // IncomingRelation represents a relationship pointing to this table
 type IncomingRelation struct {
 	SourceTable string
@@ -374,17 +588,19 @@ type IncomingRelation struct {
 }
 
 // findIncomingRelations finds all foreign keys pointing to this table
-func (f *MultiFileFormatter) findIncomingRelations(tableName string, s *schema.Schema) []IncomingRelation {
+func (f *MultiFileFormatter) findIncomingRelations(schemaName, tableName string, s *schema.Schema) []IncomingRelation {
 	var incoming []IncomingRelation
 
 	for _, table := range s.Tables {
 		for _, rel := range table.Relations {
-			// Extractors normalize references to their own schema to an empty
-			// TargetSchema. A qualified target belongs to an external schema,
-			// even when it happens to share this local table's name.
-			if rel.TargetSchema == "" && rel.TargetTable == tableName {
+			// Single-schema extractors normalize references to their own schema
+			// to an empty TargetSchema, and multi-schema extractions qualify
+			// both tables and targets. A qualified target on an unqualified
+			// table belongs to an external schema, even when it happens to
+			// share this local table's name.
+			if rel.TargetSchema == schemaName && rel.TargetTable == tableName {
 				incoming = append(incoming, IncomingRelation{
-					SourceTable: table.Name,
+					SourceTable: schema.QualifiedName(table.Schema, table.Name),
 					Relation:    rel,
 				})
 			}
@@ -417,8 +633,20 @@ func (f *MultiFileFormatter) getFileExtension() string {
 // encoded as ~ followed by two lowercase hexadecimal digits. Encoding uppercase
 // bytes also prevents collisions on case-insensitive filesystems.
 func (f *MultiFileFormatter) tableFileName(tableName string) string {
+	return f.qualifiedFileName("", tableName)
+}
+
+// qualifiedFileName returns the filename of a table or view in schemaName.
+// Tables from multi-schema extractions are written as schema.table; the
+// separating dot is unambiguous because dots within names are encoded.
+func (f *MultiFileFormatter) qualifiedFileName(schemaName, tableName string) string {
 	stem := encodeTableFileStem(tableName)
-	if isReservedTableFileStem(stem) {
+	if schemaName != "" {
+		stem = encodeTableFileStem(schemaName) + "." + stem
+	}
+	// Windows reserves device names even when followed by an extension, so
+	// only the part before the first dot needs checking.
+	if reservedStem, _, _ := strings.Cut(stem, "."); isReservedTableFileStem(reservedStem) {
 		// The encoding is injective because a literal '~' is itself encoded.
 		stem = fmt.Sprintf("~%02x%s", stem[0], stem[1:])
 	}
@@ -426,7 +654,7 @@ func (f *MultiFileFormatter) tableFileName(tableName string) string {
 	ext := f.getFileExtension()
 	maxStemBytes := maxGeneratedFileNameBytes - len(ext)
 	if len(stem) > maxStemBytes {
-		digest := sha256.Sum256([]byte(tableName))
+		digest := sha256.Sum256([]byte(schema.QualifiedName(schemaName, tableName)))
 		hexDigest := fmt.Sprintf("%x", digest)
 		suffix := "~" + hexDigest[:truncatedNameHashHexChars]
 		stem = stem[:maxStemBytes-len(suffix)] + suffix
@@ -434,19 +662,19 @@ func (f *MultiFileFormatter) tableFileName(tableName string) string {
 	return stem + ext
 }
 
-func (f *MultiFileFormatter) validateTableFileNames(tables []schema.Table) error {
-	seen := make(map[string]string, len(tables))
-	for _, table := range tables {
-		filename := f.tableFileName(table.Name)
+func (f *MultiFileFormatter) validateTableFileNames(names []documentedName) error {
+	seen := make(map[string]documentedName, len(names))
+	for _, name := range names {
+		filename := f.qualifiedFileName(name.schemaName, name.name)
 		previousTable, exists := seen[filename]
 		if !exists {
-			seen[filename] = table.Name
+			seen[filename] = name
 			continue
 		}
-		if previousTable == table.Name {
-			return fmt.Errorf("duplicate table %q would write %q more than once", table.Name, filename)
+		if previousTable == name {
+			return fmt.Errorf("duplicate table %q would write %q more than once", name, filename)
 		}
-		return fmt.Errorf("table filename collision: %q and %q both map to %q", previousTable, table.Name, filename)
+		return fmt.Errorf("table filename collision: %q and %q both map to %q", previousTable, name, filename)
 	}
 	return nil
 }
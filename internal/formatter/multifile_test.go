@@ -8,7 +8,7 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 func TestOverviewFormattersPropagateWriteErrors(t *testing.T) {
@@ -171,6 +171,97 @@ func TestTableFileIncludesReferencedBy(t *testing.T) {
 	}
 }
 
+func TestTableFileIncludesComments(t *testing.T) {
+	outputDir := t.TempDir()
+	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
+	s := &schema.Schema{Tables: []schema.Table{{
+		Name:    "users",
+		Comment: "Registered accounts",
+		Columns: []schema.Column{{Name: "kyc_lvl", Type: "smallint", Comment: "KYC level"}},
+	}}}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "users.md"))
+	if err != nil {
+		t.Fatalf("failed to read users table file: %v", err)
+	}
+	for _, want := range []string{
+		"## users\n\n> Registered accounts\n\n",
+		"| kyc_lvl | smallint NOT NULL | KYC level |",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("table file missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestTableFileCanOmitComments(t *testing.T) {
+	outputDir := t.TempDir()
+	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
+	formatter.OmitComments = true
+	s := &schema.Schema{Tables: []schema.Table{{
+		Name:    "users",
+		Comment: "Registered accounts",
+		Columns: []schema.Column{{Name: "kyc_lvl", Type: "smallint", Comment: "KYC level"}},
+	}}}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "users.md"))
+	if err != nil {
+		t.Fatalf("failed to read users table file: %v", err)
+	}
+	if strings.Contains(string(content), "Registered accounts") || strings.Contains(string(content), "KYC level") {
+		t.Fatalf("table file contains omitted comments:\n%s", content)
+	}
+}
+
+func TestMultiFileFormatterWritesViewFiles(t *testing.T) {
+	outputDir := t.TempDir()
+	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
+	s := &schema.Schema{
+		Tables: []schema.Table{{Name: "users"}},
+		Views: []schema.View{{
+			Name:         "active_users",
+			Kind:         schema.ViewKindView,
+			Columns:      []schema.Column{{Name: "id", Type: "integer", Nullable: true}},
+			Definition:   "SELECT id FROM users",
+			Dependencies: []string{"users"},
+		}},
+	}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	overview, err := os.ReadFile(filepath.Join(outputDir, "_overview.md"))
+	if err != nil {
+		t.Fatalf("failed to read overview: %v", err)
+	}
+	if want := "## Views\n\n- **active_users** (file: `active_users.md`) (view; depends on: users)"; !strings.Contains(string(overview), want) {
+		t.Errorf("overview missing %q:\n%s", want, overview)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "active_users.md"))
+	if err != nil {
+		t.Fatalf("failed to read view file: %v", err)
+	}
+	for _, want := range []string{
+		"## active_users\n\n**Kind:** view\n**Depends on:** users\n\n",
+		"| id | integer |",
+		"### Definition\n\n```sql\nSELECT id FROM users\n```\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("view file missing %q:\n%s", want, content)
+		}
+	}
+}
+
 func TestMarkdownMultiFileExplainsAndFormatsKeys(t *testing.T) {
 	outputDir := t.TempDir()
 	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
@@ -490,8 +581,209 @@ func TestFindIncomingRelationsExcludesExternalSchemas(t *testing.T) {
 		{Name: "external_profiles", Relations: []schema.Relation{{TargetSchema: "identity", TargetTable: "users"}}},
 	}}
 
-	incoming := formatter.findIncomingRelations("users", s)
+	incoming := formatter.findIncomingRelations("", "users", s)
 	if len(incoming) != 1 || incoming[0].SourceTable != "local_profiles" {
 		t.Fatalf("incoming relations = %#v, want only local_profiles", incoming)
 	}
 }
+
+func TestMultiFileFormatterResolvesReferencesBetweenSchemas(t *testing.T) {
+	outputDir := t.TempDir()
+	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
+	s := &schema.Schema{
+		Schemas: []string{"auth", "billing"},
+		Tables: []schema.Table{
+			{Schema: "auth", Name: "users"},
+			{Schema: "billing", Name: "users"},
+			{Schema: "billing", Name: "invoices", Relations: []schema.Relation{{
+				SourceColumns: []string{"user_id"},
+				TargetSchema:  "auth",
+				TargetTable:   "users",
+				TargetColumns: []string{"id"},
+				Cardinality:   "N:1",
+			}}},
+		},
+	}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "auth.users.md"))
+	if err != nil {
+		t.Fatalf("failed to read auth.users table file: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "## auth.users\n") ||
+		!strings.Contains(string(content), "### Referenced by\n\n- billing.invoices.user_id → id") {
+		t.Fatalf("auth.users file does not resolve the billing reference:\n%s", content)
+	}
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "billing.users.md"))
+	if err != nil {
+		t.Fatalf("failed to read billing.users table file: %v", err)
+	}
+	if strings.Contains(string(content), "Referenced by") {
+		t.Fatalf("billing.users file includes a reference to auth.users:\n%s", content)
+	}
+
+	overview, err := os.ReadFile(filepath.Join(outputDir, "_overview.md"))
+	if err != nil {
+		t.Fatalf("failed to read overview: %v", err)
+	}
+	if !strings.Contains(string(overview), "- **billing.invoices** (file: `billing.invoices.md`) (references: auth.users)\n") {
+		t.Fatalf("overview does not list the qualified table:\n%s", overview)
+	}
+}
+
+func TestTableFileLinksRelationTargetsAcrossSchemas(t *testing.T) {
+	outputDir := t.TempDir()
+	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
+	s := &schema.Schema{
+		Schemas: []string{"billing", "sales"},
+		Tables: []schema.Table{
+			{Schema: "billing", Name: "invoices"},
+			{Schema: "sales", Name: "orders", Relations: []schema.Relation{
+				{
+					SourceColumns: []string{"invoice_id"},
+					TargetSchema:  "billing",
+					TargetTable:   "invoices",
+					TargetColumns: []string{"id"},
+					Cardinality:   "N:1",
+				},
+				{
+					SourceColumns: []string{"user_id"},
+					TargetSchema:  "identity",
+					TargetTable:   "users",
+					TargetColumns: []string{"id"},
+					Cardinality:   "N:1",
+				},
+			}},
+		},
+	}
+
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "sales.orders.md"))
+	if err != nil {
+		t.Fatalf("failed to read sales.orders table file: %v", err)
+	}
+	for _, want := range []string{
+		"- invoice_id → [billing.invoices](billing.invoices.md).id (many orders to one invoices)\n",
+		"- user_id → identity.users.id (many orders to one users)\n",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("sales.orders file does not contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestQualifiedFileNameEncodesSchemaSeparately(t *testing.T) {
+	formatter := NewMultiFileFormatter(t.TempDir(), formatMarkdown)
+	tests := []struct {
+		schemaName string
+		tableName  string
+		want       string
+	}{
+		{schemaName: "", tableName: "users", want: "users.md"},
+		{schemaName: "billing", tableName: "users", want: "billing.users.md"},
+		{schemaName: "a.b", tableName: "c", want: "a~2eb.c.md"},
+		{schemaName: "a", tableName: "b.c", want: "a.b~2ec.md"},
+		{schemaName: "con", tableName: "users", want: "~63on.users.md"},
+	}
+
+	for _, tt := range tests {
+		if got := formatter.qualifiedFileName(tt.schemaName, tt.tableName); got != tt.want {
+			t.Errorf("qualifiedFileName(%q, %q) = %q, want %q", tt.schemaName, tt.tableName, got, tt.want)
+		}
+	}
+}
+
+func TestMultiFileFormatterDiffReportsChangesWithoutWriting(t *testing.T) {
+	outputDir := t.TempDir()
+	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
+	initialSchema := &schema.Schema{Tables: []schema.Table{{Name: "users"}, {Name: "posts"}}}
+	if err := formatter.Format(initialSchema); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "notes.md"), []byte("supplemental"), 0644); err != nil {
+		t.Fatalf("failed to write supplemental file: %v", err)
+	}
+
+	diff, err := formatter.Diff(initialSchema)
+	if err != nil {
+		t.Fatalf("Diff() of unchanged schema failed: %v", err)
+	}
+	if diff != "" {
+		t.Errorf("Diff() of unchanged schema = %q, want no changes", diff)
+	}
+
+	diff, err = formatter.Diff(&schema.Schema{Tables: []schema.Table{{Name: "users"}, {Name: "comments"}}})
+	if err != nil {
+		t.Fatalf("Diff() failed: %v", err)
+	}
+	for _, want := range []string{
+		"--- a/" + filepath.Join(outputDir, "_overview.md") + "\n",
+		"--- /dev/null\n+++ b/" + filepath.Join(outputDir, "comments.md") + "\n",
+		"--- a/" + filepath.Join(outputDir, "posts.md") + "\n+++ /dev/null\n",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Diff() does not contain %q:\n%s", want, diff)
+		}
+	}
+	if strings.Contains(diff, "notes.md") {
+		t.Errorf("Diff() reports the supplemental file:\n%s", diff)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "comments.md")); !os.IsNotExist(err) {
+		t.Errorf("Diff() created comments.md; stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "posts.md")); err != nil {
+		t.Errorf("Diff() removed posts.md: %v", err)
+	}
+
+	formatter.PreserveStaleFiles = true
+	diff, err = formatter.Diff(&schema.Schema{Tables: []schema.Table{{Name: "users"}}})
+	if err != nil {
+		t.Fatalf("Diff() with preserved stale files failed: %v", err)
+	}
+	if strings.Contains(diff, "posts.md\n+++ /dev/null") {
+		t.Errorf("Diff() reports a preserved stale file as removed:\n%s", diff)
+	}
+}
+
+func TestMultiFileFormatterDiffComparesManifest(t *testing.T) {
+	outputDir := t.TempDir()
+	formatter := NewMultiFileFormatter(outputDir, formatMarkdown)
+	s := &schema.Schema{Tables: []schema.Table{{Name: "users"}}}
+	if err := formatter.Format(s); err != nil {
+		t.Fatalf("Format() failed: %v", err)
+	}
+	manifestPath := filepath.Join(outputDir, generatedFilesManifest)
+
+	if err := os.WriteFile(manifestPath, []byte("[\n  \"posts.md\",\n  \"users.md\"\n]\n"), 0644); err != nil {
+		t.Fatalf("failed to write stale manifest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "posts.md"), []byte("## posts\n"), 0644); err != nil {
+		t.Fatalf("failed to write stale table file: %v", err)
+	}
+	diff, err := formatter.Diff(s)
+	if err != nil {
+		t.Fatalf("Diff() with a stale manifest failed: %v", err)
+	}
+	if !strings.Contains(diff, "--- a/"+manifestPath+"\n+++ b/"+manifestPath+"\n") || !strings.Contains(diff, "-  \"posts.md\",\n") {
+		t.Errorf("Diff() does not update the stale manifest:\n%s", diff)
+	}
+
+	if err := os.Remove(manifestPath); err != nil {
+		t.Fatalf("failed to remove manifest: %v", err)
+	}
+	diff, err = formatter.Diff(s)
+	if err != nil {
+		t.Fatalf("Diff() without a manifest failed: %v", err)
+	}
+	if !strings.Contains(diff, "--- /dev/null\n+++ b/"+manifestPath+"\n") {
+		t.Errorf("Diff() does not create the missing manifest:\n%s", diff)
+	}
+}
@@ -0,0 +1,233 @@
+// Package textdiff renders line-based unified diffs.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+type operation int
+
+const (
+	equal operation = iota
+	deleted
+	inserted
+)
+
+type edit struct {
+	op   operation
+	line string
+}
+
+// Unified returns a unified diff from old to new, or "" when they are equal.
+// An empty oldName marks a created file and an empty newName a deleted one,
+// which are labelled /dev/null as in git.
+func Unified(oldName, newName string, old, new []byte) string {
+	if string(old) == string(new) {
+		return ""
+	}
+
+	edits := diffLines(splitLines(string(old)), splitLines(string(new)))
+
+	var out strings.Builder
+	_, _ = fmt.Fprintf(&out, "--- %s\n+++ %s\n", label(oldName, "a/"), label(newName, "b/"))
+	for _, h := range hunks(edits) {
+		out.WriteString(h)
+	}
+	return out.String()
+}
+
+func label(name, prefix string) string {
+	if name == "" {
+		return "/dev/null"
+	}
+	return prefix + name
+}
+
+// splitLines splits content after each newline, keeping the newlines so that
+// a missing final newline is detected as a change
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns a shortest edit script from a to b using Myers' algorithm,
+// after removing the common prefix and suffix
+func diffLines(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{op: equal, line: line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{op: equal, line: line})
+	}
+	return edits
+}
+
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	maxEdits := n + m
+	offset := maxEdits + 1
+	v := make([]int, 2*maxEdits+3)
+
+	// trace[d] holds the furthest x on diagonals -d..d after d edits
+	var trace [][]int
+	for d := 0; d <= maxEdits; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+	return nil
+}
+
+// backtrack follows trace back from the end of both inputs and returns the
+// edits in forward order
+func backtrack(a, b []string, trace [][]int) []edit {
+	furthest := func(d, k int) int {
+		return trace[d][k+d]
+	}
+
+	var reversed []edit
+	x, y := len(a), len(b)
+	for d := len(trace); d > 0; d-- {
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && furthest(d-1, k-1) < furthest(d-1, k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := furthest(d-1, prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, edit{op: equal, line: a[x]})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, edit{op: inserted, line: b[y]})
+		} else {
+			x--
+			reversed = append(reversed, edit{op: deleted, line: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, edit{op: equal, line: a[x]})
+	}
+
+	edits := make([]edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
+
+// hunks groups the edits into hunks with surrounding context
+func hunks(edits []edit) []string {
+	var result []string
+	oldLine, newLine := 1, 1
+	for start := 0; start < len(edits); {
+		if edits[start].op == equal {
+			oldLine++
+			newLine++
+			start++
+			continue
+		}
+
+		// Extend the hunk while changes are separated by little enough context
+		end := start
+		for i := start; i < len(edits); i++ {
+			if edits[i].op != equal {
+				end = i + 1
+				continue
+			}
+			if i-end >= 2*contextLines {
+				break
+			}
+		}
+
+		before := min(contextLines, start)
+		after := min(contextLines, len(edits)-end)
+		hunkStart, hunkEnd := start-before, end+after
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		for _, e := range edits[hunkStart:hunkEnd] {
+			switch e.op {
+			case equal:
+				body.WriteString(" ")
+				oldCount++
+				newCount++
+			case deleted:
+				body.WriteString("-")
+				oldCount++
+			case inserted:
+				body.WriteString("+")
+				newCount++
+			}
+			body.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		oldStart, newStart := oldLine-before, newLine-before
+		result = append(result, fmt.Sprintf("@@ -%s +%s @@\n%s", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount), body.String()))
+
+		for _, e := range edits[start:hunkEnd] {
+			if e.op != inserted {
+				oldLine++
+			}
+			if e.op != deleted {
+				newLine++
+			}
+		}
+		start = hunkEnd
+	}
+	return result
+}
+
+// hunkRange formats a hunk range; empty ranges start at the line before them
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,100 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+		old     string
+		new     string
+		want    string
+	}{
+		{
+			name:    "equal content",
+			oldName: "schema.md",
+			newName: "schema.md",
+			old:     "a\nb\n",
+			new:     "a\nb\n",
+			want:    "",
+		},
+		{
+			name:    "changed line with context",
+			oldName: "schema.md",
+			newName: "schema.md",
+			old:     "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			new:     "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- a/schema.md\n+++ b/schema.md\n" +
+				"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:    "distant changes use separate hunks",
+			oldName: "schema.md",
+			newName: "schema.md",
+			old:     "a\n1\n2\n3\n4\n5\n6\n7\nb\n",
+			new:     "A\n1\n2\n3\n4\n5\n6\n7\nB\n",
+			want: "--- a/schema.md\n+++ b/schema.md\n" +
+				"@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n" +
+				"@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-b\n+B\n",
+		},
+		{
+			name:    "created file",
+			newName: "users.md",
+			new:     "## users\n\n",
+			want:    "--- /dev/null\n+++ b/users.md\n@@ -0,0 +1,2 @@\n+## users\n+\n",
+		},
+		{
+			name:    "deleted file",
+			oldName: "users.md",
+			old:     "## users\n",
+			want:    "--- a/users.md\n+++ /dev/null\n@@ -1 +0,0 @@\n-## users\n",
+		},
+		{
+			name:    "missing final newline",
+			oldName: "schema.md",
+			newName: "schema.md",
+			old:     "a\nb",
+			new:     "a\nb\n",
+			want:    "--- a/schema.md\n+++ b/schema.md\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified(tt.oldName, tt.newName, []byte(tt.old), []byte(tt.new))
+			if got != tt.want {
+				t.Errorf("Unified() mismatch\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesProducesBothInputs(t *testing.T) {
+	a := strings.SplitAfter("a\nb\nc\na\nb\nb\na\n", "\n")
+	b := strings.SplitAfter("c\nb\na\nb\na\nc\n", "\n")
+
+	var gotA, gotB []string
+	changes := 0
+	for _, e := range diffLines(a, b) {
+		if e.op != inserted {
+			gotA = append(gotA, e.line)
+		}
+		if e.op != deleted {
+			gotB = append(gotB, e.line)
+		}
+		if e.op != equal {
+			changes++
+		}
+	}
+	if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+		t.Fatalf("edits do not reproduce the inputs: %q, %q", gotA, gotB)
+	}
+	// The shortest edit script for this classic example has five changes
+	if changes != 5 {
+		t.Errorf("edit script has %d changes, want 5", changes)
+	}
+}
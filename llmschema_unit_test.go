@@ -2,11 +2,18 @@ package llmschema
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 func TestMySQLSchemaNameErrorProvidesLibraryAndCLIGuidance(t *testing.T) {
@@ -69,3 +76,681 @@ func TestFormatSchemaCanOmitDatabaseInfo(t *testing.T) {
 		t.Fatalf("output contains omitted database info:\n%s", output.String())
 	}
 }
+
+func TestFormatSchemaCanOmitComments(t *testing.T) {
+	s := &schema.Schema{Tables: []schema.Table{{
+		Name:    "users",
+		Comment: "Registered accounts",
+		Columns: []schema.Column{{Name: "kyc_lvl", Type: "smallint", Comment: "KYC level"}},
+	}}}
+
+	var defaultOutput bytes.Buffer
+	if err := FormatSchema(s, &OutputOptions{Writer: &defaultOutput}); err != nil {
+		t.Fatalf("FormatSchema() with defaults failed: %v", err)
+	}
+	for _, want := range []string{"> Registered accounts", "| kyc_lvl | smallint NOT NULL | KYC level |"} {
+		if !strings.Contains(defaultOutput.String(), want) {
+			t.Errorf("default output missing %q:\n%s", want, defaultOutput.String())
+		}
+	}
+
+	var outputWithoutComments bytes.Buffer
+	if err := FormatSchema(s, &OutputOptions{
+		Writer:       &outputWithoutComments,
+		OmitComments: true,
+	}); err != nil {
+		t.Fatalf("FormatSchema() without comments failed: %v", err)
+	}
+	if strings.Contains(outputWithoutComments.String(), "Registered accounts") ||
+		strings.Contains(outputWithoutComments.String(), "KYC level") ||
+		strings.Contains(outputWithoutComments.String(), "| Comment |") {
+		t.Fatalf("output contains omitted comments:\n%s", outputWithoutComments.String())
+	}
+}
+
+func TestFilterExcludedTablesRemovesViews(t *testing.T) {
+	s := &schema.Schema{
+		Tables: []schema.Table{{Name: "users"}, {Name: "audit_log"}},
+		Views:  []schema.View{{Name: "active_users"}, {Name: "audit_summary"}},
+	}
+
+	filterExcludedTables(s, []string{"audit_log", "audit_summary"})
+
+	if len(s.Tables) != 1 || s.Tables[0].Name != "users" {
+		t.Errorf("tables = %v, want only users", s.Tables)
+	}
+	if len(s.Views) != 1 || s.Views[0].Name != "active_users" {
+		t.Errorf("views = %v, want only active_users", s.Views)
+	}
+}
+
+func TestFilterExcludedTablesMatchesQualifiedNames(t *testing.T) {
+	s := &schema.Schema{Tables: []schema.Table{
+		{Schema: "public", Name: "migrations"},
+		{Schema: "billing", Name: "migrations"},
+		{Schema: "billing", Name: "invoices"},
+		{Schema: "auth", Name: "audit_log"},
+	}}
+
+	filterExcludedTables(s, []string{"billing.migrations", "audit_log"})
+
+	var names []string
+	for _, table := range s.Tables {
+		names = append(names, schema.QualifiedName(table.Schema, table.Name))
+	}
+	if strings.Join(names, ",") != "public.migrations,billing.invoices" {
+		t.Errorf("tables = %v, want public.migrations and billing.invoices", names)
+	}
+}
+
+func TestRequestedSchemaNames(t *testing.T) {
+	schemaNames, err := requestedSchemaNames(&Options{SchemaName: "public", SchemaNames: []string{"billing", "public", "auth"}})
+	if err != nil {
+		t.Fatalf("requestedSchemaNames() failed: %v", err)
+	}
+	if strings.Join(schemaNames, ",") != "public,billing,auth" {
+		t.Errorf("schema names = %v, want public, billing, auth", schemaNames)
+	}
+
+	if _, err := requestedSchemaNames(&Options{SchemaName: "public", AllSchemas: true}); err == nil {
+		t.Error("requestedSchemaNames() accepted AllSchemas with SchemaName")
+	}
+	if _, err := singleSchemaName(&Options{SchemaNames: []string{"a", "b"}}, "MySQL"); err == nil {
+		t.Error("singleSchemaName() accepted multiple schemas")
+	}
+	if schemaName, err := singleSchemaName(&Options{SchemaNames: []string{"app"}}, "MySQL"); err != nil || schemaName != "app" {
+		t.Errorf("singleSchemaName() = %q, %v, want app", schemaName, err)
+	}
+}
+
+func TestExtractSchemaDispatchesToRegisteredExtractor(t *testing.T) {
+	t.Cleanup(func() {
+		extractorsMu.Lock()
+		delete(extractors, "inhouse")
+		extractorsMu.Unlock()
+	})
+
+	var gotURL string
+	var gotOpts *Options
+	RegisterExtractor("InHouse", ExtractorFunc(func(_ context.Context, databaseURL string, opts *Options) (*schema.Schema, error) {
+		gotURL = databaseURL
+		gotOpts = opts
+		return &schema.Schema{DatabaseType: "InHouse"}, nil
+	}))
+
+	s, err := ExtractSchema(context.Background(), "inhouse://catalog", nil)
+	if err != nil {
+		t.Fatalf("ExtractSchema() failed: %v", err)
+	}
+	if s.DatabaseType != "InHouse" {
+		t.Errorf("database type = %q, want InHouse", s.DatabaseType)
+	}
+	if gotURL != "inhouse://catalog" {
+		t.Errorf("extractor URL = %q, want the complete URL", gotURL)
+	}
+	if gotOpts == nil {
+		t.Error("extractor options are nil, want defaults")
+	}
+}
+
+func TestRegisterExtractorCanWrapBuiltInExtractor(t *testing.T) {
+	builtIn, ok := LookupExtractor("sqlite")
+	if !ok {
+		t.Fatal("sqlite extractor is not registered")
+	}
+	t.Cleanup(func() { RegisterExtractor("sqlite", builtIn) })
+
+	var calls int
+	RegisterExtractor("sqlite", ExtractorFunc(func(ctx context.Context, databaseURL string, opts *Options) (*schema.Schema, error) {
+		calls++
+		return builtIn.ExtractSchema(ctx, databaseURL, opts)
+	}))
+
+	_, err := ExtractSchema(context.Background(), "sqlite://"+filepath.Join(t.TempDir(), "missing", "test.db"), nil)
+	if calls != 1 {
+		t.Errorf("wrapper calls = %d, want 1", calls)
+	}
+	if err == nil {
+		t.Error("ExtractSchema() succeeded for a missing database directory")
+	}
+}
+
+func TestParseDatabaseURLListsRegisteredSchemes(t *testing.T) {
+	_, err := parseDatabaseURL("oracle://db")
+	if err == nil {
+		t.Fatal("parseDatabaseURL() accepted an unregistered scheme")
+	}
+	for _, scheme := range []string{"mysql://", "postgres://", "sqlite://"} {
+		if !strings.Contains(err.Error(), scheme) {
+			t.Errorf("error %q does not list %s", err, scheme)
+		}
+	}
+}
+
+func TestRegisterExtractorRejectsInvalidSchemes(t *testing.T) {
+	for _, scheme := range []string{"", "postgres://"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterExtractor(%q) did not panic", scheme)
+				}
+			}()
+			RegisterExtractor(scheme, postgresExtractor{})
+		}()
+	}
+}
+
+func TestExtractSchemaFromDBLeavesConnectionOpen(t *testing.T) {
+	ctx := context.Background()
+	sqlDB, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatalf("failed to open SQLite database: %v", err)
+	}
+	defer func() { _ = sqlDB.Close() }()
+	if _, err := sqlDB.ExecContext(ctx, `CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	s, err := ExtractSchemaFromDB(ctx, sqlDB, DialectSQLite, nil)
+	if err != nil {
+		t.Fatalf("ExtractSchemaFromDB() failed: %v", err)
+	}
+	if len(s.Tables) != 1 || s.Tables[0].Name != "users" {
+		t.Errorf("tables = %v, want only users", s.Tables)
+	}
+	if s.DatabaseName != "app.db" {
+		t.Errorf("database name = %q, want app.db", s.DatabaseName)
+	}
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		t.Errorf("connection was closed by extraction: %v", err)
+	}
+}
+
+func TestExtractSchemaFromDBRejectsUnsupportedConnections(t *testing.T) {
+	ctx := context.Background()
+	sqlDB, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatalf("failed to open SQLite database: %v", err)
+	}
+	defer func() { _ = sqlDB.Close() }()
+
+	if _, err := ExtractSchemaFromDB(ctx, sqlDB, "oracle", nil); err == nil {
+		t.Error("ExtractSchemaFromDB() accepted an unsupported dialect")
+	}
+	// A PostgreSQL extraction needs the pgx driver underneath database/sql
+	_, err = ExtractSchemaFromDB(ctx, sqlDB, DialectPostgres, nil)
+	if err == nil || !strings.Contains(err.Error(), "ExtractSchemaFromPgx") {
+		t.Errorf("ExtractSchemaFromDB() error = %v, want guidance to use ExtractSchemaFromPgx", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		t.Errorf("connection was closed after a failed extraction: %v", err)
+	}
+}
+
+func TestFormatSchemaUsesRegisteredFormatter(t *testing.T) {
+	t.Cleanup(func() {
+		formattersMu.Lock()
+		delete(formatters, "names")
+		formattersMu.Unlock()
+	})
+
+	RegisterFormatter("Names", func(opts *OutputOptions) (Formatter, error) {
+		return FormatterFunc(func(s *schema.Schema) error {
+			for _, table := range s.Tables {
+				if _, err := fmt.Fprintln(opts.Writer, table.Name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}), nil
+	})
+
+	var output bytes.Buffer
+	s := &schema.Schema{Tables: []schema.Table{{Name: "users"}, {Name: "orders"}}}
+	if err := FormatSchema(s, &OutputOptions{Writer: &output, Format: "names"}); err != nil {
+		t.Fatalf("FormatSchema() failed: %v", err)
+	}
+	if got := output.String(); got != "users\norders\n" {
+		t.Errorf("output = %q, want table names", got)
+	}
+}
+
+func TestFormatSchemaRejectsUnknownFormat(t *testing.T) {
+	err := FormatSchema(&schema.Schema{}, &OutputOptions{Writer: io.Discard, Format: "yaml"})
+	if err == nil || !strings.Contains(err.Error(), FormatMarkdown) {
+		t.Fatalf("FormatSchema() error = %v, want unknown format listing %s", err, FormatMarkdown)
+	}
+}
+
+func TestFormatSchemaWritesLoadableJSON(t *testing.T) {
+	s := &schema.Schema{
+		DatabaseType: "SQLite",
+		Tables:       []schema.Table{{Name: "users", Columns: []schema.Column{{Name: "id", Type: "INTEGER"}}, PrimaryKey: []string{"id"}}},
+	}
+
+	var output bytes.Buffer
+	if err := FormatSchema(s, &OutputOptions{Writer: &output, Format: FormatJSON}); err != nil {
+		t.Fatalf("FormatSchema() failed: %v", err)
+	}
+	loaded, err := schema.ReadJSON(&output)
+	if err != nil {
+		t.Fatalf("ReadJSON() failed: %v", err)
+	}
+
+	var markdown bytes.Buffer
+	if err := FormatSchema(loaded, &OutputOptions{Writer: &markdown}); err != nil {
+		t.Fatalf("FormatSchema() of loaded schema failed: %v", err)
+	}
+	if !strings.Contains(markdown.String(), "| id | PK INTEGER NOT NULL |") {
+		t.Errorf("loaded schema formats differently:\n%s", markdown.String())
+	}
+
+	if err := FormatSchema(s, &OutputOptions{OutputDir: t.TempDir(), Format: FormatJSON}); err == nil {
+		t.Error("FormatSchema() accepted an output directory for JSON")
+	}
+}
+
+func TestFormatSnapshotSelectsTablesWithoutDatabase(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "schema.json")
+	s := &schema.Schema{
+		DatabaseType: "PostgreSQL",
+		Tables: []schema.Table{
+			{Schema: "billing", Name: "invoices"},
+			{Schema: "public", Name: "orders"},
+			{Schema: "public", Name: "users"},
+		},
+		Views: []schema.View{{Schema: "public", Name: "active_users", Kind: schema.ViewKindView}},
+	}
+	if err := SaveSnapshot(snapshotPath, s); err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+
+	var output bytes.Buffer
+	err := FormatSnapshot(snapshotPath, &Options{
+		Tables:        []string{"users", "billing.invoices", "active_users", "orders"},
+		ExcludeTables: []string{"public.orders"},
+	}, &OutputOptions{Writer: &output, Format: FormatJSON})
+	if err != nil {
+		t.Fatalf("FormatSnapshot() failed: %v", err)
+	}
+
+	formatted, err := schema.ReadJSON(&output)
+	if err != nil {
+		t.Fatalf("ReadJSON() failed: %v", err)
+	}
+	var names []string
+	for _, table := range formatted.Tables {
+		names = append(names, schema.QualifiedName(table.Schema, table.Name))
+	}
+	if got, want := strings.Join(names, ","), "public.users,billing.invoices"; got != want {
+		t.Errorf("tables = %s, want %s", got, want)
+	}
+	if len(formatted.Views) != 1 || formatted.Views[0].Name != "active_users" {
+		t.Errorf("views = %+v, want active_users", formatted.Views)
+	}
+}
+
+func TestFormatSnapshotRejectsInvalidSelections(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "schema.json")
+	if err := SaveSnapshot(snapshotPath, &schema.Schema{Tables: []schema.Table{{Name: "users"}}}); err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    *Options
+		wantErr string
+	}{
+		{name: "schema selection", path: snapshotPath, opts: &Options{SchemaName: "public"}, wantErr: "schemas cannot be selected from a snapshot"},
+		{name: "unknown table", path: snapshotPath, opts: &Options{Tables: []string{"orders"}}, wantErr: `table "orders" not found in snapshot`},
+		{name: "missing snapshot", path: filepath.Join(t.TempDir(), "missing.json"), wantErr: "failed to open snapshot file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FormatSnapshot(tt.path, tt.opts, &OutputOptions{Writer: io.Discard})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("FormatSnapshot() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiffSchemasComparesSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+	newPath := filepath.Join(dir, "new.json")
+	if err := SaveSnapshot(oldPath, &schema.Schema{Tables: []schema.Table{{Name: "users"}, {Name: "migrations"}}}); err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+	if err := SaveSnapshot(newPath, &schema.Schema{Tables: []schema.Table{{Name: "users"}, {Name: "orders"}}}); err != nil {
+		t.Fatalf("SaveSnapshot() failed: %v", err)
+	}
+
+	d, err := DiffSchemas(context.Background(), oldPath, newPath, &Options{ExcludeTables: []string{"migrations"}})
+	if err != nil {
+		t.Fatalf("DiffSchemas() failed: %v", err)
+	}
+	if len(d.AddedTables) != 1 || d.AddedTables[0].Name != "orders" || len(d.RemovedTables) != 0 || len(d.ChangedTables) != 0 {
+		t.Errorf("DiffSchemas() = %+v, want only orders added", d)
+	}
+
+	_, err = DiffSchemas(context.Background(), filepath.Join(dir, "missing.json"), newPath, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to load old schema") {
+		t.Errorf("DiffSchemas() error = %v, want old schema load failure", err)
+	}
+}
+
+func TestFormatDiffWritesMarkdownAndJSON(t *testing.T) {
+	d := schema.Compare(&schema.Schema{}, &schema.Schema{Tables: []schema.Table{{Name: "users"}}})
+
+	var markdown bytes.Buffer
+	if err := FormatDiff(d, &OutputOptions{Writer: &markdown}); err != nil {
+		t.Fatalf("FormatDiff() failed: %v", err)
+	}
+	if !strings.Contains(markdown.String(), "## Added tables\n\n- `users` (0 columns)\n") {
+		t.Errorf("markdown diff = %q, want users added", markdown.String())
+	}
+
+	var jsonOutput bytes.Buffer
+	if err := FormatDiff(d, &OutputOptions{Writer: &jsonOutput, Format: FormatJSON}); err != nil {
+		t.Fatalf("FormatDiff() failed: %v", err)
+	}
+	if !strings.HasPrefix(jsonOutput.String(), "{\n  \"version\": 1,\n  \"diff\": {\n    \"added_tables\": [") {
+		t.Errorf("JSON diff = %q, want a versioned diff document", jsonOutput.String())
+	}
+
+	if err := FormatDiff(d, &OutputOptions{Writer: io.Discard, Format: "team-wiki"}); err == nil {
+		t.Error("FormatDiff() accepted an unsupported format")
+	}
+	if err := FormatDiff(d, &OutputOptions{OutputDir: t.TempDir()}); err == nil {
+		t.Error("FormatDiff() accepted an output directory")
+	}
+}
+
+func TestCheckOutputComparesSingleFileWithoutWriting(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "schema.md")
+	s := &schema.Schema{Tables: []schema.Table{{Name: "users", Columns: []schema.Column{{Name: "id", Type: "integer"}}}}}
+
+	var diff bytes.Buffer
+	if err := CheckOutput(s, outputFile, nil, &diff); !errors.Is(err, ErrOutputOutdated) {
+		t.Fatalf("CheckOutput() of missing output error = %v, want %v", err, ErrOutputOutdated)
+	}
+	if !strings.Contains(diff.String(), "--- /dev/null\n+++ b/"+outputFile+"\n") || !strings.Contains(diff.String(), "+## users\n") {
+		t.Errorf("diff does not create the output:\n%s", diff.String())
+	}
+	if _, err := os.Stat(outputFile); !os.IsNotExist(err) {
+		t.Fatalf("CheckOutput() wrote the output; stat error = %v", err)
+	}
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		t.Fatalf("failed to create output: %v", err)
+	}
+	if err := FormatSchema(s, &OutputOptions{Writer: file}); err != nil {
+		t.Fatalf("FormatSchema() failed: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("failed to close output: %v", err)
+	}
+
+	diff.Reset()
+	if err := CheckOutput(s, outputFile, nil, &diff); err != nil {
+		t.Fatalf("CheckOutput() of current output failed: %v\n%s", err, diff.String())
+	}
+
+	s.Tables[0].Columns[0].Type = "bigint"
+	if err := CheckOutput(s, outputFile, nil, &diff); !errors.Is(err, ErrOutputOutdated) {
+		t.Fatalf("CheckOutput() of stale output error = %v, want %v", err, ErrOutputOutdated)
+	}
+	if !strings.Contains(diff.String(), "-| id | integer NOT NULL |\n+| id | bigint NOT NULL |\n") {
+		t.Errorf("diff does not show the changed column:\n%s", diff.String())
+	}
+}
+
+func TestCheckOutputIgnoresJSONSnapshotTime(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "schema.json")
+	s := &schema.Schema{
+		SnapshotTime: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tables:       []schema.Table{{Name: "users", Columns: []schema.Column{{Name: "id", Type: "integer"}}}},
+	}
+	var output bytes.Buffer
+	if err := FormatSchema(s, &OutputOptions{Writer: &output, Format: FormatJSON}); err != nil {
+		t.Fatalf("FormatSchema() failed: %v", err)
+	}
+	if !strings.Contains(output.String(), `"snapshot_time": "2026-01-02T03:04:05Z"`) {
+		t.Errorf("JSON output does not record the snapshot time:\n%s", output.String())
+	}
+	if err := os.WriteFile(outputFile, output.Bytes(), 0o644); err != nil {
+		t.Fatalf("failed to write output: %v", err)
+	}
+
+	opts := &OutputOptions{Format: FormatJSON}
+
+	s.SnapshotTime = s.SnapshotTime.Add(time.Hour)
+	var diff bytes.Buffer
+	if err := CheckOutput(s, outputFile, opts, &diff); err != nil {
+		t.Fatalf("CheckOutput() with a new snapshot time failed: %v\n%s", err, diff.String())
+	}
+
+	s.Tables[0].Columns[0].Type = "bigint"
+	if err := CheckOutput(s, outputFile, opts, &diff); !errors.Is(err, ErrOutputOutdated) {
+		t.Fatalf("CheckOutput() of stale output error = %v, want %v", err, ErrOutputOutdated)
+	}
+	if strings.Contains(diff.String(), "snapshot_time") {
+		t.Errorf("diff shows the snapshot time:\n%s", diff.String())
+	}
+}
+
+func TestCheckOutputRequiresOutput(t *testing.T) {
+	err := CheckOutput(&schema.Schema{}, "", nil, io.Discard)
+	if err == nil || errors.Is(err, ErrOutputOutdated) {
+		t.Fatalf("CheckOutput() error = %v, want missing output error", err)
+	}
+
+	err = CheckOutput(&schema.Schema{}, "", &OutputOptions{OutputDir: t.TempDir(), Format: FormatJSON}, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "only supported for markdown") {
+		t.Fatalf("CheckOutput() error = %v, want unsupported format error", err)
+	}
+}
+
+func TestFormatSchemaWritesMermaid(t *testing.T) {
+	s := &schema.Schema{Tables: []schema.Table{{Name: "users", Columns: []schema.Column{{Name: "id", Type: "INTEGER"}}, PrimaryKey: []string{"id"}}}}
+
+	var output bytes.Buffer
+	if err := FormatSchema(s, &OutputOptions{Writer: &output, Format: FormatMermaid}); err != nil {
+		t.Fatalf("FormatSchema() failed: %v", err)
+	}
+	if got, want := output.String(), "erDiagram\n    users {\n        INTEGER id PK\n    }\n"; got != want {
+		t.Errorf("FormatSchema() = %q, want %q", got, want)
+	}
+
+	if err := FormatSchema(s, &OutputOptions{OutputDir: t.TempDir(), Format: FormatMermaid}); err == nil {
+		t.Error("FormatSchema() accepted an output directory for Mermaid")
+	}
+}
+
+func TestFormatSchemaWritesDBML(t *testing.T) {
+	s := &schema.Schema{Tables: []schema.Table{{Name: "users", Columns: []schema.Column{{Name: "id", Type: "INTEGER"}}, PrimaryKey: []string{"id"}}}}
+
+	var output bytes.Buffer
+	if err := FormatSchema(s, &OutputOptions{Writer: &output, Format: FormatDBML}); err != nil {
+		t.Fatalf("FormatSchema() failed: %v", err)
+	}
+	if got, want := output.String(), "Table users {\n  id INTEGER [pk]\n}\n\n"; got != want {
+		t.Errorf("FormatSchema() = %q, want %q", got, want)
+	}
+
+	if err := FormatSchema(s, &OutputOptions{OutputDir: t.TempDir(), Format: FormatDBML}); err == nil {
+		t.Error("FormatSchema() accepted an output directory for DBML")
+	}
+}
+
+func TestExtractSchemaFromDDLSelectsSchemas(t *testing.T) {
+	ddl := `
+CREATE TABLE users (id serial PRIMARY KEY, email text NOT NULL);
+CREATE SCHEMA billing;
+CREATE TABLE billing.invoices (id int PRIMARY KEY, user_id int REFERENCES public.users (id));
+`
+	s, err := ExtractSchemaFromDDL(ddl, "", nil)
+	if err != nil {
+		t.Fatalf("ExtractSchemaFromDDL() failed: %v", err)
+	}
+	if s.DatabaseType != "PostgreSQL" || s.SchemaName != "public" || len(s.Tables) != 1 || s.Tables[0].Name != "users" {
+		t.Errorf("default schema = %+v, want public.users", s)
+	}
+
+	s, err = ExtractSchemaFromDDL(ddl, DialectPostgres, &Options{AllSchemas: true})
+	if err != nil {
+		t.Fatalf("ExtractSchemaFromDDL() failed: %v", err)
+	}
+	if len(s.Tables) != 2 || s.Tables[0].Schema != "billing" || s.Tables[0].Relations[0].TargetSchema != "public" {
+		t.Errorf("all schemas = %+v, want billing.invoices referencing public.users", s.Tables)
+	}
+
+	if _, err := ExtractSchemaFromDDL(ddl, DialectSQLite, &Options{SchemaNames: []string{"a", "b"}}); err == nil {
+		t.Error("ExtractSchemaFromDDL() accepted multiple SQLite schemas")
+	}
+	if _, err := ExtractSchemaFromDDL("CREATE TABLE t (id int);\nALTER TABLE missing ADD x int;", "", nil); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ExtractSchemaFromDDL() error = %v, want the failing line", err)
+	}
+}
+
+func TestExtractAndFormatReadsSQLURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema?.sql")
+	if err := os.WriteFile(path, []byte("CREATE TABLE `users` (`id` INT AUTO_INCREMENT PRIMARY KEY);"), 0o644); err != nil {
+		t.Fatalf("failed to write DDL file: %v", err)
+	}
+
+	var output bytes.Buffer
+	err := ExtractAndFormat(context.Background(), "sql://"+path+"?dialect=mysql", nil, &OutputOptions{Writer: &output, Format: FormatDBML})
+	if err != nil {
+		t.Fatalf("ExtractAndFormat() failed: %v", err)
+	}
+	if got, want := output.String(), "Table users {\n  id int [pk, increment]\n}\n\n"; got != want {
+		t.Errorf("ExtractAndFormat() = %q, want %q", got, want)
+	}
+
+	if err := ExtractAndFormat(context.Background(), "sql://"+path+"?dialect=oracle", nil, nil); err == nil {
+		t.Error("ExtractAndFormat() accepted an unsupported dialect")
+	}
+}
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write migration %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestExtractSchemaFromMigrationsReplaysIntoSQLite(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"1_create_users.up.sql":   "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);",
+		"1_create_users.down.sql": "DROP TABLE users;",
+		"2_add_email.up.sql":      "ALTER TABLE users ADD COLUMN email TEXT NOT NULL DEFAULT '';",
+		"10_create_posts.up.sql": `CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id));
+CREATE INDEX posts_user_id ON posts (user_id);`,
+		"README.md": "not a migration",
+	})
+
+	s, err := ExtractSchemaFromMigrations(context.Background(), dir, DialectSQLite, nil)
+	if err != nil {
+		t.Fatalf("ExtractSchemaFromMigrations() failed: %v", err)
+	}
+	if s.DatabaseType != "SQLite" || len(s.Tables) != 2 {
+		t.Fatalf("schema = %+v, want two SQLite tables", s)
+	}
+	posts, users := s.Tables[0], s.Tables[1]
+	if len(users.Columns) != 3 || users.Columns[2].Name != "email" {
+		t.Errorf("users columns = %+v, want email added by the second migration", users.Columns)
+	}
+	if len(posts.Relations) != 1 || posts.Relations[0].TargetTable != "users" || len(posts.Indexes) != 1 {
+		t.Errorf("posts = %+v, want a reference to users and one index", posts)
+	}
+}
+
+func TestExtractSchemaFromMigrationsAppliesGooseUpSections(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{
+		"20240101120000_init.sql": `-- +goose Up
+CREATE TABLE users (id serial PRIMARY KEY);
+-- +goose StatementBegin
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE users;
+`,
+		"20240201120000_accounts.sql": `-- +goose Up
+ALTER TABLE users RENAME TO accounts;
+-- +goose Down
+ALTER TABLE accounts RENAME TO users;
+`,
+	})
+
+	s, err := ExtractSchemaFromMigrations(context.Background(), dir, "", nil)
+	if err != nil {
+		t.Fatalf("ExtractSchemaFromMigrations() failed: %v", err)
+	}
+	if len(s.Tables) != 1 || s.Tables[0].Name != "accounts" || s.SchemaName != "public" {
+		t.Errorf("tables = %+v, want public.accounts", s.Tables)
+	}
+}
+
+func TestExtractSchemaFromMigrationsReportsFailingMigration(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantErrText string
+	}{
+		{
+			name:        "failing statement",
+			files:       map[string]string{"1_init.up.sql": "CREATE TABLE t (id int);", "2_broken.up.sql": "ALTER TABLE missing ADD x int;"},
+			wantErrText: "migration 2_broken.up.sql",
+		},
+		{
+			name:        "duplicate version",
+			files:       map[string]string{"1_a.up.sql": "", "01_b.up.sql": ""},
+			wantErrText: "have the same version",
+		},
+		{
+			name:        "not an up migration",
+			files:       map[string]string{"1_seed.sql": "INSERT INTO t VALUES (1);"},
+			wantErrText: "neither a .up.sql file nor annotated with -- +goose Up",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeMigrations(t, tt.files)
+			for _, dialect := range []string{DialectPostgres, DialectSQLite} {
+				_, err := ExtractSchemaFromMigrations(context.Background(), dir, dialect, nil)
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Errorf("%s: ExtractSchemaFromMigrations() error = %v, want error containing %q", dialect, err, tt.wantErrText)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractAndFormatReadsMigrationsURL(t *testing.T) {
+	dir := writeMigrations(t, map[string]string{"1_init.up.sql": "CREATE TABLE users (id INTEGER PRIMARY KEY);"})
+
+	var output bytes.Buffer
+	err := ExtractAndFormat(context.Background(), "migrations://"+dir+"?dialect=sqlite", nil, &OutputOptions{Writer: &output, Format: FormatDBML})
+	if err != nil {
+		t.Fatalf("ExtractAndFormat() failed: %v", err)
+	}
+	if got, want := output.String(), "Table users {\n  id INTEGER [pk, increment]\n}\n\n"; got != want {
+		t.Errorf("ExtractAndFormat() = %q, want %q", got, want)
+	}
+}
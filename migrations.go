@@ -0,0 +1,167 @@
+package llmschema
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tordrt/llmschema/internal/db"
+	"github.com/tordrt/llmschema/schema"
+)
+
+// migration is the up part of one migration file
+type migration struct {
+	version uint64
+	name    string
+	sql     string
+}
+
+// ExtractSchemaFromMigrations builds a schema by replaying the up migrations
+// of a migrations directory, without a database server.
+//
+// Migrations are golang-migrate files named <version>_<title>.up.sql or
+// goose files named <version>_<title>.sql, of which only the part after the
+// -- +goose Up annotation is applied. They are applied in version order;
+// .down.sql files are ignored, and other .sql files are rejected.
+//
+// For DialectSQLite the migrations run in an in-memory SQLite database, which
+// is then extracted like any SQLite database. Other dialects are applied to
+// the DDL model of ExtractSchemaFromDDL, with its limitations. The dialect
+// defaults to DialectPostgres.
+//
+// Example:
+//
+//	s, err := llmschema.ExtractSchemaFromMigrations(ctx, "migrations", llmschema.DialectSQLite, nil)
+func ExtractSchemaFromMigrations(ctx context.Context, dir, dialect string, opts *Options) (*schema.Schema, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if dialect == "" {
+		dialect = DialectPostgres
+	}
+
+	migrations, err := readMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(dialect, DialectSQLite) {
+		return replaySQLiteMigrations(ctx, migrations, opts)
+	}
+
+	model, err := db.NewDDLModel(dialect)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if err := model.Apply(m.sql); err != nil {
+			return nil, fmt.Errorf("failed to apply migration %s: %w", m.name, err)
+		}
+	}
+	return extractDDLModelSchema(model, opts)
+}
+
+// replaySQLiteMigrations applies migrations to an in-memory SQLite database
+// and extracts its schema
+func replaySQLiteMigrations(ctx context.Context, migrations []migration, opts *Options) (*schema.Schema, error) {
+	if _, err := singleSchemaName(opts, "SQLite"); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory SQLite database: %w", err)
+	}
+	defer func() { _ = sqlDB.Close() }()
+	// Every connection to :memory: opens a new, empty database
+	sqlDB.SetMaxOpenConns(1)
+
+	for _, m := range migrations {
+		if _, err := sqlDB.ExecContext(ctx, m.sql); err != nil {
+			return nil, fmt.Errorf("failed to apply migration %s: %w", m.name, err)
+		}
+	}
+	return db.NewSQLiteExtractor(db.NewSQLiteClientFromDB(ctx, sqlDB)).ExtractSchema(ctx, opts.Tables)
+}
+
+// readMigrations returns the up migrations of dir in version order
+func readMigrations(dir string) ([]migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") || strings.HasSuffix(name, ".down.sql") {
+			continue
+		}
+
+		digits := len(name) - len(strings.TrimLeft(name, "0123456789"))
+		version, err := strconv.ParseUint(name[:digits], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s does not start with a version number", name)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		up := string(content)
+		if !strings.HasSuffix(name, ".up.sql") {
+			var ok bool
+			if up, ok = gooseUpSection(up); !ok {
+				return nil, fmt.Errorf("migration %s is neither a .up.sql file nor annotated with -- +goose Up", name)
+			}
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: up})
+	}
+
+	slices.SortFunc(migrations, func(a, b migration) int { return cmp.Compare(a.version, b.version) })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", migrations[i-1].name, migrations[i].name)
+		}
+	}
+	return migrations, nil
+}
+
+// gooseUpSection returns the statements between -- +goose Up and
+// -- +goose Down. Lines outside the section are blanked so that DDL errors
+// report the line numbers of the file.
+func gooseUpSection(content string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	found, inUp := false, false
+	for i, line := range lines {
+		switch annotation := strings.Join(strings.Fields(line), " "); {
+		case strings.EqualFold(annotation, "-- +goose Up"):
+			found, inUp = true, true
+			lines[i] = ""
+			continue
+		case strings.EqualFold(annotation, "-- +goose Down"):
+			inUp = false
+		}
+		if !inUp {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n"), found
+}
+
+// migrationsExtractor reads migrations://path/to/migrations?dialect=sqlite
+// URLs, so migration directories can be used wherever a database URL is accepted
+type migrationsExtractor struct{}
+
+func (migrationsExtractor) ExtractSchema(ctx context.Context, databaseURL string, opts *Options) (*schema.Schema, error) {
+	dir, dialect, err := parseFileURL(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractSchemaFromMigrations(ctx, dir, dialect, opts)
+}
@@ -0,0 +1,282 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Diff describes the changes between two schemas, as computed by Compare.
+//
+// Tables and views are matched by schema and name. Constraint and index names
+// are not compared, because databases generate them differently across
+// environments, and neither are comments or column order. Unique indexes that
+// back unique keys are reported as column or unique key changes.
+type Diff struct {
+	AddedTables   []Table     `json:"added_tables,omitempty"`
+	RemovedTables []Table     `json:"removed_tables,omitempty"`
+	ChangedTables []TableDiff `json:"changed_tables,omitempty"`
+	AddedViews    []View      `json:"added_views,omitempty"`
+	RemovedViews  []View      `json:"removed_views,omitempty"`
+	ChangedViews  []View      `json:"changed_views,omitempty"` // As defined in the new schema
+}
+
+// TableDiff describes the changes to a table present in both schemas
+type TableDiff struct {
+	Schema            string            `json:"schema,omitempty"`
+	Name              string            `json:"name"`
+	AddedColumns      []Column          `json:"added_columns,omitempty"`
+	RemovedColumns    []Column          `json:"removed_columns,omitempty"`
+	ChangedColumns    []ColumnDiff      `json:"changed_columns,omitempty"`
+	PrimaryKey        *KeyChange        `json:"primary_key,omitempty"` // Set only when the primary key changed
+	AddedUniqueKeys   [][]string        `json:"added_unique_keys,omitempty"`
+	RemovedUniqueKeys [][]string        `json:"removed_unique_keys,omitempty"`
+	AddedIndexes      []Index           `json:"added_indexes,omitempty"`
+	RemovedIndexes    []Index           `json:"removed_indexes,omitempty"`
+	AddedRelations    []Relation        `json:"added_relations,omitempty"`
+	RemovedRelations  []Relation        `json:"removed_relations,omitempty"`
+	AddedChecks       []CheckConstraint `json:"added_checks,omitempty"`
+	RemovedChecks     []CheckConstraint `json:"removed_checks,omitempty"`
+}
+
+// KeyChange holds the columns of a key before and after a change
+type KeyChange struct {
+	Old []string `json:"old"`
+	New []string `json:"new"`
+}
+
+// Column attributes reported in ColumnDiff.Changes
+const (
+	ColumnChangeType      = "type" // Type or enum values
+	ColumnChangeNullable  = "nullable"
+	ColumnChangeDefault   = "default"
+	ColumnChangeUnique    = "unique"
+	ColumnChangeCheck     = "check"
+	ColumnChangeGenerated = "generated"
+	ColumnChangeIdentity  = "identity"
+)
+
+// ColumnDiff describes a column present in both versions of a table
+type ColumnDiff struct {
+	Name    string   `json:"name"`
+	Changes []string `json:"changes"` // ColumnChange attributes that differ, in declaration order
+	Old     Column   `json:"old"`
+	New     Column   `json:"new"`
+}
+
+// IsEmpty reports whether the compared schemas have no differences.
+func (d *Diff) IsEmpty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 && len(d.ChangedTables) == 0 &&
+		len(d.AddedViews) == 0 && len(d.RemovedViews) == 0 && len(d.ChangedViews) == 0
+}
+
+// Compare returns the changes that turn the old schema into the new one.
+// Added and changed entries follow the order of the new schema, and removed
+// entries the order of the old schema.
+func Compare(old, new *Schema) *Diff {
+	diff := &Diff{}
+
+	oldTables := make(map[string]Table, len(old.Tables))
+	for _, table := range old.Tables {
+		oldTables[QualifiedName(table.Schema, table.Name)] = table
+	}
+	newTables := make(map[string]bool, len(new.Tables))
+	for _, table := range new.Tables {
+		name := QualifiedName(table.Schema, table.Name)
+		newTables[name] = true
+		oldTable, ok := oldTables[name]
+		if !ok {
+			diff.AddedTables = append(diff.AddedTables, table)
+			continue
+		}
+		if tableDiff, changed := compareTables(oldTable, table); changed {
+			diff.ChangedTables = append(diff.ChangedTables, tableDiff)
+		}
+	}
+	for _, table := range old.Tables {
+		if !newTables[QualifiedName(table.Schema, table.Name)] {
+			diff.RemovedTables = append(diff.RemovedTables, table)
+		}
+	}
+
+	oldViews := make(map[string]View, len(old.Views))
+	for _, view := range old.Views {
+		oldViews[QualifiedName(view.Schema, view.Name)] = view
+	}
+	newViews := make(map[string]bool, len(new.Views))
+	for _, view := range new.Views {
+		name := QualifiedName(view.Schema, view.Name)
+		newViews[name] = true
+		oldView, ok := oldViews[name]
+		switch {
+		case !ok:
+			diff.AddedViews = append(diff.AddedViews, view)
+		case viewChanged(oldView, view):
+			diff.ChangedViews = append(diff.ChangedViews, view)
+		}
+	}
+	for _, view := range old.Views {
+		if !newViews[QualifiedName(view.Schema, view.Name)] {
+			diff.RemovedViews = append(diff.RemovedViews, view)
+		}
+	}
+
+	return diff
+}
+
+func compareTables(old, new Table) (TableDiff, bool) {
+	diff := TableDiff{Schema: new.Schema, Name: new.Name}
+
+	oldColumns := make(map[string]Column, len(old.Columns))
+	for _, column := range old.Columns {
+		oldColumns[column.Name] = column
+	}
+	newColumns := make(map[string]bool, len(new.Columns))
+	for _, column := range new.Columns {
+		newColumns[column.Name] = true
+		oldColumn, ok := oldColumns[column.Name]
+		if !ok {
+			diff.AddedColumns = append(diff.AddedColumns, column)
+			continue
+		}
+		if changes := columnChanges(oldColumn, column); len(changes) > 0 {
+			diff.ChangedColumns = append(diff.ChangedColumns, ColumnDiff{Name: column.Name, Changes: changes, Old: oldColumn, New: column})
+		}
+	}
+	for _, column := range old.Columns {
+		if !newColumns[column.Name] {
+			diff.RemovedColumns = append(diff.RemovedColumns, column)
+		}
+	}
+
+	if !slices.Equal(old.PrimaryKey, new.PrimaryKey) {
+		diff.PrimaryKey = &KeyChange{Old: old.PrimaryKey, New: new.PrimaryKey}
+	}
+	diff.AddedUniqueKeys, diff.RemovedUniqueKeys = compareBy(old.UniqueKeys, new.UniqueKeys, func(key []string) string {
+		return strings.Join(key, "\x00")
+	})
+	diff.AddedIndexes, diff.RemovedIndexes = compareBy(additionalIndexes(old.Indexes), additionalIndexes(new.Indexes), indexKey)
+	diff.AddedRelations, diff.RemovedRelations = compareBy(old.Relations, new.Relations, relationKey)
+	diff.AddedChecks, diff.RemovedChecks = compareBy(old.Checks, new.Checks, func(check CheckConstraint) string {
+		return check.Expression
+	})
+
+	changed := len(diff.AddedColumns) > 0 || len(diff.RemovedColumns) > 0 || len(diff.ChangedColumns) > 0 ||
+		diff.PrimaryKey != nil ||
+		len(diff.AddedUniqueKeys) > 0 || len(diff.RemovedUniqueKeys) > 0 ||
+		len(diff.AddedIndexes) > 0 || len(diff.RemovedIndexes) > 0 ||
+		len(diff.AddedRelations) > 0 || len(diff.RemovedRelations) > 0 ||
+		len(diff.AddedChecks) > 0 || len(diff.RemovedChecks) > 0
+	return diff, changed
+}
+
+func columnChanges(old, new Column) []string {
+	var changes []string
+	if old.Type != new.Type || !slices.Equal(old.EnumValues, new.EnumValues) {
+		changes = append(changes, ColumnChangeType)
+	}
+	if old.Nullable != new.Nullable {
+		changes = append(changes, ColumnChangeNullable)
+	}
+	if !equalPointers(old.DefaultValue, new.DefaultValue) {
+		changes = append(changes, ColumnChangeDefault)
+	}
+	if old.IsUnique != new.IsUnique {
+		changes = append(changes, ColumnChangeUnique)
+	}
+	if !equalPointers(old.CheckConstraint, new.CheckConstraint) {
+		changes = append(changes, ColumnChangeCheck)
+	}
+	if !equalPointers(old.Generated, new.Generated) {
+		changes = append(changes, ColumnChangeGenerated)
+	}
+	if old.Identity != new.Identity {
+		changes = append(changes, ColumnChangeIdentity)
+	}
+	return changes
+}
+
+func viewChanged(old, new View) bool {
+	if old.Kind != new.Kind || old.Definition != new.Definition || len(old.Columns) != len(new.Columns) {
+		return true
+	}
+	for i := range old.Columns {
+		if old.Columns[i].Name != new.Columns[i].Name || len(columnChanges(old.Columns[i], new.Columns[i])) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// compareBy returns the values only in new and the values only in old, with
+// values identified by key
+func compareBy[T any](old, new []T, key func(T) string) (added, removed []T) {
+	oldKeys := make(map[string]bool, len(old))
+	for _, value := range old {
+		oldKeys[key(value)] = true
+	}
+	newKeys := make(map[string]bool, len(new))
+	for _, value := range new {
+		newKeys[key(value)] = true
+		if !oldKeys[key(value)] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range old {
+		if !newKeys[key(value)] {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed
+}
+
+// additionalIndexes omits the plain unique indexes that back unique keys,
+// whose changes are reported as column or unique key changes
+func additionalIndexes(indexes []Index) []Index {
+	var additional []Index
+	for _, index := range indexes {
+		if !index.IsUnique || index.IsPartial || index.HasExpressions || len(index.Columns) == 0 {
+			additional = append(additional, index)
+		}
+	}
+	return additional
+}
+
+// indexKey identifies an index by its definition. Expression indexes are also
+// identified by name, because their columns do not describe them completely.
+func indexKey(index Index) string {
+	key := fmt.Sprintf("%q unique=%t partial=%t", index.Columns, index.IsUnique, index.IsPartial)
+	if index.HasExpressions {
+		key += " expressions " + index.Name
+	}
+	return key
+}
+
+// relationKey identifies a relation by its columns and referential actions,
+// treating an unreported action as NO ACTION
+func relationKey(relation Relation) string {
+	sourceColumns, targetColumns := relation.SourceColumns, relation.TargetColumns
+	if len(sourceColumns) == 0 && relation.SourceColumn != "" {
+		sourceColumns = []string{relation.SourceColumn}
+	}
+	if len(targetColumns) == 0 && relation.TargetColumn != "" {
+		targetColumns = []string{relation.TargetColumn}
+	}
+	return fmt.Sprintf("%q -> %q %q %q update=%q delete=%q",
+		sourceColumns, relation.TargetSchema, relation.TargetTable, targetColumns,
+		referentialAction(relation.OnUpdate), referentialAction(relation.OnDelete))
+}
+
+func referentialAction(action string) string {
+	if action == "" {
+		return "NO ACTION"
+	}
+	return action
+}
+
+func equalPointers[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
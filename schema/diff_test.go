@@ -0,0 +1,162 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func diffTestSchemas() (*Schema, *Schema) {
+	zero := "0"
+	one := "1"
+	old := &Schema{
+		Tables: []Table{
+			{
+				Name: "users",
+				Columns: []Column{
+					{Name: "id", Type: "integer"},
+					{Name: "name", Type: "text", Nullable: true},
+					{Name: "age", Type: "integer", DefaultValue: &zero},
+					{Name: "nickname", Type: "text", Nullable: true},
+				},
+				PrimaryKey: []string{"id"},
+				Indexes: []Index{
+					{Name: "users_name_idx", Columns: []string{"name"}},
+					{Name: "users_age_idx", Columns: []string{"age"}},
+				},
+				Relations: []Relation{{Name: "users_team_fkey", TargetTable: "teams", SourceColumns: []string{"team_id"}, TargetColumns: []string{"id"}}},
+				Checks:    []CheckConstraint{{Name: "users_age_check", Expression: "age >= 0"}},
+			},
+			{Name: "legacy", Columns: []Column{{Name: "id", Type: "integer"}}},
+			{Name: "teams", Columns: []Column{{Name: "id", Type: "integer"}}},
+		},
+		Views: []View{
+			{Name: "adults", Definition: "SELECT * FROM users WHERE age >= 18"},
+			{Name: "old_view", Definition: "SELECT 1"},
+		},
+	}
+	new := &Schema{
+		Tables: []Table{
+			{Name: "teams", Columns: []Column{{Name: "id", Type: "integer"}}},
+			{
+				Name: "users",
+				Columns: []Column{
+					{Name: "email", Type: "text", IsUnique: true},
+					{Name: "id", Type: "bigint"},
+					{Name: "name", Type: "text"},
+					{Name: "age", Type: "integer", DefaultValue: &one},
+				},
+				PrimaryKey: []string{"id"},
+				UniqueKeys: [][]string{{"name", "age"}},
+				Indexes: []Index{
+					{Name: "idx_users_name", Columns: []string{"name"}},
+					{Name: "users_email_key", Columns: []string{"email"}, IsUnique: true},
+				},
+				Relations: []Relation{
+					{Name: "fk_users_team", TargetTable: "teams", SourceColumns: []string{"team_id"}, TargetColumns: []string{"id"}, OnDelete: "NO ACTION"},
+					{TargetTable: "orgs", SourceColumns: []string{"org_id"}, TargetColumns: []string{"id"}, OnDelete: "CASCADE"},
+				},
+			},
+			{Name: "orders", Columns: []Column{{Name: "id", Type: "integer"}}},
+		},
+		Views: []View{
+			{Name: "adults", Definition: "SELECT * FROM users WHERE age >= 21"},
+			{Name: "new_view", Definition: "SELECT 1"},
+		},
+	}
+	return old, new
+}
+
+func TestCompareReportsChanges(t *testing.T) {
+	old, new := diffTestSchemas()
+	diff := Compare(old, new)
+
+	if got := tableNames(diff.AddedTables); !reflect.DeepEqual(got, []string{"orders"}) {
+		t.Errorf("added tables = %v, want [orders]", got)
+	}
+	if got := tableNames(diff.RemovedTables); !reflect.DeepEqual(got, []string{"legacy"}) {
+		t.Errorf("removed tables = %v, want [legacy]", got)
+	}
+	if len(diff.ChangedTables) != 1 {
+		t.Fatalf("changed tables = %+v, want only users", diff.ChangedTables)
+	}
+
+	users := diff.ChangedTables[0]
+	if users.Name != "users" {
+		t.Fatalf("changed table = %s, want users", users.Name)
+	}
+	if len(users.AddedColumns) != 1 || users.AddedColumns[0].Name != "email" {
+		t.Errorf("added columns = %+v, want email", users.AddedColumns)
+	}
+	if len(users.RemovedColumns) != 1 || users.RemovedColumns[0].Name != "nickname" {
+		t.Errorf("removed columns = %+v, want nickname", users.RemovedColumns)
+	}
+	changes := make(map[string][]string)
+	for _, column := range users.ChangedColumns {
+		changes[column.Name] = column.Changes
+	}
+	wantChanges := map[string][]string{
+		"id":   {ColumnChangeType},
+		"name": {ColumnChangeNullable},
+		"age":  {ColumnChangeDefault},
+	}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("column changes = %v, want %v", changes, wantChanges)
+	}
+	if users.PrimaryKey != nil {
+		t.Errorf("primary key change = %+v, want none", users.PrimaryKey)
+	}
+	if !reflect.DeepEqual(users.AddedUniqueKeys, [][]string{{"name", "age"}}) || len(users.RemovedUniqueKeys) != 0 {
+		t.Errorf("unique keys added %v, removed %v; want [[name age]] added", users.AddedUniqueKeys, users.RemovedUniqueKeys)
+	}
+	// Renamed indexes and unique key indexes are not reported
+	if len(users.AddedIndexes) != 0 || len(users.RemovedIndexes) != 1 || users.RemovedIndexes[0].Name != "users_age_idx" {
+		t.Errorf("indexes added %+v, removed %+v; want users_age_idx removed", users.AddedIndexes, users.RemovedIndexes)
+	}
+	// Renamed relations and explicit NO ACTION are not reported
+	if len(users.AddedRelations) != 1 || users.AddedRelations[0].TargetTable != "orgs" || len(users.RemovedRelations) != 0 {
+		t.Errorf("relations added %+v, removed %+v; want orgs added", users.AddedRelations, users.RemovedRelations)
+	}
+	if len(users.RemovedChecks) != 1 || len(users.AddedChecks) != 0 {
+		t.Errorf("checks added %+v, removed %+v; want one removed", users.AddedChecks, users.RemovedChecks)
+	}
+
+	if len(diff.AddedViews) != 1 || diff.AddedViews[0].Name != "new_view" {
+		t.Errorf("added views = %+v, want new_view", diff.AddedViews)
+	}
+	if len(diff.RemovedViews) != 1 || diff.RemovedViews[0].Name != "old_view" {
+		t.Errorf("removed views = %+v, want old_view", diff.RemovedViews)
+	}
+	if len(diff.ChangedViews) != 1 || diff.ChangedViews[0].Name != "adults" {
+		t.Errorf("changed views = %+v, want adults", diff.ChangedViews)
+	}
+}
+
+func TestCompareMatchesTablesBySchema(t *testing.T) {
+	old := &Schema{Tables: []Table{{Schema: "public", Name: "users"}}}
+	new := &Schema{Tables: []Table{{Schema: "auth", Name: "users"}}}
+
+	diff := Compare(old, new)
+	if len(diff.AddedTables) != 1 || diff.AddedTables[0].Schema != "auth" {
+		t.Errorf("added tables = %+v, want auth.users", diff.AddedTables)
+	}
+	if len(diff.RemovedTables) != 1 || diff.RemovedTables[0].Schema != "public" {
+		t.Errorf("removed tables = %+v, want public.users", diff.RemovedTables)
+	}
+}
+
+func TestCompareIdenticalSchemasIsEmpty(t *testing.T) {
+	old, _ := diffTestSchemas()
+	same, _ := diffTestSchemas()
+
+	if diff := Compare(old, same); !diff.IsEmpty() {
+		t.Errorf("Compare() of identical schemas = %+v, want no changes", diff)
+	}
+}
+
+func tableNames(tables []Table) []string {
+	var names []string
+	for _, table := range tables {
+		names = append(names, QualifiedName(table.Schema, table.Name))
+	}
+	return names
+}
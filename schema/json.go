@@ -0,0 +1,102 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONVersion is the version of the JSON document written by WriteJSON.
+//
+// The document is an object with the version and the schema:
+//
+//	{"version": 1, "schema": {"database_type": "PostgreSQL", "tables": [...]}}
+//
+// Schema fields are encoded under the names in their json struct tags, and
+// empty fields are omitted, except for table, view, column, and index names,
+// column types, column nullability, and relation target tables. Within a
+// version, fields are only ever added, so readers should ignore unknown
+// fields. Any other change increments the version.
+const JSONVersion = 1
+
+type jsonDocument struct {
+	Version int     `json:"version"`
+	Schema  *Schema `json:"schema"`
+}
+
+type jsonDiffDocument struct {
+	Version int   `json:"version"`
+	Diff    *Diff `json:"diff"`
+}
+
+// WriteJSON writes s as an indented JSON document of the current JSONVersion
+func WriteJSON(w io.Writer, s *Schema) error {
+	return writeJSONDocument(w, jsonDocument{Version: JSONVersion, Schema: s})
+}
+
+// WriteDiffJSON writes d as an indented JSON document of the current
+// JSONVersion, with the diff under "diff" instead of "schema"
+func WriteDiffJSON(w io.Writer, d *Diff) error {
+	return writeJSONDocument(w, jsonDiffDocument{Version: JSONVersion, Diff: d})
+}
+
+func writeJSONDocument(w io.Writer, document any) error {
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+	_, err = w.Write(content)
+	return err
+}
+
+// ReadJSON reads a JSON document written by WriteJSON. Documents from a newer
+// JSONVersion are rejected.
+func ReadJSON(r io.Reader) (*Schema, error) {
+	var document jsonDocument
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode schema JSON: %w", err)
+	}
+	switch {
+	case document.Version == 0:
+		return nil, fmt.Errorf("schema JSON has no version")
+	case document.Version > JSONVersion:
+		return nil, fmt.Errorf("unsupported schema JSON version %d (supported up to %d)", document.Version, JSONVersion)
+	case document.Schema == nil:
+		return nil, fmt.Errorf("schema JSON has no schema")
+	}
+
+	return document.Schema, nil
+}
+
+// MarshalJSON encodes the deprecated single-column aliases as column lists
+// when the lists themselves are empty
+func (r Relation) MarshalJSON() ([]byte, error) {
+	type plainRelation Relation
+	encoded := plainRelation(r)
+	if len(encoded.SourceColumns) == 0 && encoded.SourceColumn != "" {
+		encoded.SourceColumns = []string{encoded.SourceColumn}
+	}
+	if len(encoded.TargetColumns) == 0 && encoded.TargetColumn != "" {
+		encoded.TargetColumns = []string{encoded.TargetColumn}
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes a relation and populates the deprecated
+// single-column aliases
+func (r *Relation) UnmarshalJSON(data []byte) error {
+	type plainRelation Relation
+	var decoded plainRelation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*r = Relation(decoded)
+	if len(r.SourceColumns) == 1 {
+		r.SourceColumn = r.SourceColumns[0]
+	}
+	if len(r.TargetColumns) == 1 {
+		r.TargetColumn = r.TargetColumns[0]
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package schema
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	defaultValue := "'active'"
+	original := &Schema{
+		DatabaseType: "PostgreSQL",
+		DatabaseName: "app",
+		Tables: []Table{{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", Type: "integer", Identity: IdentityAlways},
+				{Name: "status", Type: "text", Nullable: true, DefaultValue: &defaultValue},
+				{Name: "total", Type: "numeric", Generated: &GeneratedColumn{Expression: "price * quantity", Stored: true}},
+			},
+			PrimaryKey: []string{"id"},
+			UniqueKeys: [][]string{{"status", "total"}},
+			Checks:     []CheckConstraint{{Name: "orders_total", Expression: "total >= 0", Columns: []string{"total"}}},
+			Indexes:    []Index{{Name: "orders_status", Columns: []string{"status"}, IsPartial: true}},
+			Relations: []Relation{{
+				Name:          "orders_user_id_fkey",
+				TargetTable:   "users",
+				TargetColumns: []string{"id"},
+				SourceColumns: []string{"user_id"},
+				TargetColumn:  "id",
+				SourceColumn:  "user_id",
+				Cardinality:   "N:1",
+				OnDelete:      "CASCADE",
+			}},
+		}},
+		Views: []View{{Name: "open_orders", Kind: ViewKindView, Dependencies: []string{"orders"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, original); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "{\n  \"version\": 1,\n  \"schema\": {\n") {
+		t.Errorf("document does not start with its version:\n%s", buf.String())
+	}
+
+	loaded, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON() failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, original) {
+		t.Errorf("round trip changed the schema:\ngot  %+v\nwant %+v", loaded, original)
+	}
+}
+
+func TestWriteJSONEncodesDeprecatedRelationColumns(t *testing.T) {
+	s := &Schema{Tables: []Table{{
+		Name:      "orders",
+		Relations: []Relation{{TargetTable: "users", TargetColumn: "id", SourceColumn: "user_id"}},
+	}}}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, s); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	loaded, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON() failed: %v", err)
+	}
+	relation := loaded.Tables[0].Relations[0]
+	if !reflect.DeepEqual(relation.SourceColumns, []string{"user_id"}) || !reflect.DeepEqual(relation.TargetColumns, []string{"id"}) {
+		t.Errorf("relation columns = %v -> %v, want [user_id] -> [id]", relation.SourceColumns, relation.TargetColumns)
+	}
+}
+
+func TestReadJSONRejectsUnsupportedDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{name: "missing version", document: `{"schema": {}}`, wantErr: "no version"},
+		{name: "newer version", document: `{"version": 2, "schema": {}}`, wantErr: "unsupported schema JSON version 2"},
+		{name: "missing schema", document: `{"version": 1}`, wantErr: "no schema"},
+		{name: "invalid JSON", document: `{"version":`, wantErr: "failed to decode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadJSON(strings.NewReader(tt.document))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadJSON() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
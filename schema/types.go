@@ -0,0 +1,128 @@
+// Package schema defines the database schema model produced by
+// llmschema.ExtractSchema and consumed by llmschema.FormatSchema.
+//
+// The model is part of the public API and follows the module's semantic
+// versioning: within a major version, existing types, fields, and constants
+// keep their names and meaning. New fields may be added, so construct values
+// with keyed fields (schema.Table{Name: "users"}) rather than positionally.
+// Fields that are superseded are marked Deprecated and remain populated until
+// the next major version.
+//
+// WriteJSON and ReadJSON convert the model to and from a versioned JSON
+// document; see JSONVersion for its shape.
+package schema
+
+import "time"
+
+// Schema represents a complete database schema
+type Schema struct {
+	DatabaseType    string    `json:"database_type,omitempty"`
+	DatabaseVersion string    `json:"database_version,omitempty"`
+	DatabaseName    string    `json:"database_name,omitempty"`
+	SchemaName      string    `json:"schema_name,omitempty"`
+	Schemas         []string  `json:"schemas,omitempty"` // Schemas covered by a multi-schema extraction, which leaves SchemaName empty
+	Tables          []Table   `json:"tables,omitempty"`
+	Views           []View    `json:"views,omitempty"`
+	SnapshotTime    time.Time `json:"snapshot_time,omitzero"` // When the database snapshot every table and view was read from was taken, in UTC; zero without a database
+}
+
+// QualifiedName returns name prefixed by its schema, or name alone when the
+// schema is empty
+func QualifiedName(schemaName, name string) string {
+	if schemaName == "" {
+		return name
+	}
+	return schemaName + "." + name
+}
+
+// Table represents a database table
+type Table struct {
+	Schema     string            `json:"schema,omitempty"` // Containing schema, set only by multi-schema extractions
+	Name       string            `json:"name"`
+	Columns    []Column          `json:"columns,omitempty"`
+	Relations  []Relation        `json:"relations,omitempty"`
+	Indexes    []Index           `json:"indexes,omitempty"`
+	PrimaryKey []string          `json:"primary_key,omitempty"`
+	UniqueKeys [][]string        `json:"unique_keys,omitempty"` // Composite unique keys; single-column keys use Column.IsUnique
+	Checks     []CheckConstraint `json:"checks,omitempty"`      // CHECK constraints not shown on a single column
+	Comment    string            `json:"comment,omitempty"`     // Table comment, empty when none is set
+}
+
+// CheckConstraint represents a table-level CHECK constraint
+type CheckConstraint struct {
+	Name       string   `json:"name,omitempty"`
+	Expression string   `json:"expression,omitempty"` // Condition without the surrounding CHECK (...)
+	Columns    []string `json:"columns,omitempty"`    // Columns referenced by the condition, when known
+}
+
+// View kinds
+const (
+	ViewKindView         = "view"
+	ViewKindMaterialized = "materialized view"
+)
+
+// View represents a database view or materialized view
+type View struct {
+	Schema       string   `json:"schema,omitempty"` // Containing schema, set only by multi-schema extractions
+	Name         string   `json:"name"`
+	Kind         string   `json:"kind,omitempty"` // ViewKindView or ViewKindMaterialized
+	Columns      []Column `json:"columns,omitempty"`
+	Definition   string   `json:"definition,omitempty"`   // Defining SELECT statement, empty when unavailable
+	Dependencies []string `json:"dependencies,omitempty"` // Tables and views read by the view, qualified when in another schema or when Schema is set
+	Comment      string   `json:"comment,omitempty"`      // View comment, empty when none is set
+}
+
+// Column represents a table column
+type Column struct {
+	Name            string           `json:"name"`
+	Type            string           `json:"type"`
+	Nullable        bool             `json:"nullable"`
+	DefaultValue    *string          `json:"default,omitempty"`
+	IsUnique        bool             `json:"unique,omitempty"`
+	EnumValues      []string         `json:"enum_values,omitempty"` // For USER-DEFINED enum types
+	CheckConstraint *string          `json:"check,omitempty"`       // For CHECK constraints
+	Generated       *GeneratedColumn `json:"generated,omitempty"`   // Set for computed columns, which cannot be written
+	Identity        string           `json:"identity,omitempty"`    // IdentityAlways or IdentityByDefault for database-generated keys
+	Comment         string           `json:"comment,omitempty"`     // Column comment, empty when none is set
+}
+
+// Identity modes of database-generated key columns
+const (
+	// IdentityAlways columns reject explicit values, as with GENERATED ALWAYS AS IDENTITY.
+	IdentityAlways = "always"
+	// IdentityByDefault columns are generated when omitted on insert, as with
+	// GENERATED BY DEFAULT AS IDENTITY, serial, AUTO_INCREMENT, and SQLite rowid aliases.
+	IdentityByDefault = "by default"
+)
+
+// GeneratedColumn describes how a generated column is computed
+type GeneratedColumn struct {
+	Expression string `json:"expression,omitempty"`
+	Stored     bool   `json:"stored,omitempty"` // Computed on write and stored, rather than computed on read
+}
+
+// Relation represents a foreign key relationship
+type Relation struct {
+	Name          string   `json:"name,omitempty"`
+	TargetSchema  string   `json:"target_schema,omitempty"` // Empty for the extracted schema unless the source table's Schema is set
+	TargetTable   string   `json:"target_table"`
+	TargetColumns []string `json:"target_columns,omitempty"`
+	SourceColumns []string `json:"source_columns,omitempty"`
+	Cardinality   string   `json:"cardinality,omitempty"` // 1:1 or N:1, expressed from source to target
+	OnUpdate      string   `json:"on_update,omitempty"`
+	OnDelete      string   `json:"on_delete,omitempty"`
+
+	// Deprecated: use TargetColumns and SourceColumns. These aliases remain
+	// populated for single-column relationships for API compatibility.
+	TargetColumn string `json:"-"`
+	SourceColumn string `json:"-"`
+}
+
+// Index represents a database index
+type Index struct {
+	Name           string   `json:"name"`
+	Columns        []string `json:"columns,omitempty"`
+	IsUnique       bool     `json:"unique,omitempty"`
+	IsPartial      bool     `json:"partial,omitempty"`         // Conditional PostgreSQL or SQLite index
+	HasExpressions bool     `json:"has_expressions,omitempty"` // Columns is incomplete and cannot prove key uniqueness
+}
@@ -0,0 +1,12 @@
+package schema
+
+import "testing"
+
+func TestQualifiedName(t *testing.T) {
+	if got := QualifiedName("", "users"); got != "users" {
+		t.Errorf("QualifiedName(\"\", users) = %q, want users", got)
+	}
+	if got := QualifiedName("billing", "invoices"); got != "billing.invoices" {
+		t.Errorf("QualifiedName(billing, invoices) = %q, want billing.invoices", got)
+	}
+}
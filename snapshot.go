@@ -0,0 +1,115 @@
+package llmschema
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/tordrt/llmschema/schema"
+)
+
+// SaveSnapshot writes s to path as a versioned JSON document (see
+// schema.JSONVersion), replacing any existing file. The snapshot can be
+// formatted later without a database connection with FormatSnapshot.
+func SaveSnapshot(path string, s *schema.Schema) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+
+	if err := schema.WriteJSON(file, s); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot reads a schema saved with SaveSnapshot or written with the
+// json output format.
+func LoadSnapshot(path string) (*schema.Schema, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	s, err := schema.ReadJSON(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// FormatSnapshot formats a schema snapshot like ExtractAndFormat formats a
+// live database, so committed snapshots can generate documentation without
+// database credentials.
+//
+// Tables and ExcludeTables select tables and views from the snapshot, matching
+// schema.table names as well as unqualified names. Schema selection options
+// are rejected: the snapshot already contains the schemas it was saved with.
+func FormatSnapshot(snapshotPath string, opts *Options, outOpts *OutputOptions) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	s, err := loadSnapshotTables(snapshotPath, opts)
+	if err != nil {
+		return err
+	}
+	return FormatSchema(s, outOpts)
+}
+
+// loadSnapshotTables loads a snapshot and applies the table filters in opts
+func loadSnapshotTables(snapshotPath string, opts *Options) (*schema.Schema, error) {
+	if opts.SchemaName != "" || len(opts.SchemaNames) > 0 || opts.AllSchemas {
+		return nil, fmt.Errorf("schemas cannot be selected from a snapshot (save the snapshot with the schemas to document instead)")
+	}
+
+	s, err := LoadSnapshot(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := selectTables(s, opts.Tables); err != nil {
+		return nil, err
+	}
+	filterExcludedTables(s, opts.ExcludeTables)
+	return s, nil
+}
+
+// selectTables keeps the requested tables and views in the requested order,
+// as extraction with Options.Tables does
+func selectTables(s *schema.Schema, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	matches := func(schemaName, name, requested string) bool {
+		return name == requested || schema.QualifiedName(schemaName, name) == requested
+	}
+
+	var tables []schema.Table
+	var views []schema.View
+	for _, requested := range names {
+		found := false
+		for _, table := range s.Tables {
+			if matches(table.Schema, table.Name, requested) {
+				tables = append(tables, table)
+				found = true
+			}
+		}
+		for _, view := range s.Views {
+			if matches(view.Schema, view.Name, requested) {
+				views = append(views, view)
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("table %q not found in snapshot", requested)
+		}
+	}
+
+	s.Tables = tables
+	s.Views = views
+	return nil
+}
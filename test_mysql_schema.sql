@@ -2,6 +2,9 @@
 CREATE DATABASE IF NOT EXISTS testdb;
 USE testdb;
 
+-- Drop views if they exist
+DROP VIEW IF EXISTS active_users;
+
 -- Drop tables if they exist
 DROP TABLE IF EXISTS external_profiles;
 DROP TABLE IF EXISTS expression_children;
@@ -19,18 +22,20 @@ CREATE TABLE users (
     id INT AUTO_INCREMENT PRIMARY KEY,
     username VARCHAR(50) NOT NULL UNIQUE,
     email VARCHAR(100) NOT NULL,
-    status ENUM('active', 'inactive', 'banned') DEFAULT 'active',
+    status ENUM('active', 'inactive', 'banned') DEFAULT 'active' COMMENT 'Account lifecycle state',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
+) COMMENT = 'Registered application accounts';
 
 CREATE TABLE products (
     id INT AUTO_INCREMENT PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     description TEXT,
-    price DECIMAL(10, 2) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    sale_price DECIMAL(10, 2),
     stock INT DEFAULT 0,
     category ENUM('electronics', 'clothing', 'food', 'books') NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT products_sale_below_price CHECK (sale_price < price),
     INDEX idx_category (category),
     INDEX idx_price (price)
 );
@@ -89,11 +94,17 @@ CREATE TABLE order_items (
     product_id INT NOT NULL,
     quantity INT NOT NULL DEFAULT 1,
     unit_price DECIMAL(10, 2) NOT NULL,
+    line_total DECIMAL(12, 2) GENERATED ALWAYS AS (quantity * unit_price) STORED,
     FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
     FOREIGN KEY (product_id) REFERENCES products(id),
     UNIQUE KEY unique_order_product (order_id, product_id)
 );
 
+CREATE VIEW active_users AS
+SELECT id, username, email
+FROM users
+WHERE status = 'active';
+
 -- Insert some test data
 INSERT INTO users (username, email, status) VALUES
     ('alice', 'alice@example.com', 'active'),
@@ -4,6 +4,10 @@
 -- psql testdb < test_postgres_schema.sql
 -- Then test with: ./llmschema --db-url "postgres://localhost/testdb"
 
+-- Drop views if they exist
+DROP MATERIALIZED VIEW IF EXISTS order_totals;
+DROP VIEW IF EXISTS active_users;
+
 -- Drop tables if they exist
 DROP TABLE IF EXISTS external_profiles;
 DROP TABLE IF EXISTS partitioned_profiles;
@@ -35,14 +39,19 @@ CREATE TABLE users (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+COMMENT ON TABLE users IS 'Registered application accounts';
+COMMENT ON COLUMN users.status IS 'Account lifecycle state';
+
 CREATE TABLE products (
-    id SERIAL PRIMARY KEY,
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     description TEXT,
-    price DECIMAL(10, 2) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    sale_price DECIMAL(10, 2),
     stock INT DEFAULT 0,
     category product_category NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT products_sale_below_price CHECK (sale_price < price)
 );
 
 CREATE TABLE profiles (
@@ -105,11 +114,24 @@ CREATE TABLE order_items (
     product_id INT NOT NULL,
     quantity INT NOT NULL DEFAULT 1,
     unit_price DECIMAL(10, 2) NOT NULL,
+    line_total DECIMAL(12, 2) GENERATED ALWAYS AS (quantity * unit_price) STORED,
     FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
     FOREIGN KEY (product_id) REFERENCES products(id),
     UNIQUE (order_id, product_id)
 );
 
+CREATE VIEW active_users AS
+SELECT id, username, email
+FROM users
+WHERE status = 'active';
+
+COMMENT ON VIEW active_users IS 'Users that can sign in';
+
+CREATE MATERIALIZED VIEW order_totals AS
+SELECT o.user_id, count(*) AS order_count, sum(o.total_amount) AS total_spent
+FROM orders o
+GROUP BY o.user_id;
+
 -- Insert some test data
 INSERT INTO users (username, email, status) VALUES
     ('alice', 'alice@example.com', 'active'),
@@ -1,5 +1,8 @@
 -- Test schema for SQLite
 
+-- Drop views if they exist
+DROP VIEW IF EXISTS active_users;
+
 -- Drop tables if they exist
 DROP TABLE IF EXISTS expression_children;
 DROP TABLE IF EXISTS implicit_composite_children;
@@ -96,11 +99,17 @@ CREATE TABLE order_items (
     product_id INTEGER NOT NULL,
     quantity INTEGER NOT NULL DEFAULT 1,
     unit_price REAL NOT NULL,
+    line_total REAL GENERATED ALWAYS AS (quantity * unit_price) STORED,
     FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
     FOREIGN KEY (product_id) REFERENCES products(id),
     UNIQUE(order_id, product_id)
 );
 
+CREATE VIEW active_users AS
+SELECT id, username, email
+FROM users
+WHERE status = 'active';
+
 -- Insert some test data
 INSERT INTO users (username, email, status) VALUES
     ('alice', 'alice@example.com', 'active'),
@@ -5,10 +5,13 @@ package integration
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"testing"
 
+	"github.com/tordrt/llmschema"
 	"github.com/tordrt/llmschema/internal/db"
+	"github.com/tordrt/llmschema/schema"
 )
 
 func TestMySQLExtraction(t *testing.T) {
@@ -54,6 +57,10 @@ func TestMySQLExtraction(t *testing.T) {
 	verifyPrimaryKey(t, table, []string{"id"})
 	expectedColumns := []string{"id", "username", "email", "status", "created_at"}
 	verifyColumns(t, table, expectedColumns)
+	verifyComments(t, table, "Registered application accounts", map[string]string{
+		"status": "Account lifecycle state",
+		"email":  "",
+	})
 
 	// Verify ENUM type extraction for status column
 	expectedEnumValues := []string{"active", "inactive", "banned"}
@@ -65,6 +72,17 @@ func TestMySQLExtraction(t *testing.T) {
 	verifyExternalSchemaRelation(t, s, "external_profiles", "identity", "users")
 	verifyExpressionIndexMarked(t, s, "expression_children_user_label")
 	verifyKeyAndIndexMarkdown(t, s)
+
+	products := findTable(s, "products")
+	if products == nil {
+		t.Fatal("products table not found")
+	}
+	verifyColumnCheck(t, products, "price", "`price` >= 0")
+	verifyTableCheck(t, products, "products_sale_below_price", []string{"price", "sale_price"})
+	verifyGeneratedColumn(t, s, "order_items", "line_total", true)
+	verifyIdentity(t, s, "users", "id", schema.IdentityByDefault)
+
+	verifyView(t, s, "active_users", "view", []string{"id", "username", "email"}, []string{"users"})
 }
 
 func TestMySQLSpecificTables(t *testing.T) {
@@ -106,3 +124,34 @@ func TestMySQLSpecificTables(t *testing.T) {
 		t.Error("Should not include orders or order_items tables")
 	}
 }
+
+func TestMySQLExtractionFromExistingDB(t *testing.T) {
+	ctx := context.Background()
+
+	connString := os.Getenv("MYSQL_TEST_URL")
+	if connString == "" {
+		connString = "root:testpassword@tcp(localhost:3306)/testdb"
+	}
+
+	sqlDB, err := sql.Open("mysql", connString)
+	if err != nil {
+		t.Fatalf("Failed to open MySQL: %v", err)
+	}
+	defer sqlDB.Close()
+
+	// The schema name comes from the database selected on the connection
+	s, err := llmschema.ExtractSchemaFromDB(ctx, sqlDB, llmschema.DialectMySQL, &llmschema.Options{
+		Tables: []string{"users"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to extract schema from existing DB: %v", err)
+	}
+	if s.SchemaName != "testdb" {
+		t.Errorf("Expected schema name testdb, got %q", s.SchemaName)
+	}
+	verifyTablesExist(t, s, []string{"users"})
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		t.Errorf("Existing DB was closed by extraction: %v", err)
+	}
+}
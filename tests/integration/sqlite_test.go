@@ -11,6 +11,7 @@ import (
 
 	"github.com/tordrt/llmschema"
 	"github.com/tordrt/llmschema/internal/db"
+	"github.com/tordrt/llmschema/schema"
 )
 
 func TestSQLiteExtraction(t *testing.T) {
@@ -59,6 +60,9 @@ func TestSQLiteExtraction(t *testing.T) {
 	// Verify indexes
 	verifyIndex(t, s, "products", "idx_category", []string{"category"})
 	verifyKeyAndIndexMarkdown(t, s)
+	verifyGeneratedColumn(t, s, "order_items", "line_total", true)
+	verifyIdentity(t, s, "users", "id", schema.IdentityByDefault)
+	verifyView(t, s, "active_users", "view", []string{"id", "username", "email"}, []string{"users"})
 }
 
 func TestSQLiteSpecificTables(t *testing.T) {
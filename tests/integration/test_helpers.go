@@ -10,7 +10,7 @@ import (
 	"testing"
 
 	"github.com/tordrt/llmschema/internal/formatter"
-	"github.com/tordrt/llmschema/internal/schema"
+	"github.com/tordrt/llmschema/schema"
 )
 
 // verifyTablesExist checks that all expected tables are present in the schema
@@ -177,6 +177,125 @@ func verifyColumns(t *testing.T, table *schema.Table, expectedColumns []string)
 	}
 }
 
+// verifyComments checks the table comment and the comments of the given columns
+func verifyComments(t *testing.T, table *schema.Table, tableComment string, columnComments map[string]string) {
+	t.Helper()
+
+	if table.Comment != tableComment {
+		t.Errorf("Expected %s table comment %q, got %q", table.Name, tableComment, table.Comment)
+	}
+	for _, col := range table.Columns {
+		if want, ok := columnComments[col.Name]; ok && col.Comment != want {
+			t.Errorf("Expected %s.%s comment %q, got %q", table.Name, col.Name, want, col.Comment)
+		}
+	}
+}
+
+// verifyView checks a view's kind, columns, and dependencies
+func verifyView(t *testing.T, s *schema.Schema, viewName, kind string, expectedColumns, expectedDependencies []string) {
+	t.Helper()
+
+	view := findView(s, viewName)
+	if view == nil {
+		t.Errorf("Expected view %s not found", viewName)
+		return
+	}
+	if view.Kind != kind {
+		t.Errorf("Expected %s kind %q, got %q", viewName, kind, view.Kind)
+	}
+	if view.Definition == "" {
+		t.Errorf("Expected %s to have a definition", viewName)
+	}
+
+	columns := make([]string, len(view.Columns))
+	for i, col := range view.Columns {
+		columns[i] = col.Name
+	}
+	if !slices.Equal(columns, expectedColumns) {
+		t.Errorf("Expected %s columns %v, got %v", viewName, expectedColumns, columns)
+	}
+	if !slices.Equal(view.Dependencies, expectedDependencies) {
+		t.Errorf("Expected %s dependencies %v, got %v", viewName, expectedDependencies, view.Dependencies)
+	}
+}
+
+// verifyColumnCheck checks that a column's CHECK constraint contains the expected text
+func verifyColumnCheck(t *testing.T, table *schema.Table, columnName, expected string) {
+	t.Helper()
+
+	for _, col := range table.Columns {
+		if col.Name != columnName {
+			continue
+		}
+		if col.CheckConstraint == nil || !strings.Contains(*col.CheckConstraint, expected) {
+			t.Errorf("Expected %s.%s check containing %q, got %v", table.Name, columnName, expected, col.CheckConstraint)
+		}
+		return
+	}
+	t.Errorf("Column %s not found in table %s", columnName, table.Name)
+}
+
+// verifyTableCheck checks that a table-level CHECK constraint covers the expected columns
+func verifyTableCheck(t *testing.T, table *schema.Table, checkName string, expectedColumns []string) {
+	t.Helper()
+
+	for _, check := range table.Checks {
+		if check.Name != checkName {
+			continue
+		}
+		if !slices.Equal(check.Columns, expectedColumns) {
+			t.Errorf("Expected %s columns %v, got %v", checkName, expectedColumns, check.Columns)
+		}
+		return
+	}
+	t.Errorf("Check %s not found in table %s; got %v", checkName, table.Name, table.Checks)
+}
+
+// verifyGeneratedColumn checks that a column is generated and whether it is stored
+func verifyGeneratedColumn(t *testing.T, s *schema.Schema, tableName, columnName string, stored bool) {
+	t.Helper()
+
+	table := findTable(s, tableName)
+	if table == nil {
+		t.Fatalf("%s table not found", tableName)
+	}
+	for _, col := range table.Columns {
+		if col.Name != columnName {
+			continue
+		}
+		if col.Generated == nil || col.Generated.Expression == "" {
+			t.Errorf("Expected %s.%s to be generated with an expression, got %+v", tableName, columnName, col.Generated)
+		} else if col.Generated.Stored != stored {
+			t.Errorf("Expected %s.%s stored = %v", tableName, columnName, stored)
+		}
+		return
+	}
+	t.Errorf("Column %s not found in table %s", columnName, tableName)
+}
+
+// verifyIdentity checks a column's identity mode
+func verifyIdentity(t *testing.T, s *schema.Schema, tableName, columnName, identity string) {
+	t.Helper()
+
+	table := findTable(s, tableName)
+	if table == nil {
+		t.Fatalf("%s table not found", tableName)
+	}
+	for _, col := range table.Columns {
+		if col.Name != columnName {
+			continue
+		}
+		if col.Identity != identity {
+			t.Errorf("Expected %s.%s identity %q, got %q", tableName, columnName, identity, col.Identity)
+		}
+		if identity != "" && col.DefaultValue != nil {
+			t.Errorf("Expected %s.%s identity without a default, got %q", tableName, columnName, *col.DefaultValue)
+		}
+		return
+	}
+	t.Errorf("Column %s not found in table %s", columnName, tableName)
+}
+
 // verifyPrimaryKey checks that a table has the expected primary key
 func verifyPrimaryKey(t *testing.T, table *schema.Table, expectedPK []string) {
 	t.Helper()
@@ -306,3 +425,13 @@ func findTable(s *schema.Schema, tableName string) *schema.Table {
 	}
 	return nil
 }
+
+// findView finds a view by name in the schema
+func findView(s *schema.Schema, viewName string) *schema.View {
+	for i := range s.Views {
+		if s.Views[i].Name == viewName {
+			return &s.Views[i]
+		}
+	}
+	return nil
+}